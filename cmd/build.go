@@ -7,6 +7,9 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"os"
+	"slices"
+	"strings"
 
 	"github.com/spf13/cobra"
 
@@ -16,6 +19,7 @@ import (
 	"github.com/elastic/elastic-package/internal/install"
 	"github.com/elastic/elastic-package/internal/logger"
 	"github.com/elastic/elastic-package/internal/packages"
+	"github.com/elastic/elastic-package/internal/packages/buildmanifest"
 )
 
 const buildLongDescription = `Use this command to build a package.
@@ -26,6 +30,14 @@ Built packages are served up by the Elastic Package Registry running locally (se
 
 Built packages can also be published to the global package registry service.
 
+Zipped packages are reproducible: entries are sorted, and they have normalized permissions and the same modification time, taken from the "SOURCE_DATE_EPOCH" environment variable, or from the date of the last commit of the package. Use "--verify-reproducible" to build the package twice and check that both builds produce the same archive.
+
+Packages are not built again if their source, linked files, license, ECS schema settings and the version of elastic-package haven't changed since the last build, and the built package hasn't been modified. Use "--no-cache" to build them anyway.
+
+Use "--sbom" to generate a software bill of materials of the zipped package in CycloneDX format. It is written next to the zipped package, and lists the package, the packages it requires, the ECS version its external fields are imported from, linked files with their source paths and checksums, bundled machine learning models, and licenses.
+
+Use "--size-report" to get a breakdown of the size of the built package by directory and asset category. Packages can define size budgets in "_dev/shared/size_budget.yml", the build fails if they are exceeded. For details, see the [HOWTO guide](https://github.com/elastic/elastic-package/blob/main/docs/howto/package_size.md).
+
 For details on how to enable dependency management, see the [HOWTO guide](https://github.com/elastic/elastic-package/blob/main/docs/howto/dependency_management.md).`
 
 func setupBuildCommand() *cobraext.Command {
@@ -39,6 +51,11 @@ func setupBuildCommand() *cobraext.Command {
 	cmd.Flags().Bool(cobraext.BuildZipFlagName, true, cobraext.BuildZipFlagDescription)
 	cmd.Flags().Bool(cobraext.SignPackageFlagName, false, cobraext.SignPackageFlagDescription)
 	cmd.Flags().Bool(cobraext.BuildSkipValidationFlagName, false, cobraext.BuildSkipValidationFlagDescription)
+	cmd.Flags().Bool(cobraext.BuildNoCacheFlagName, false, cobraext.BuildNoCacheFlagDescription)
+	cmd.Flags().Bool(cobraext.BuildVerifyReproducibleFlagName, false, cobraext.BuildVerifyReproducibleFlagDescription)
+	cmd.Flags().Bool(cobraext.BuildSBOMFlagName, false, cobraext.BuildSBOMFlagDescription)
+	cmd.Flags().String(cobraext.BuildSizeReportFlagName, "", fmt.Sprintf(cobraext.BuildSizeReportFlagDescription, strings.Join(builder.SizeReportFormats, "\", \"")))
+	cmd.Flags().String(cobraext.BuildSizeReportOutputFlagName, "", cobraext.BuildSizeReportOutputFlagDescription)
 	return cobraext.NewCommand(cmd, cobraext.ContextPackage)
 }
 
@@ -48,11 +65,31 @@ func buildCommandAction(cmd *cobra.Command, args []string) error {
 	createZip, _ := cmd.Flags().GetBool(cobraext.BuildZipFlagName)
 	signPackage, _ := cmd.Flags().GetBool(cobraext.SignPackageFlagName)
 	skipValidation, _ := cmd.Flags().GetBool(cobraext.BuildSkipValidationFlagName)
+	noCache, _ := cmd.Flags().GetBool(cobraext.BuildNoCacheFlagName)
+	verifyReproducible, _ := cmd.Flags().GetBool(cobraext.BuildVerifyReproducibleFlagName)
+	createSBOM, _ := cmd.Flags().GetBool(cobraext.BuildSBOMFlagName)
+	sizeReportFormat, _ := cmd.Flags().GetString(cobraext.BuildSizeReportFlagName)
+	sizeReportOutput, _ := cmd.Flags().GetString(cobraext.BuildSizeReportOutputFlagName)
+
+	if sizeReportFormat != "" && !slices.Contains(builder.SizeReportFormats, sizeReportFormat) {
+		return cobraext.FlagParsingError(fmt.Errorf("unsupported format %q, supported formats: %s", sizeReportFormat, strings.Join(builder.SizeReportFormats, ", ")), cobraext.BuildSizeReportFlagName)
+	}
+	if sizeReportOutput != "" && sizeReportFormat == "" {
+		return cobraext.FlagParsingError(errors.New("the size report format must be selected with --size-report"), cobraext.BuildSizeReportOutputFlagName)
+	}
 
 	if signPackage && !createZip {
 		return errors.New("can't sign the unzipped package, please use also the --zip switch")
 	}
 
+	if verifyReproducible && !createZip {
+		return errors.New("can't verify reproducibility of the unzipped package, please use also the --zip switch")
+	}
+
+	if createSBOM && !createZip {
+		return errors.New("can't generate the software bill of materials of the unzipped package, please use also the --zip switch")
+	}
+
 	if signPackage {
 		err := files.VerifySignerConfiguration()
 		if err != nil {
@@ -84,7 +121,7 @@ func buildCommandAction(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("can't load configuration: %w", err)
 	}
 
-	target, err := builder.BuildPackage(builder.BuildOptions{
+	buildOptions := builder.BuildOptions{
 		PackageRoot:    packageRoot,
 		BuildDir:       buildDir,
 		CreateZip:      createZip,
@@ -93,13 +130,87 @@ func buildCommandAction(cmd *cobra.Command, args []string) error {
 		RepositoryRoot: repositoryRoot,
 		UpdateReadmes:  true,
 		SchemaURLs:     appConfig.SchemaURLs(),
-	})
-	if err != nil {
-		return fmt.Errorf("building package failed: %w", err)
+		UseCache:       !noCache,
 	}
 
-	cmd.Printf("Package built: %s\n", target)
+	var target string
+	if verifyReproducible {
+		var checksum string
+		target, checksum, err = builder.BuildReproduciblePackage(buildOptions)
+		if err != nil {
+			return fmt.Errorf("building package failed: %w", err)
+		}
+
+		cmd.Printf("Package built: %s\n", target)
+		cmd.Printf("Package build is reproducible, SHA-256 checksum: %s\n", checksum)
+	} else {
+		target, err = builder.BuildPackage(buildOptions)
+		if err != nil {
+			return fmt.Errorf("building package failed: %w", err)
+		}
+
+		cmd.Printf("Package built: %s\n", target)
+	}
+
+	if createSBOM {
+		sbomPath, err := builder.WriteSBOM(buildOptions, target)
+		if err != nil {
+			return err
+		}
+		cmd.Printf("Software bill of materials written: %s\n", sbomPath)
+	}
+
+	err = checkPackageSize(cmd, packageRoot, target, sizeReportFormat, sizeReportOutput)
+	if err != nil {
+		return err
+	}
 
 	cmd.Println("Done")
 	return nil
 }
+
+// checkPackageSize reports the size of the built package if requested, and checks that it
+// doesn't exceed the size budget of the package, if there is any.
+func checkPackageSize(cmd *cobra.Command, packageRoot, target, format, output string) error {
+	budget, hasBudget, err := buildmanifest.ReadSizeBudget(packageRoot)
+	if err != nil {
+		return fmt.Errorf("can't read size budget: %w", err)
+	}
+	if !hasBudget && format == "" {
+		return nil
+	}
+
+	manifest, err := packages.ReadPackageManifestFromPackageRoot(packageRoot)
+	if err != nil {
+		return fmt.Errorf("reading package manifest failed (path: %s): %w", packageRoot, err)
+	}
+	report, err := builder.NewSizeReport(target, manifest.Name, manifest.Version)
+	if err != nil {
+		return err
+	}
+
+	if format != "" {
+		formatted, err := report.Format(format)
+		if err != nil {
+			return err
+		}
+		if output == "" {
+			cmd.Println(formatted)
+		} else {
+			err = os.WriteFile(output, []byte(formatted+"\n"), 0644)
+			if err != nil {
+				return fmt.Errorf("writing size report failed: %w", err)
+			}
+			cmd.Printf("Size report written to %s\n", output)
+		}
+	}
+
+	if hasBudget {
+		err = report.CheckBudget(*budget)
+		if err != nil {
+			return fmt.Errorf("checking package size budget failed: %w", err)
+		}
+		cmd.Println("Package size is within budget")
+	}
+	return nil
+}
@@ -6,15 +6,19 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/elastic/elastic-package/internal/cobraext"
+	"github.com/elastic/elastic-package/internal/lint"
 )
 
 const checkLongDescription = `Use this command to verify if the package is correct in terms of formatting, validation and building.
 
-It will execute the lint and build commands all at once, in that order.`
+It will execute the lint and build commands all at once, in that order.
+
+Use the --format flag to print the findings of the lint command as text (default), as JSON, as a SARIF log for code scanning tools, or as GitHub Actions workflow commands to annotate pull requests.`
 
 func setupCheckCommand() *cobraext.Command {
 	cmd := &cobra.Command{
@@ -23,8 +27,18 @@ func setupCheckCommand() *cobraext.Command {
 		Long:  checkLongDescription,
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			err := cobraext.ComposeCommands(cmd, args,
-				setupLintCommand(),
+			format, err := cmd.Flags().GetString(cobraext.LintFormatFlagName)
+			if err != nil {
+				return cobraext.FlagParsingError(err, cobraext.LintFormatFlagName)
+			}
+			lintCommand := setupLintCommand()
+			err = lintCommand.Flags().Set(cobraext.LintFormatFlagName, format)
+			if err != nil {
+				return cobraext.FlagParsingError(err, cobraext.LintFormatFlagName)
+			}
+
+			err = cobraext.ComposeCommands(cmd, args,
+				lintCommand,
 				setupBuildCommand(),
 			)
 			if err != nil {
@@ -33,6 +47,7 @@ func setupCheckCommand() *cobraext.Command {
 			return nil
 		},
 	}
+	cmd.Flags().String(cobraext.LintFormatFlagName, lint.FormatText, fmt.Sprintf(cobraext.LintFormatFlagDescription, strings.Join(lint.Formats, ",")))
 	cmd.PersistentFlags().BoolP(cobraext.FailFastFlagName, "f", true, cobraext.FailFastFlagDescription)
 
 	return cobraext.NewCommand(cmd, cobraext.ContextPackage)
@@ -5,14 +5,17 @@
 package cmd
 
 import (
+	"fmt"
+
 	"github.com/spf13/cobra"
 
 	"github.com/elastic/elastic-package/internal/cobraext"
+	"github.com/elastic/elastic-package/internal/install"
 )
 
 const createLongDescription = `Use this command to create a new package or add more data streams.
 
-The command can help bootstrap the first draft of a package using embedded package template. It can be used to extend the package with more data streams.
+The command can help bootstrap the first draft of a package using embedded package template. It can be used to extend the package with more data streams, to generate policy tests, and to infer fields definitions from sample documents.
 
 For details on how to create a new package, review the [HOWTO guide](https://github.com/elastic/elastic-package/blob/main/docs/howto/create_new_package.md).`
 
@@ -33,6 +36,27 @@ func setupCreateCommand() *cobraext.Command {
 		RunE:  createDataStreamCommandAction,
 	}
 
+	createPolicyTestsCmd := &cobra.Command{
+		Use:   "policy-tests",
+		Short: "Create policy tests",
+		Long:  createPolicyTestsLongDescription,
+		Args:  cobra.NoArgs,
+		RunE:  createPolicyTestsCommandAction,
+	}
+	createPolicyTestsCmd.Flags().StringSliceP(cobraext.DataStreamsFlagName, "d", nil, cobraext.DataStreamsFlagDescription)
+	createPolicyTestsCmd.Flags().StringP(cobraext.ProfileFlagName, "p", "", fmt.Sprintf(cobraext.ProfileFlagDescription, install.ProfileNameEnvVar))
+
+	createFieldsCmd := &cobra.Command{
+		Use:   "fields",
+		Short: "Create fields definitions from sample documents",
+		Long:  createFieldsLongDescription,
+		Args:  cobra.NoArgs,
+		RunE:  createFieldsCommandAction,
+	}
+	createFieldsCmd.Flags().StringP(cobraext.DataStreamFlagName, "d", "", cobraext.DataStreamFieldsFlagDescription)
+	createFieldsCmd.Flags().Bool(cobraext.FromStackFlagName, false, cobraext.FromStackFlagDescription)
+	createFieldsCmd.Flags().StringP(cobraext.ProfileFlagName, "p", "", fmt.Sprintf(cobraext.ProfileFlagDescription, install.ProfileNameEnvVar))
+
 	cmd := &cobra.Command{
 		Use:   "create",
 		Short: "Create package resources",
@@ -40,6 +64,8 @@ func setupCreateCommand() *cobraext.Command {
 	}
 	cmd.AddCommand(createPackageCmd)
 	cmd.AddCommand(createDataStreamCmd)
+	cmd.AddCommand(createPolicyTestsCmd)
+	cmd.AddCommand(createFieldsCmd)
 
 	return cobraext.NewCommand(cmd, cobraext.ContextGlobal)
 }
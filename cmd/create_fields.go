@@ -0,0 +1,265 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/elastic/elastic-package/internal/cobraext"
+	"github.com/elastic/elastic-package/internal/common"
+	"github.com/elastic/elastic-package/internal/fields"
+	"github.com/elastic/elastic-package/internal/files"
+	"github.com/elastic/elastic-package/internal/formatter"
+	"github.com/elastic/elastic-package/internal/install"
+	"github.com/elastic/elastic-package/internal/logger"
+	"github.com/elastic/elastic-package/internal/packages"
+	"github.com/elastic/elastic-package/internal/profile"
+	"github.com/elastic/elastic-package/internal/signal"
+	"github.com/elastic/elastic-package/internal/stack"
+)
+
+const createFieldsLongDescription = `Use this command to create the definitions of the fields found in sample documents.
+
+Documents are read from the expected results of pipeline tests and the sample event of the data stream, or from the documents ingested in the data stream in the Elastic stack when --from-stack is used. The types of the fields are inferred from their values.
+
+Fields already defined in the data stream, and fields that can be imported from ECS, are not included. Definitions are written to the "fields/inferred.yml" file of the data stream. Review them before committing them, inferred types are a first approximation.`
+
+const (
+	inferredFieldsFile = "inferred.yml"
+
+	// maxStackDocumentsForInference is the number of documents read from the stack to infer fields.
+	maxStackDocumentsForInference = 500
+)
+
+func createFieldsCommandAction(cmd *cobra.Command, args []string) error {
+	cmd.Println("Create fields definitions")
+
+	packageRoot, err := packages.FindPackageRoot()
+	if err != nil {
+		if errors.Is(err, packages.ErrPackageRootNotFound) {
+			return errors.New("package root not found, you can only create fields in the package context")
+		}
+		return fmt.Errorf("locating package root failed: %w", err)
+	}
+
+	manifest, err := packages.ReadPackageManifestFromPackageRoot(packageRoot)
+	if err != nil {
+		return fmt.Errorf("reading package manifest failed (path: %s): %w", packageRoot, err)
+	}
+
+	dataStream, err := cmd.Flags().GetString(cobraext.DataStreamFlagName)
+	if err != nil {
+		return cobraext.FlagParsingError(err, cobraext.DataStreamFlagName)
+	}
+
+	fromStack, err := cmd.Flags().GetBool(cobraext.FromStackFlagName)
+	if err != nil {
+		return cobraext.FlagParsingError(err, cobraext.FromStackFlagName)
+	}
+
+	root := packageRoot
+	switch {
+	case manifest.Type == "input" && dataStream != "":
+		return cobraext.FlagParsingError(errors.New("input packages don't have data streams"), cobraext.DataStreamFlagName)
+	case manifest.Type == "input" && fromStack:
+		return cobraext.FlagParsingError(errors.New("fields of input packages can only be inferred from files"), cobraext.FromStackFlagName)
+	case manifest.Type != "input" && dataStream == "":
+		return cobraext.FlagParsingError(errors.New("data stream is required"), cobraext.DataStreamFlagName)
+	case dataStream != "":
+		root = filepath.Join(packageRoot, "data_stream", dataStream)
+		if _, err := os.Stat(filepath.Join(root, packages.DataStreamManifestFile)); err != nil {
+			return cobraext.FlagParsingError(fmt.Errorf("data stream %q not found", dataStream), cobraext.DataStreamFlagName)
+		}
+	}
+
+	fieldsDir := filepath.Join(root, "fields")
+	outputPath := filepath.Join(fieldsDir, inferredFieldsFile)
+	if _, err := os.Stat(outputPath); err == nil {
+		return fmt.Errorf("file %s already exists, review and rename it before inferring fields again", outputPath)
+	}
+
+	var docs []common.MapStr
+	if fromStack {
+		profile, err := cobraext.GetProfileFlag(cmd)
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.Enable(cmd.Context(), logger.Info)
+		defer stop()
+
+		docs, err = readStackDocumentsForInference(ctx, profile, packageRoot, manifest.Name, dataStream)
+		if err != nil {
+			return err
+		}
+	} else {
+		docs, err = readFileDocumentsForInference(root)
+		if err != nil {
+			return err
+		}
+	}
+	if len(docs) == 0 {
+		return errors.New("no documents found to infer fields")
+	}
+
+	repositoryRoot, err := files.FindRepositoryRoot()
+	if err != nil {
+		return fmt.Errorf("locating repository root failed: %w", err)
+	}
+	defer repositoryRoot.Close()
+
+	appConfig, err := install.Configuration()
+	if err != nil {
+		return fmt.Errorf("can't load configuration: %w", err)
+	}
+
+	known, err := fields.KnownFieldDefinitions(repositoryRoot, packageRoot, fieldsDir, appConfig.SchemaURLs())
+	if err != nil {
+		return err
+	}
+
+	inference := fields.NewFieldsInference(known)
+	for _, doc := range docs {
+		inference.AddDocument(doc)
+	}
+	for _, name := range inference.Skipped() {
+		logger.Debugf("Field %q is already defined", name)
+	}
+
+	definitions := inference.Definitions()
+	if len(definitions) == 0 {
+		cmd.Println("All fields found in documents are already defined.")
+		return nil
+	}
+
+	d, err := fields.MarshalInferredDefinitions(definitions)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(fieldsDir, 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create fields directory: %w", err)
+	}
+	err = os.WriteFile(outputPath, d, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write fields file: %w", err)
+	}
+
+	relPath, err := filepath.Rel(packageRoot, outputPath)
+	if err != nil {
+		relPath = outputPath
+	}
+	cmd.Printf("Created %s from %d documents\n", relPath, len(docs))
+	return nil
+}
+
+// readFileDocumentsForInference reads the documents in the expected results of pipeline
+// tests and the sample event found in the given root.
+func readFileDocumentsForInference(root string) ([]common.MapStr, error) {
+	var docs []common.MapStr
+
+	expectedResults, err := filepath.Glob(filepath.Join(root, "_dev", "test", "pipeline", "*-expected.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range expectedResults {
+		d, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading expected results failed: %w", err)
+		}
+		var results struct {
+			Expected []common.MapStr `json:"expected"`
+		}
+		err = formatter.JSONUnmarshalUsingNumber(d, &results)
+		if err != nil {
+			return nil, fmt.Errorf("unmarshalling expected results failed (path: %s): %w", path, err)
+		}
+		for _, doc := range results.Expected {
+			// Dropped documents are null in expected results.
+			if doc != nil {
+				docs = append(docs, doc)
+			}
+		}
+	}
+
+	sampleEventPath := filepath.Join(root, "sample_event.json")
+	d, err := os.ReadFile(sampleEventPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return docs, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading sample event failed: %w", err)
+	}
+	var sampleEvent common.MapStr
+	err = formatter.JSONUnmarshalUsingNumber(d, &sampleEvent)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshalling sample event failed (path: %s): %w", sampleEventPath, err)
+	}
+	return append(docs, sampleEvent), nil
+}
+
+// readStackDocumentsForInference reads the most recent documents ingested in the data streams
+// of the given data stream of the package.
+func readStackDocumentsForInference(ctx context.Context, profile *profile.Profile, packageRoot, packageName, dataStream string) ([]common.MapStr, error) {
+	dsManifest, err := packages.ReadDataStreamManifestFromPackageRoot(packageRoot, dataStream)
+	if err != nil {
+		return nil, fmt.Errorf("reading data stream manifest failed: %w", err)
+	}
+	dataset := dsManifest.Dataset
+	if dataset == "" {
+		dataset = packageName + "." + dataStream
+	}
+	indexPattern := fmt.Sprintf("%s-%s-*", dsManifest.Type, dataset)
+
+	esClient, err := stack.NewElasticsearchClientFromProfile(profile)
+	if err != nil {
+		return nil, fmt.Errorf("can't create Elasticsearch client: %w", err)
+	}
+
+	resp, err := esClient.Search(
+		esClient.Search.WithContext(ctx),
+		esClient.Search.WithIndex(indexPattern),
+		esClient.Search.WithSize(maxStackDocumentsForInference),
+		esClient.Search.WithSort("@timestamp:desc"),
+		esClient.Search.WithIgnoreUnavailable(true),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("searching documents failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.IsError() {
+		return nil, fmt.Errorf("searching documents in %s failed: %s", indexPattern, resp.String())
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading search response failed: %w", err)
+	}
+	var result struct {
+		Hits struct {
+			Hits []struct {
+				Source common.MapStr `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	err = formatter.JSONUnmarshalUsingNumber(body, &result)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshalling search response failed: %w", err)
+	}
+
+	docs := make([]common.MapStr, 0, len(result.Hits.Hits))
+	for _, hit := range result.Hits.Hits {
+		docs = append(docs, hit.Source)
+	}
+	logger.Debugf("Found %d documents in %s", len(docs), indexPattern)
+	return docs, nil
+}
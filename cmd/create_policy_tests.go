@@ -0,0 +1,116 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/elastic/elastic-package/internal/cobraext"
+	"github.com/elastic/elastic-package/internal/files"
+	"github.com/elastic/elastic-package/internal/install"
+	"github.com/elastic/elastic-package/internal/logger"
+	"github.com/elastic/elastic-package/internal/packages"
+	"github.com/elastic/elastic-package/internal/signal"
+	"github.com/elastic/elastic-package/internal/stack"
+	"github.com/elastic/elastic-package/internal/testrunner"
+	"github.com/elastic/elastic-package/internal/testrunner/reporters/formats"
+	"github.com/elastic/elastic-package/internal/testrunner/reporters/outputs"
+	"github.com/elastic/elastic-package/internal/testrunner/runners/policy"
+)
+
+const createPolicyTestsLongDescription = `Use this command to generate policy tests for the package.
+
+A test case is generated for each policy template, input and data stream, with the default values of the variables, and additional test cases for each alternative value of boolean and select variables. Existing test cases are not modified.
+
+The policy tests runner is then used to generate the expected policies of the new test cases, so the Elastic stack must be running. Review the generated files before committing them.`
+
+func createPolicyTestsCommandAction(cmd *cobra.Command, args []string) error {
+	cmd.Println("Create policy tests for the package")
+
+	profile, err := cobraext.GetProfileFlag(cmd)
+	if err != nil {
+		return err
+	}
+
+	packageRoot, err := packages.FindPackageRoot()
+	if err != nil {
+		if errors.Is(err, packages.ErrPackageRootNotFound) {
+			return errors.New("package root not found, you can only create policy tests in the package context")
+		}
+		return fmt.Errorf("locating package root failed: %w", err)
+	}
+
+	dataStreams, err := getDataStreamsFlag(cmd, packageRoot)
+	if err != nil {
+		return err
+	}
+
+	testPaths, err := policy.GenerateTestConfigs(packageRoot, dataStreams)
+	if err != nil {
+		return fmt.Errorf("generating policy test configs failed: %w", err)
+	}
+	if len(testPaths) == 0 {
+		cmd.Println("No new policy test cases to generate.")
+		return nil
+	}
+	for _, path := range testPaths {
+		relPath, err := filepath.Rel(packageRoot, path)
+		if err != nil {
+			relPath = path
+		}
+		cmd.Printf("Created %s\n", relPath)
+	}
+
+	repositoryRoot, err := files.FindRepositoryRoot()
+	if err != nil {
+		return fmt.Errorf("locating repository root failed: %w", err)
+	}
+	defer repositoryRoot.Close()
+
+	ctx, stop := signal.Enable(cmd.Context(), logger.Info)
+	defer stop()
+
+	kibanaClient, err := stack.NewKibanaClientFromProfile(profile)
+	if err != nil {
+		return fmt.Errorf("can't create Kibana client: %w", err)
+	}
+
+	manifest, err := packages.ReadPackageManifestFromPackageRoot(packageRoot)
+	if err != nil {
+		return fmt.Errorf("reading package manifest failed (path: %s): %w", packageRoot, err)
+	}
+
+	globalTestConfig, err := testrunner.ReadGlobalTestConfig(packageRoot)
+	if err != nil {
+		return fmt.Errorf("failed to read global config: %w", err)
+	}
+
+	appConfig, err := install.Configuration()
+	if err != nil {
+		return fmt.Errorf("can't load configuration: %w", err)
+	}
+
+	runner := policy.NewPolicyTestRunner(policy.PolicyTestRunnerOptions{
+		PackageRoot:        packageRoot,
+		KibanaClient:       kibanaClient,
+		DataStreams:        dataStreams,
+		GenerateTestResult: true,
+		TestPaths:          testPaths,
+		GlobalTestConfig:   globalTestConfig.Policy,
+		RepositoryRoot:     repositoryRoot,
+		SchemaURLs:         appConfig.SchemaURLs(),
+	})
+
+	results, err := testrunner.RunSuite(ctx, runner)
+	if err != nil {
+		return err
+	}
+
+	return processResults(results, policy.TestType, string(formats.ReportFormatHuman), string(outputs.ReportOutputSTDOUT), packageRoot, manifest.Name, manifest.Type, "", false)
+}
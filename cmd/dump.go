@@ -5,6 +5,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -14,7 +15,9 @@ import (
 	"github.com/elastic/elastic-package/internal/elasticsearch"
 	"github.com/elastic/elastic-package/internal/install"
 	"github.com/elastic/elastic-package/internal/kibana"
+	"github.com/elastic/elastic-package/internal/packages"
 	"github.com/elastic/elastic-package/internal/stack"
+	"github.com/elastic/elastic-package/internal/testrunner/runners/policy"
 )
 
 const dumpLongDescription = `Use this command as an exploratory tool to dump resources from Elastic Stack (objects installed as part of package and agent policies).`
@@ -31,6 +34,12 @@ If no flag is provided, by default this command dumps all agent policies created
 
 If --package flag is provided, this command dumps all agent policies that the given package has been assigned to it.`
 
+const dumpCompiledPoliciesLongDescription = `Use this command to dump the agent policies of the policy tests of a package, compiled without Fleet.
+
+Policies are compiled locally following the same rules as Fleet, so the Elastic stack is not needed. Compiled policies are stored in the same format used by the expected policies of policy tests.
+
+Use the --data-streams flag to dump only the policies of the tests of the given data streams.`
+
 func setupDumpCommand() *cobraext.Command {
 	dumpInstalledObjectsCmd := &cobra.Command{
 		Use:   "installed-objects",
@@ -53,6 +62,15 @@ func setupDumpCommand() *cobraext.Command {
 	dumpAgentPoliciesCmd.Flags().StringP(cobraext.AgentPolicyFlagName, "", "", cobraext.AgentPolicyDescription)
 	dumpAgentPoliciesCmd.Flags().StringP(cobraext.PackageFlagName, cobraext.PackageFlagShorthand, "", cobraext.PackageFlagDescription)
 
+	dumpCompiledPoliciesCmd := &cobra.Command{
+		Use:   "compiled-policies",
+		Short: "Dump agent policies of policy tests compiled offline",
+		Long:  dumpCompiledPoliciesLongDescription,
+		Args:  cobra.NoArgs,
+		RunE:  dumpCompiledPoliciesCmdAction,
+	}
+	dumpCompiledPoliciesCmd.Flags().StringSliceP(cobraext.DataStreamsFlagName, "d", nil, cobraext.DataStreamsFlagDescription)
+
 	cmd := &cobra.Command{
 		Use:   "dump",
 		Short: "Dump package assets",
@@ -63,6 +81,7 @@ func setupDumpCommand() *cobraext.Command {
 
 	cmd.AddCommand(dumpInstalledObjectsCmd)
 	cmd.AddCommand(dumpAgentPoliciesCmd)
+	cmd.AddCommand(dumpCompiledPoliciesCmd)
 
 	return cobraext.NewCommand(cmd, cobraext.ContextGlobal)
 }
@@ -198,3 +217,34 @@ func dumpAgentPoliciesCmdAction(cmd *cobra.Command, args []string) error {
 	}
 	return nil
 }
+
+func dumpCompiledPoliciesCmdAction(cmd *cobra.Command, args []string) error {
+	outputPath, err := cmd.Flags().GetString(cobraext.DumpOutputFlagName)
+	if err != nil {
+		return cobraext.FlagParsingError(err, cobraext.DumpOutputFlagName)
+	}
+
+	packageRoot, err := packages.FindPackageRoot()
+	if err != nil {
+		if errors.Is(err, packages.ErrPackageRootNotFound) {
+			return errors.New("package root not found, you can only dump compiled policies in the package context")
+		}
+		return fmt.Errorf("locating package root failed: %w", err)
+	}
+
+	dataStreams, err := getDataStreamsFlag(cmd, packageRoot)
+	if err != nil {
+		return err
+	}
+
+	count, err := policy.DumpCompiledPolicies(packageRoot, dataStreams, outputPath)
+	if err != nil {
+		return fmt.Errorf("dump failed: %w", err)
+	}
+	if count == 0 {
+		cmd.Printf("No policy tests were found\n")
+		return nil
+	}
+	cmd.Printf("Dumped %d compiled agent policies to %s\n", count, outputPath)
+	return nil
+}
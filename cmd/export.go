@@ -6,6 +6,7 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 
@@ -13,7 +14,7 @@ import (
 	"github.com/elastic/elastic-package/internal/install"
 )
 
-const exportLongDescription = `Use this command to export assets relevant for the package, e.g. Kibana dashboards.`
+const exportLongDescription = `Use this command to export assets relevant for the package, e.g. Kibana dashboards, or the definitions of its fields.`
 
 func setupExportCommand() *cobraext.Command {
 	exportDashboardCmd := &cobra.Command{
@@ -39,6 +40,18 @@ func setupExportCommand() *cobraext.Command {
 	exportIngestPipelinesCmd.Flags().Bool(cobraext.TLSSkipVerifyFlagName, false, cobraext.TLSSkipVerifyFlagDescription)
 	exportIngestPipelinesCmd.Flags().Bool(cobraext.AllowSnapshotFlagName, false, cobraext.AllowSnapshotDescription)
 
+	exportFieldsCmd := &cobra.Command{
+		Use:   "fields",
+		Short: "Export fields definitions",
+		Long:  exportFieldsLongDescription,
+		Args:  cobra.NoArgs,
+		RunE:  exportFieldsCommandAction,
+	}
+	exportFieldsCmd.Flags().String(cobraext.ExportFieldsFormatFlagName, exportFieldsFormatJSONSchema, fmt.Sprintf(cobraext.ExportFieldsFormatFlagDescription, strings.Join(exportFieldsFormats, ",")))
+	exportFieldsCmd.Flags().StringP(cobraext.DataStreamFlagName, "d", "", cobraext.DataStreamExportFieldsFlagDescription)
+	exportFieldsCmd.Flags().Bool(cobraext.IncludeECSFlagName, false, cobraext.IncludeECSFlagDescription)
+	exportFieldsCmd.Flags().StringP(cobraext.ExportFieldsOutputFlagName, "o", "", cobraext.ExportFieldsOutputFlagDescription)
+
 	cmd := &cobra.Command{
 		Use:   "export",
 		Short: "Export package assets",
@@ -46,6 +59,7 @@ func setupExportCommand() *cobraext.Command {
 	}
 	cmd.AddCommand(exportDashboardCmd)
 	cmd.AddCommand(exportIngestPipelinesCmd)
+	cmd.AddCommand(exportFieldsCmd)
 	cmd.PersistentFlags().StringP(cobraext.ProfileFlagName, "p", "", fmt.Sprintf(cobraext.ProfileFlagDescription, install.ProfileNameEnvVar))
 
 	return cobraext.NewCommand(cmd, cobraext.ContextPackage)
@@ -0,0 +1,121 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/elastic/elastic-package/internal/cobraext"
+	"github.com/elastic/elastic-package/internal/fields"
+	"github.com/elastic/elastic-package/internal/files"
+	"github.com/elastic/elastic-package/internal/install"
+	"github.com/elastic/elastic-package/internal/packages"
+)
+
+const exportFieldsLongDescription = `Use this command to export the definitions of the fields of a data stream, or of an input package.
+
+Fields are exported with external fields resolved. Use --include-ecs to include all the fields of the ECS version the package depends on, and not only the ones imported by the package.
+
+The "jsonschema" format produces a JSON Schema document that can be used to validate events with the fields as nested objects. It checks the types of the values, patterns, allowed and expected values, arrays and the values of constant keywords. Fields not defined in the package are not accepted, except in flattened fields and objects with an object type.`
+
+const exportFieldsFormatJSONSchema = "jsonschema"
+
+var exportFieldsFormats = []string{exportFieldsFormatJSONSchema}
+
+func exportFieldsCommandAction(cmd *cobra.Command, args []string) error {
+	format, err := cmd.Flags().GetString(cobraext.ExportFieldsFormatFlagName)
+	if err != nil {
+		return cobraext.FlagParsingError(err, cobraext.ExportFieldsFormatFlagName)
+	}
+	if !slices.Contains(exportFieldsFormats, format) {
+		return cobraext.FlagParsingError(fmt.Errorf("unsupported format %q, supported formats: %s", format, strings.Join(exportFieldsFormats, ", ")), cobraext.ExportFieldsFormatFlagName)
+	}
+
+	dataStream, err := cmd.Flags().GetString(cobraext.DataStreamFlagName)
+	if err != nil {
+		return cobraext.FlagParsingError(err, cobraext.DataStreamFlagName)
+	}
+
+	includeECS, err := cmd.Flags().GetBool(cobraext.IncludeECSFlagName)
+	if err != nil {
+		return cobraext.FlagParsingError(err, cobraext.IncludeECSFlagName)
+	}
+
+	output, err := cmd.Flags().GetString(cobraext.ExportFieldsOutputFlagName)
+	if err != nil {
+		return cobraext.FlagParsingError(err, cobraext.ExportFieldsOutputFlagName)
+	}
+
+	packageRoot, err := packages.FindPackageRoot()
+	if err != nil {
+		if errors.Is(err, packages.ErrPackageRootNotFound) {
+			return errors.New("package root not found, you can only export fields in the package context")
+		}
+		return fmt.Errorf("locating package root failed: %w", err)
+	}
+
+	manifest, err := packages.ReadPackageManifestFromPackageRoot(packageRoot)
+	if err != nil {
+		return fmt.Errorf("reading package manifest failed (path: %s): %w", packageRoot, err)
+	}
+
+	root := packageRoot
+	title := manifest.Name
+	switch {
+	case manifest.Type == "input" && dataStream != "":
+		return cobraext.FlagParsingError(errors.New("input packages don't have data streams"), cobraext.DataStreamFlagName)
+	case manifest.Type != "input" && dataStream == "":
+		return cobraext.FlagParsingError(errors.New("data stream is required"), cobraext.DataStreamFlagName)
+	case dataStream != "":
+		dsManifest, err := packages.ReadDataStreamManifestFromPackageRoot(packageRoot, dataStream)
+		if err != nil {
+			return cobraext.FlagParsingError(fmt.Errorf("data stream %q not found: %w", dataStream, err), cobraext.DataStreamFlagName)
+		}
+		root = filepath.Join(packageRoot, "data_stream", dataStream)
+		title = dsManifest.Dataset
+		if title == "" {
+			title = manifest.Name + "." + dataStream
+		}
+	}
+
+	repositoryRoot, err := files.FindRepositoryRoot()
+	if err != nil {
+		return fmt.Errorf("locating repository root failed: %w", err)
+	}
+	defer repositoryRoot.Close()
+
+	appConfig, err := install.Configuration()
+	if err != nil {
+		return fmt.Errorf("can't load configuration: %w", err)
+	}
+
+	definitions, err := fields.JSONSchemaFieldDefinitions(repositoryRoot, packageRoot, filepath.Join(root, "fields"), appConfig.SchemaURLs(), includeECS)
+	if err != nil {
+		return err
+	}
+
+	d, err := fields.MarshalJSONSchema(title, definitions)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		_, err = cmd.OutOrStdout().Write(d)
+		return err
+	}
+	err = os.WriteFile(output, d, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write exported fields: %w", err)
+	}
+	cmd.Printf("Fields exported to %s\n", output)
+	return nil
+}
@@ -6,21 +6,32 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/elastic/elastic-package/internal/cobraext"
 	"github.com/elastic/elastic-package/internal/docs"
+	"github.com/elastic/elastic-package/internal/fields"
 	"github.com/elastic/elastic-package/internal/files"
 	"github.com/elastic/elastic-package/internal/install"
-	"github.com/elastic/elastic-package/internal/logger"
+	"github.com/elastic/elastic-package/internal/lint"
 	"github.com/elastic/elastic-package/internal/packages"
 	"github.com/elastic/elastic-package/internal/validation"
 )
 
 const lintLongDescription = `Use this command to validate the contents of a package using the package specification (see: https://github.com/elastic/package-spec).
 
-The command ensures that the package is aligned with the package spec and the README file is up-to-date with its template (if present).`
+The command ensures that the package is aligned with the package spec and the README file is up-to-date with its template (if present).
+
+It also checks that fields are defined with the same type, metric_type, unit and index settings in all the data streams of the package. Fields defined with different settings than in ECS are reported as warnings.
+
+Additional lint rules check conventions of the package contents, as ingest processors having tags, or dashboards having titles prefixed with the package name. Rules can be disabled, or their severity changed, in the "` + lint.ConfigFileName + `" file at the root of the repository. For details, see the [HOWTO guide](https://github.com/elastic/elastic-package/blob/main/docs/howto/lint_rules.md).
+
+Use the --format flag to print all the findings, with their file, line, rule and severity, as text (default), as JSON, as a SARIF log for code scanning tools, or as GitHub Actions workflow commands to annotate pull requests.`
 
 func setupLintCommand() *cobraext.Command {
 	cmd := &cobra.Command{
@@ -28,24 +39,30 @@ func setupLintCommand() *cobraext.Command {
 		Short: "Lint the package",
 		Long:  lintLongDescription,
 		Args:  cobra.NoArgs,
-		RunE: func(cmd *cobra.Command, args []string) error {
-			err := cobraext.ComposeCommandActions(cmd, args,
-				lintCommandAction,
-				validateSourceCommandAction,
-			)
-			if err != nil {
-				return err
-			}
-			cmd.Println("Done")
-			return nil
-		},
+		RunE:  lintCommandAction,
 	}
 
+	cmd.Flags().String(cobraext.LintFormatFlagName, lint.FormatText, fmt.Sprintf(cobraext.LintFormatFlagDescription, strings.Join(lint.Formats, ",")))
+
 	return cobraext.NewCommand(cmd, cobraext.ContextPackage)
 }
 
+// lintCommandAction runs all the checks, and prints their findings in the selected format.
 func lintCommandAction(cmd *cobra.Command, args []string) error {
-	cmd.Println("Lint the package")
+	format, err := cmd.Flags().GetString(cobraext.LintFormatFlagName)
+	if err != nil {
+		return cobraext.FlagParsingError(err, cobraext.LintFormatFlagName)
+	}
+	if format == "" {
+		format = lint.FormatText
+	}
+	if !slices.Contains(lint.Formats, format) {
+		return cobraext.FlagParsingError(fmt.Errorf("unsupported format %q, supported formats: %s", format, strings.Join(lint.Formats, ", ")), cobraext.LintFormatFlagName)
+	}
+
+	if format == lint.FormatText {
+		cmd.Println("Lint the package")
+	}
 
 	repositoryRoot, err := files.FindRepositoryRoot()
 	if err != nil {
@@ -63,32 +80,78 @@ func lintCommandAction(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("can't load configuration: %w", err)
 	}
 
-	readmeFiles, err := docs.AreReadmesUpToDate(repositoryRoot, packageRoot, appConfig.SchemaURLs())
+	findings, err := lintFindings(repositoryRoot, packageRoot, appConfig.SchemaURLs())
+	if err != nil {
+		return err
+	}
+
+	// Paths in machine readable formats are relative to the repository root, as expected by code scanning tools.
+	basePath := ""
+	if format != lint.FormatText {
+		rel, err := filepath.Rel(repositoryRoot.Name(), packageRoot)
+		if err != nil {
+			return fmt.Errorf("locating package in repository failed: %w", err)
+		}
+		basePath = filepath.ToSlash(rel)
+	}
+	output, err := lint.Format(format, findings, basePath)
 	if err != nil {
-		for _, f := range readmeFiles {
-			if !f.UpToDate {
-				cmd.Printf("%s is outdated. Rebuild the package with 'elastic-package build'\n%s", f.FileName, f.Diff)
-			}
-			if f.Error != nil {
-				cmd.Printf("check if %s is up-to-date failed: %s\n", f.FileName, f.Error)
-			}
+		return err
+	}
+	switch {
+	case format != lint.FormatText:
+		fmt.Fprintln(cmd.OutOrStdout(), output)
+	case output != "":
+		cmd.Println(output)
+	}
+
+	errorCount := 0
+	for _, finding := range findings {
+		if finding.Severity == lint.SeverityError {
+			errorCount++
 		}
-		return fmt.Errorf("checking readme files are up-to-date failed: %w", err)
+	}
+	if errorCount > 0 {
+		return fmt.Errorf("linting package failed: found %d errors", errorCount)
+	}
+	if format == lint.FormatText {
+		cmd.Println("Done")
 	}
 	return nil
 }
 
-func validateSourceCommandAction(cmd *cobra.Command, args []string) error {
-	packageRoot, err := packages.FindPackageRoot()
-	if err != nil {
-		return fmt.Errorf("locating package root failed: %w", err)
+// lintFindings runs all the checks of the lint command: README files are up-to-date, the package
+// is valid according to the package spec, fields don't have conflicting definitions, and the lint
+// rules. It returns the findings of all of them.
+func lintFindings(repositoryRoot *os.Root, packageRoot string, schemaURLs fields.SchemaURLs) ([]lint.Finding, error) {
+	readmeFiles, err := docs.AreReadmesUpToDate(repositoryRoot, packageRoot, schemaURLs)
+	findings := lint.ReadmeFindings(readmeFiles)
+	if err != nil && len(findings) == 0 {
+		return nil, fmt.Errorf("checking readme files are up-to-date failed: %w", err)
 	}
+
 	errs, skipped := validation.ValidateAndFilterFromPath(packageRoot)
-	if skipped != nil {
-		logger.Infof("Skipped errors: %v", skipped)
+	findings = append(findings, lint.ValidationFindings(errs, packageRoot, lint.SeverityError)...)
+	findings = append(findings, lint.ValidationFindings(skipped, packageRoot, lint.SeverityInfo)...)
+
+	conflicts, err := fields.FindFieldConflicts(repositoryRoot, packageRoot, schemaURLs)
+	if err != nil {
+		return nil, fmt.Errorf("looking for conflicts between field definitions failed: %w", err)
 	}
-	if errs != nil {
-		return fmt.Errorf("linting package failed: %w", errs)
+	findings = append(findings, lint.FieldConflictFindings(conflicts)...)
+
+	config, err := lint.LoadConfig(repositoryRoot.Name())
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	ruleFindings, err := lint.Run(packageRoot, config,
+		lint.WithRepositoryRoot(repositoryRoot),
+		lint.WithSchemaURLs(schemaURLs),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("running lint rules failed: %w", err)
+	}
+	findings = append(findings, ruleFindings...)
+
+	return findings, nil
 }
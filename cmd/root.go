@@ -40,6 +40,7 @@ var commands = []*cobraext.Command{
 	setupStatusCommand(),
 	setupTestCommand(),
 	setupUninstallCommand(),
+	setupVerifyCommand(),
 	setupVersionCommand(),
 }
 
@@ -13,14 +13,22 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/olekukonko/tablewriter"
+	"github.com/olekukonko/tablewriter/renderer"
+	"github.com/olekukonko/tablewriter/tw"
 	"github.com/spf13/cobra"
 
 	"github.com/elastic/elastic-package/internal/cobraext"
 	"github.com/elastic/elastic-package/internal/common"
+	"github.com/elastic/elastic-package/internal/elasticsearch"
+	"github.com/elastic/elastic-package/internal/fields"
 	"github.com/elastic/elastic-package/internal/files"
 	"github.com/elastic/elastic-package/internal/install"
+	"github.com/elastic/elastic-package/internal/kibana"
 	"github.com/elastic/elastic-package/internal/logger"
 	"github.com/elastic/elastic-package/internal/packages"
+	"github.com/elastic/elastic-package/internal/profile"
+	"github.com/elastic/elastic-package/internal/recording"
 	"github.com/elastic/elastic-package/internal/signal"
 	"github.com/elastic/elastic-package/internal/stack"
 	"github.com/elastic/elastic-package/internal/testrunner"
@@ -120,10 +128,11 @@ func getTestRunnerAssetCommand() *cobra.Command {
 		RunE:  testRunnerAssetCommandAction,
 	}
 
+	addRecordingFlags(cmd)
 	return cmd
 }
 
-func testRunnerAssetCommandAction(cmd *cobra.Command, args []string) error {
+func testRunnerAssetCommandAction(cmd *cobra.Command, args []string) (err error) {
 	cmd.Printf("Run asset tests for the package\n")
 	testType := testrunner.TestType("asset")
 
@@ -172,10 +181,18 @@ func testRunnerAssetCommandAction(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("reading package manifest failed (path: %s): %w", packageRoot, err)
 	}
 
+	recorder, err := getRecorderFlags(cmd, testType)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, stopRecorder(recorder))
+	}()
+
 	ctx, stop := signal.Enable(cmd.Context(), logger.Info)
 	defer stop()
 
-	kibanaClient, err := stack.NewKibanaClientFromProfile(profile)
+	kibanaClient, err := newTestKibanaClient(profile, recorder)
 	if err != nil {
 		return fmt.Errorf("can't create Kibana client: %w", err)
 	}
@@ -322,11 +339,13 @@ func getTestRunnerPipelineCommand() *cobra.Command {
 	cmd.Flags().BoolP(cobraext.FailOnMissingFlagName, "m", false, cobraext.FailOnMissingFlagDescription)
 	cmd.Flags().BoolP(cobraext.GenerateTestResultFlagName, "g", false, cobraext.GenerateTestResultFlagDescription)
 	cmd.Flags().StringSliceP(cobraext.DataStreamsFlagName, "d", nil, cobraext.DataStreamsFlagDescription)
+	cmd.Flags().Bool(cobraext.ReportUnusedFieldsFlagName, false, cobraext.ReportUnusedFieldsFlagDescription)
+	addRecordingFlags(cmd)
 
 	return cmd
 }
 
-func testRunnerPipelineCommandAction(cmd *cobra.Command, args []string) error {
+func testRunnerPipelineCommandAction(cmd *cobra.Command, args []string) (err error) {
 	cmd.Printf("Run pipeline tests for the package\n")
 	testType := testrunner.TestType("pipeline")
 
@@ -374,6 +393,11 @@ func testRunnerPipelineCommandAction(cmd *cobra.Command, args []string) error {
 		return cobraext.FlagParsingError(err, cobraext.DeferCleanupFlagName)
 	}
 
+	fieldsUsage, err := getFieldsUsageFlag(cmd)
+	if err != nil {
+		return err
+	}
+
 	repositoryRoot, err := files.FindRepositoryRoot()
 	if err != nil {
 		return fmt.Errorf("locating repository root failed: %w", err)
@@ -390,10 +414,18 @@ func testRunnerPipelineCommandAction(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	recorder, err := getRecorderFlags(cmd, testType)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, stopRecorder(recorder))
+	}()
+
 	ctx, stop := signal.Enable(cmd.Context(), logger.Info)
 	defer stop()
 
-	esClient, err := stack.NewElasticsearchClientFromProfile(profile)
+	esClient, err := newTestElasticsearchClient(profile, recorder)
 	if err != nil {
 		return fmt.Errorf("can't create Elasticsearch client: %w", err)
 	}
@@ -437,6 +469,7 @@ func testRunnerPipelineCommandAction(cmd *cobra.Command, args []string) error {
 		GlobalTestConfig:   globalTestConfig.Pipeline,
 		RepositoryRoot:     repositoryRoot,
 		SchemaURLs:         appConfig.SchemaURLs(),
+		FieldsUsage:        fieldsUsage,
 	})
 
 	results, err := testrunner.RunSuite(ctx, runner)
@@ -444,6 +477,8 @@ func testRunnerPipelineCommandAction(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	printUnusedFieldsReport(cmd, packageRoot, fieldsUsage)
+
 	return processResults(results, testType, reportFormat, reportOutput, packageRoot, manifest.Name, manifest.Type, testCoverageFormat, testCoverage)
 }
 
@@ -466,6 +501,8 @@ func getTestRunnerSystemCommand() *cobra.Command {
 	cmd.Flags().Bool(cobraext.TearDownFlagName, false, cobraext.TearDownFlagDescription)
 	cmd.Flags().Bool(cobraext.NoProvisionFlagName, false, cobraext.NoProvisionFlagDescription)
 	cmd.Flags().String(cobraext.AgentVersionFlagName, "", cobraext.AgentVersionFlagDescription)
+	cmd.Flags().Bool(cobraext.ReportUnusedFieldsFlagName, false, cobraext.ReportUnusedFieldsFlagDescription)
+	cmd.Flags().StringSlice(cobraext.IndexModesFlagName, nil, fmt.Sprintf(cobraext.IndexModesFlagDescription, strings.Join(system.IndexModesList(), ",")))
 
 	cmd.MarkFlagsMutuallyExclusive(cobraext.SetupFlagName, cobraext.TearDownFlagName, cobraext.NoProvisionFlagName)
 	cmd.MarkFlagsRequiredTogether(cobraext.ConfigFileFlagName, cobraext.SetupFlagName)
@@ -481,6 +518,11 @@ func getTestRunnerSystemCommand() *cobra.Command {
 	cmd.MarkFlagsMutuallyExclusive(cobraext.DataStreamsFlagName, cobraext.TearDownFlagName)
 	cmd.MarkFlagsMutuallyExclusive(cobraext.DataStreamsFlagName, cobraext.NoProvisionFlagName)
 
+	// index modes flag requires to run the whole test on each mode
+	cmd.MarkFlagsMutuallyExclusive(cobraext.IndexModesFlagName, cobraext.SetupFlagName)
+	cmd.MarkFlagsMutuallyExclusive(cobraext.IndexModesFlagName, cobraext.TearDownFlagName)
+	cmd.MarkFlagsMutuallyExclusive(cobraext.IndexModesFlagName, cobraext.NoProvisionFlagName)
+
 	return cmd
 }
 
@@ -536,6 +578,24 @@ func testRunnerSystemCommandAction(cmd *cobra.Command, args []string) error {
 		return cobraext.FlagParsingError(err, cobraext.VariantFlagName)
 	}
 
+	fieldsUsage, err := getFieldsUsageFlag(cmd)
+	if err != nil {
+		return err
+	}
+
+	indexModes, err := cmd.Flags().GetStringSlice(cobraext.IndexModesFlagName)
+	if err != nil {
+		return cobraext.FlagParsingError(err, cobraext.IndexModesFlagName)
+	}
+	for i, mode := range indexModes {
+		if !slices.Contains(system.IndexModesList(), mode) {
+			return cobraext.FlagParsingError(fmt.Errorf("index mode not available: %s", mode), cobraext.IndexModesFlagName)
+		}
+		if slices.Contains(indexModes[:i], mode) {
+			return cobraext.FlagParsingError(fmt.Errorf("index mode repeated: %s", mode), cobraext.IndexModesFlagName)
+		}
+	}
+
 	packageRoot, err := packages.FindPackageRoot()
 	if err != nil {
 		return fmt.Errorf("locating package root failed: %w", err)
@@ -643,6 +703,7 @@ func testRunnerSystemCommandAction(cmd *cobra.Command, args []string) error {
 		RunTestsOnly:         runTestsOnly,
 		DataStreams:          dataStreams,
 		ServiceVariant:       variantFlag,
+		IndexModes:           indexModes,
 		FailOnMissingTests:   failOnMissing,
 		GenerateTestResult:   generateTestResult,
 		DeferCleanup:         deferCleanup,
@@ -651,6 +712,7 @@ func testRunnerSystemCommandAction(cmd *cobra.Command, args []string) error {
 		CoverageType:         testCoverageFormat,
 		RepositoryRoot:       repositoryRoot,
 		OverrideAgentVersion: agentVersion,
+		FieldsUsage:          fieldsUsage,
 	})
 
 	logger.Debugf("Running suite...")
@@ -659,6 +721,8 @@ func testRunnerSystemCommandAction(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	printUnusedFieldsReport(cmd, packageRoot, fieldsUsage)
+
 	err = processResults(results, runner.Type(), reportFormat, reportOutput, packageRoot, manifest.Name, manifest.Type, testCoverageFormat, testCoverage)
 	if err != nil {
 		return fmt.Errorf("failed to process results: %w", err)
@@ -683,6 +747,8 @@ func getTestRunnerScriptCommand() *cobra.Command {
 	cmd.Flags().BoolP(cobraext.WorkScriptTestFlagName, "w", false, cobraext.WorkScriptTestFlagDescription)
 	cmd.Flags().Bool(cobraext.ContinueOnErrorFlagName, false, cobraext.ContinueOnErrorFlagDescription)
 	cmd.Flags().Bool(cobraext.VerboseScriptFlagName, false, cobraext.VerboseScriptFlagDescription)
+	cmd.Flags().Int(cobraext.ParallelScriptTestFlagName, 1, cobraext.ParallelScriptTestFlagDescription)
+	cmd.Flags().Duration(cobraext.ScriptTimeoutFlagName, 0, cobraext.ScriptTimeoutFlagDescription)
 
 	cmd.MarkFlagsMutuallyExclusive(cobraext.ScriptsFlagName, cobraext.DataStreamsFlagName)
 
@@ -728,6 +794,14 @@ func testRunnerScriptCommandAction(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	opts.Parallel, err = cmd.Flags().GetInt(cobraext.ParallelScriptTestFlagName)
+	if err != nil {
+		return cobraext.FlagParsingError(err, cobraext.ParallelScriptTestFlagName)
+	}
+	opts.Timeout, err = cmd.Flags().GetDuration(cobraext.ScriptTimeoutFlagName)
+	if err != nil {
+		return cobraext.FlagParsingError(err, cobraext.ScriptTimeoutFlagName)
+	}
 
 	pkgRoot, err := packages.FindPackageRoot()
 	if err != nil {
@@ -758,7 +832,13 @@ func testRunnerScriptCommandAction(cmd *cobra.Command, args []string) error {
 		return cobraext.FlagParsingError(err, cobraext.TestCoverageFormatFlagName)
 	}
 
+	if !slices.Contains(testrunner.CoverageFormatsList(), testCoverageFormat) {
+		return cobraext.FlagParsingError(fmt.Errorf("coverage format not available: %s", testCoverageFormat), cobraext.TestCoverageFormatFlagName)
+	}
+
 	opts.Package = manifest.Name
+	opts.WithCoverage = testCoverage
+	opts.CoverageType = testCoverageFormat
 
 	var results []testrunner.TestResult
 	err = script.Run(&results, cmd.OutOrStderr(), opts)
@@ -780,10 +860,15 @@ func getTestRunnerPolicyCommand() *cobra.Command {
 	cmd.Flags().BoolP(cobraext.FailOnMissingFlagName, "m", false, cobraext.FailOnMissingFlagDescription)
 	cmd.Flags().StringSliceP(cobraext.DataStreamsFlagName, "d", nil, cobraext.DataStreamsFlagDescription)
 	cmd.Flags().BoolP(cobraext.GenerateTestResultFlagName, "g", false, cobraext.GenerateTestResultFlagDescription)
+	cmd.Flags().Bool(cobraext.OfflineFlagName, false, cobraext.OfflineFlagDescription)
+	addRecordingFlags(cmd)
+
+	cmd.MarkFlagsMutuallyExclusive(cobraext.OfflineFlagName, cobraext.RecordFlagName)
+	cmd.MarkFlagsMutuallyExclusive(cobraext.OfflineFlagName, cobraext.ReplayFlagName)
 	return cmd
 }
 
-func testRunnerPolicyCommandAction(cmd *cobra.Command, args []string) error {
+func testRunnerPolicyCommandAction(cmd *cobra.Command, args []string) (err error) {
 	cmd.Printf("Run policy tests for the package\n")
 	testType := testrunner.TestType("policy")
 
@@ -802,6 +887,11 @@ func testRunnerPolicyCommandAction(cmd *cobra.Command, args []string) error {
 		return cobraext.FlagParsingError(err, cobraext.GenerateTestResultFlagName)
 	}
 
+	offline, err := cmd.Flags().GetBool(cobraext.OfflineFlagName)
+	if err != nil {
+		return cobraext.FlagParsingError(err, cobraext.OfflineFlagName)
+	}
+
 	reportFormat, err := cmd.Flags().GetString(cobraext.ReportFormatFlagName)
 	if err != nil {
 		return cobraext.FlagParsingError(err, cobraext.ReportFormatFlagName)
@@ -842,13 +932,16 @@ func testRunnerPolicyCommandAction(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	ctx, stop := signal.Enable(cmd.Context(), logger.Info)
-	defer stop()
-
-	kibanaClient, err := stack.NewKibanaClientFromProfile(profile)
+	recorder, err := getRecorderFlags(cmd, testType)
 	if err != nil {
-		return fmt.Errorf("can't create Kibana client: %w", err)
+		return err
 	}
+	defer func() {
+		err = errors.Join(err, stopRecorder(recorder))
+	}()
+
+	ctx, stop := signal.Enable(cmd.Context(), logger.Info)
+	defer stop()
 
 	manifest, err := packages.ReadPackageManifestFromPackageRoot(packageRoot)
 	if err != nil {
@@ -860,24 +953,34 @@ func testRunnerPolicyCommandAction(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to read global config: %w", err)
 	}
 
-	stackVersion, err := kibanaClient.Version()
-	if err != nil {
-		return fmt.Errorf("fetching stack version failed: %w", err)
-	}
-
 	appConfig, err := install.Configuration()
 	if err != nil {
 		return fmt.Errorf("can't load configuration: %w", err)
 	}
 
 	logger.Info(version.Version())
-	logger.Infof("elastic-stack: %s", stackVersion.Version())
+
+	var kibanaClient *kibana.Client
+	if !offline {
+		kibanaClient, err = newTestKibanaClient(profile, recorder)
+		if err != nil {
+			return fmt.Errorf("can't create Kibana client: %w", err)
+		}
+
+		stackVersion, err := kibanaClient.Version()
+		if err != nil {
+			return fmt.Errorf("fetching stack version failed: %w", err)
+		}
+		logger.Infof("elastic-stack: %s", stackVersion.Version())
+	}
+
 	runner := policy.NewPolicyTestRunner(policy.PolicyTestRunnerOptions{
 		PackageRoot:        packageRoot,
 		KibanaClient:       kibanaClient,
 		DataStreams:        dataStreams,
 		FailOnMissingTests: failOnMissing,
 		GenerateTestResult: generateTestResult,
+		Offline:            offline,
 		GlobalTestConfig:   globalTestConfig.Policy,
 		WithCoverage:       testCoverage,
 		CoverageType:       testCoverageFormat,
@@ -893,6 +996,59 @@ func testRunnerPolicyCommandAction(cmd *cobra.Command, args []string) error {
 	return processResults(results, testType, reportFormat, reportOutput, packageRoot, manifest.Name, manifest.Type, testCoverageFormat, testCoverage)
 }
 
+// getFieldsUsageFlag returns a tracker of the fields seen in validated documents if
+// the report of unused fields is requested, nil otherwise.
+func getFieldsUsageFlag(cmd *cobra.Command) (*fields.FieldsUsage, error) {
+	reportUnusedFields, err := cmd.Flags().GetBool(cobraext.ReportUnusedFieldsFlagName)
+	if err != nil {
+		return nil, cobraext.FlagParsingError(err, cobraext.ReportUnusedFieldsFlagName)
+	}
+	if !reportUnusedFields {
+		return nil, nil
+	}
+	return fields.NewFieldsUsage(), nil
+}
+
+// printUnusedFieldsReport prints the fields defined in each fields directory that haven't
+// been found in any validated document.
+func printUnusedFieldsReport(cmd *cobra.Command, packageRoot string, usage *fields.FieldsUsage) {
+	if usage == nil {
+		return
+	}
+
+	w := cmd.OutOrStderr()
+	fieldsDirs := usage.FieldsDirs()
+	if len(fieldsDirs) == 0 {
+		fmt.Fprintln(w, "No documents were validated, unused fields cannot be reported.")
+		return
+	}
+
+	config := defaultColorizedConfig()
+	config.Settings.Separators.BetweenRows = tw.Off
+	table := tablewriter.NewTable(w,
+		tablewriter.WithRenderer(renderer.NewColorized(config)),
+		tablewriter.WithConfig(defaultTableConfig),
+	)
+	table.Header("Fields directory", "Unused field")
+	total := 0
+	for _, dir := range fieldsDirs {
+		relDir, err := filepath.Rel(packageRoot, dir)
+		if err != nil {
+			relDir = dir
+		}
+		for _, name := range usage.UnseenFields(dir) {
+			table.Append(relDir, name)
+			total++
+		}
+	}
+	if total == 0 {
+		fmt.Fprintln(w, "All defined fields were found in validated documents.")
+		return
+	}
+	fmt.Fprintf(w, "Fields defined but not found in any validated document (%d):\n", total)
+	table.Render()
+}
+
 func processResults(results []testrunner.TestResult, testType testrunner.TestType, reportFormat, reportOutput, packageRoot, packageName, packageType, testCoverageFormat string, testCoverage bool) error {
 	sort.Slice(results, func(i, j int) bool {
 		if results[i].Package != results[j].Package {
@@ -947,6 +1103,82 @@ func validateDataStreamsFlag(packageRoot string, dataStreams []string) error {
 	return nil
 }
 
+func addRecordingFlags(cmd *cobra.Command) {
+	cmd.Flags().String(cobraext.RecordFlagName, "", cobraext.RecordFlagDescription)
+	cmd.Flags().String(cobraext.ReplayFlagName, "", cobraext.ReplayFlagDescription)
+	cmd.MarkFlagsMutuallyExclusive(cobraext.RecordFlagName, cobraext.ReplayFlagName)
+}
+
+// getRecorderFlags returns a recorder for the interactions with the stack if the
+// record or replay flags are used, or nil otherwise. Each test type uses its own
+// recordings in the given directory.
+func getRecorderFlags(cmd *cobra.Command, testType testrunner.TestType) (*recording.Recorder, error) {
+	recordDir, err := cmd.Flags().GetString(cobraext.RecordFlagName)
+	if err != nil {
+		return nil, cobraext.FlagParsingError(err, cobraext.RecordFlagName)
+	}
+	replayDir, err := cmd.Flags().GetString(cobraext.ReplayFlagName)
+	if err != nil {
+		return nil, cobraext.FlagParsingError(err, cobraext.ReplayFlagName)
+	}
+
+	switch {
+	case recordDir != "":
+		logger.Infof("Recording interactions with the stack in %s", recordDir)
+		return recording.NewRecorder(filepath.Join(recordDir, string(testType)), recording.ModeRecord), nil
+	case replayDir != "":
+		logger.Infof("Replaying interactions with the stack from %s", replayDir)
+		return recording.NewRecorder(filepath.Join(replayDir, string(testType)), recording.ModeReplay), nil
+	default:
+		return nil, nil
+	}
+}
+
+// newTestElasticsearchClient creates an Elasticsearch client for the given profile, whose
+// interactions are recorded or replayed if a recorder is provided.
+func newTestElasticsearchClient(profile *profile.Profile, recorder *recording.Recorder) (*elasticsearch.Client, error) {
+	if recorder == nil {
+		return stack.NewElasticsearchClientFromProfile(profile)
+	}
+	options, err := recorder.ElasticsearchClientOptions()
+	if err != nil {
+		return nil, err
+	}
+	if recorder.Replaying() {
+		return stack.NewElasticsearchClient(options...)
+	}
+	return stack.NewElasticsearchClientFromProfile(profile, options...)
+}
+
+// newTestKibanaClient creates a Kibana client for the given profile, whose interactions
+// are recorded or replayed if a recorder is provided.
+func newTestKibanaClient(profile *profile.Profile, recorder *recording.Recorder) (*kibana.Client, error) {
+	if recorder == nil {
+		return stack.NewKibanaClientFromProfile(profile)
+	}
+	options, err := recorder.KibanaClientOptions()
+	if err != nil {
+		return nil, err
+	}
+	if recorder.Replaying() {
+		return stack.NewKibanaClient(options...)
+	}
+	return stack.NewKibanaClientFromProfile(profile, options...)
+}
+
+// stopRecorder stops the recorder, if any. It is deferred by commands so interactions are
+// stored also when tests fail.
+func stopRecorder(recorder *recording.Recorder) error {
+	if recorder == nil {
+		return nil
+	}
+	err := recorder.Stop()
+	if err != nil {
+		return fmt.Errorf("failed to store recorded interactions: %w", err)
+	}
+	return nil
+}
+
 func getDataStreamsFlag(cmd *cobra.Command, packageRoot string) ([]string, error) {
 	dataStreams, err := cmd.Flags().GetStringSlice(cobraext.DataStreamsFlagName)
 	common.TrimStringSlice(dataStreams)
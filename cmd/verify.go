@@ -0,0 +1,86 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/elastic/elastic-package/internal/cobraext"
+	"github.com/elastic/elastic-package/internal/files"
+	"github.com/elastic/elastic-package/internal/install"
+	"github.com/elastic/elastic-package/internal/packages"
+	"github.com/elastic/elastic-package/internal/validation"
+)
+
+const verifyLongDescription = `Use this command to verify a zipped package before publishing or installing it.
+
+The command checks the detached signature of the package, created with "elastic-package build --sign", validates the package against the package specification, and reports the name and version of the package it contains. It fails if any of these checks fails.
+
+The public key used to verify the signature can be provided with the "--public-key" flag, or configured in the "signing.public_keyfile" setting of the elastic-package configuration file located at ~/.elastic-package/config.yml. By default, the signature is read from the file with the same path as the package and the ".sig" extension.`
+
+func setupVerifyCommand() *cobraext.Command {
+	cmd := &cobra.Command{
+		Use:   "verify <zip>",
+		Short: "Verify the signature and contents of a zipped package",
+		Long:  verifyLongDescription,
+		Args:  cobra.ExactArgs(1),
+		RunE:  verifyCommandAction,
+	}
+	cmd.Flags().String(cobraext.VerifyPublicKeyFlagName, "", cobraext.VerifyPublicKeyFlagDescription)
+	cmd.Flags().String(cobraext.VerifySignatureFlagName, "", cobraext.VerifySignatureFlagDescription)
+
+	return cobraext.NewCommand(cmd, cobraext.ContextGlobal)
+}
+
+func verifyCommandAction(cmd *cobra.Command, args []string) error {
+	zipPath := args[0]
+
+	publicKeyfile, err := cmd.Flags().GetString(cobraext.VerifyPublicKeyFlagName)
+	if err != nil {
+		return cobraext.FlagParsingError(err, cobraext.VerifyPublicKeyFlagName)
+	}
+	signatureFile, err := cmd.Flags().GetString(cobraext.VerifySignatureFlagName)
+	if err != nil {
+		return cobraext.FlagParsingError(err, cobraext.VerifySignatureFlagName)
+	}
+	if signatureFile == "" {
+		signatureFile = zipPath + ".sig"
+	}
+
+	if publicKeyfile == "" {
+		appConfig, err := install.Configuration()
+		if err != nil {
+			return fmt.Errorf("can't load configuration: %w", err)
+		}
+		publicKeyfile = appConfig.SignerPublicKeyfile()
+	}
+	if publicKeyfile == "" {
+		return errors.New("public key is required, please provide it with --public-key or configure it in signing.public_keyfile")
+	}
+
+	cmd.Printf("Verify the package: %s\n", zipPath)
+
+	err = files.Verify(zipPath, signatureFile, publicKeyfile)
+	if err != nil {
+		return fmt.Errorf("verifying signature failed: %w", err)
+	}
+	cmd.Println("Signature is valid")
+
+	err = validation.ValidateFromZip(zipPath)
+	if err != nil {
+		return fmt.Errorf("validating package failed: %w", err)
+	}
+	cmd.Println("Package is valid")
+
+	manifest, err := packages.ReadPackageManifestFromZipPackage(zipPath)
+	if err != nil {
+		return fmt.Errorf("reading package manifest failed: %w", err)
+	}
+	cmd.Printf("Package verified: %s %s\n", manifest.Name, manifest.Version)
+	return nil
+}
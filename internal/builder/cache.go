@@ -0,0 +1,231 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package builder
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+
+	"github.com/elastic/elastic-package/internal/files"
+	"github.com/elastic/elastic-package/internal/version"
+)
+
+const buildCacheDir = "cache"
+
+// buildCacheEntry is the content of the cache file of a built package.
+type buildCacheEntry struct {
+	// Key is the hash of all the inputs of the build.
+	Key string `json:"key"`
+
+	// Target is the path to the result of the build.
+	Target string `json:"target"`
+
+	// Checksum is the hash of the result of the build, including its signature if any.
+	Checksum string `json:"checksum"`
+}
+
+// buildCache stores the key of the last build of a package, so it is not built again if
+// its inputs don't change.
+type buildCache struct {
+	path string
+}
+
+// newBuildCache returns the cache of the package built in buildPackageRoot. Cache files are
+// stored in the build directory, in the form <buildDir>/cache/<package name>-<package version>.json.
+func newBuildCache(buildPackageRoot string) *buildCache {
+	version := filepath.Base(buildPackageRoot)
+	name := filepath.Base(filepath.Dir(buildPackageRoot))
+	buildDir := filepath.Dir(filepath.Dir(filepath.Dir(buildPackageRoot)))
+	return &buildCache{
+		path: filepath.Join(buildDir, buildCacheDir, fmt.Sprintf("%s-%s.json", name, version)),
+	}
+}
+
+// lookup returns the target of the last build if it was built with the same key, and
+// the target still exists and has not been modified.
+func (c *buildCache) lookup(key string, options BuildOptions) (string, bool) {
+	d, err := os.ReadFile(c.path)
+	if err != nil {
+		return "", false
+	}
+	var entry buildCacheEntry
+	if err := json.Unmarshal(d, &entry); err != nil {
+		return "", false
+	}
+	if entry.Key != key {
+		return "", false
+	}
+
+	checksum, err := buildTargetsChecksum(buildTargets(entry.Target, options))
+	if err != nil || checksum != entry.Checksum {
+		return "", false
+	}
+	return entry.Target, true
+}
+
+// store saves the key of the build that produced the target, and the checksum of the target.
+func (c *buildCache) store(key, target string, options BuildOptions) error {
+	checksum, err := buildTargetsChecksum(buildTargets(target, options))
+	if err != nil {
+		return fmt.Errorf("calculating checksum of build target failed: %w", err)
+	}
+	d, err := json.MarshalIndent(buildCacheEntry{Key: key, Target: target, Checksum: checksum}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding build cache entry failed: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("creating build cache directory failed: %w", err)
+	}
+	if err := os.WriteFile(c.path, d, 0644); err != nil {
+		return fmt.Errorf("writing build cache file failed: %w", err)
+	}
+	return nil
+}
+
+// buildTargets returns the paths of the results of a build.
+func buildTargets(target string, options BuildOptions) []string {
+	targets := []string{target}
+	if options.SignPackage {
+		targets = append(targets, target+".sig")
+	}
+	return targets
+}
+
+// buildTargetsChecksum calculates a hash of the contents of the targets, that can be files or
+// directories.
+func buildTargetsChecksum(targets []string) (string, error) {
+	h := sha256.New()
+	for _, target := range targets {
+		err := filepath.WalkDir(target, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			fmt.Fprintf(h, "file:%s:", filepath.ToSlash(path))
+			return hashFile(h, path)
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// invalidate removes the cache entry, it must be called before building the package, so a
+// failed build doesn't leave a valid entry for a target in an unknown state.
+func (c *buildCache) invalidate() error {
+	err := os.Remove(c.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("removing build cache file failed: %w", err)
+	}
+	return nil
+}
+
+// buildCacheKey calculates a hash of all the inputs of the build: the package source, including
+// development files as they are used to build the readme and to resolve external fields, the
+// contents of linked files, the license file, the schema URLs, the build options, the modification
+// time of the files in zipped packages and the version of elastic-package.
+func buildCacheKey(options BuildOptions) (string, error) {
+	h := sha256.New()
+
+	fmt.Fprintf(h, "version:%s\n", buildCacheVersion())
+	fmt.Fprintf(h, "options:zip=%t,sign=%t,skip-validation=%t,update-readmes=%t\n",
+		options.CreateZip, options.SignPackage, options.SkipValidation, options.UpdateReadmes)
+	fmt.Fprintf(h, "ecs-base:%s\n", options.SchemaURLs.ECSBase())
+	if options.CreateZip {
+		modTime, err := zipModTime(options.PackageRoot)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "zip-mod-time:%d\n", modTime.Unix())
+	}
+
+	err := filepath.WalkDir(options.PackageRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(options.PackageRoot, path)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "file:%s:", filepath.ToSlash(rel))
+		return hashFile(h, path)
+	})
+	if err != nil {
+		return "", fmt.Errorf("hashing package contents failed: %w", err)
+	}
+
+	linksFS, err := files.CreateLinksFSFromPath(options.RepositoryRoot, options.PackageRoot)
+	if err != nil {
+		return "", fmt.Errorf("creating links filesystem failed: %w", err)
+	}
+	links, err := linksFS.ListLinkedFiles()
+	if err != nil {
+		return "", fmt.Errorf("listing linked files failed: %w", err)
+	}
+	for _, l := range links {
+		fmt.Fprintf(h, "link:%s:%s\n", filepath.ToSlash(l.TargetRelPath), l.IncludedFileContentsChecksum)
+	}
+
+	repositoryLicenseTextFileName, userDefined := os.LookupEnv(repositoryLicenseEnv)
+	if !userDefined {
+		repositoryLicenseTextFileName = licenseTextFileName
+	}
+	fmt.Fprintf(h, "license:%s:", repositoryLicenseTextFileName)
+	licensePath, err := findRepositoryLicensePath(options.RepositoryRoot, repositoryLicenseTextFileName)
+	if err == nil {
+		if err := hashFile(h, licensePath); err != nil {
+			return "", fmt.Errorf("hashing license file failed: %w", err)
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashFile(h hash.Hash, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fh := sha256.New()
+	if _, err := io.Copy(fh, f); err != nil {
+		return err
+	}
+	fmt.Fprintf(h, "%x\n", fh.Sum(nil))
+	return nil
+}
+
+// buildCacheVersion returns the version of elastic-package, including the revision of the source
+// code when available, so development builds don't reuse packages built by other binaries.
+func buildCacheVersion() string {
+	v := version.Version()
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return v
+	}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision", "vcs.modified":
+			v += " " + setting.Key + "=" + setting.Value
+		}
+	}
+	return v
+}
@@ -0,0 +1,126 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package builder
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildCacheKey(t *testing.T) {
+	repositoryRoot, err := os.OpenRoot(t.TempDir())
+	require.NoError(t, err)
+	defer repositoryRoot.Close()
+
+	packageRoot := filepath.Join(repositoryRoot.Name(), "packages", "foo")
+	require.NoError(t, os.MkdirAll(filepath.Join(packageRoot, "_dev", "build"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(packageRoot, "manifest.yml"), []byte("name: foo\ntype: integration\nversion: 1.0.0\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(packageRoot, "_dev", "build", "build.yml"), []byte("ecs: 8.11\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(repositoryRoot.Name(), "shared.yml"), []byte("foo: bar\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(packageRoot, "shared.yml.link"), []byte("../../shared.yml\n"), 0644))
+
+	options := BuildOptions{
+		PackageRoot:    packageRoot,
+		RepositoryRoot: repositoryRoot,
+		CreateZip:      true,
+	}
+	key, err := buildCacheKey(options)
+	require.NoError(t, err)
+
+	t.Run("same inputs", func(t *testing.T) {
+		other, err := buildCacheKey(options)
+		require.NoError(t, err)
+		assert.Equal(t, key, other)
+	})
+
+	t.Run("different options", func(t *testing.T) {
+		options := options
+		options.CreateZip = false
+		other, err := buildCacheKey(options)
+		require.NoError(t, err)
+		assert.NotEqual(t, key, other)
+	})
+
+	t.Run("license added", func(t *testing.T) {
+		require.NoError(t, repositoryRoot.WriteFile(licenseTextFileName, []byte("license"), 0644))
+		defer repositoryRoot.Remove(licenseTextFileName)
+
+		other, err := buildCacheKey(options)
+		require.NoError(t, err)
+		assert.NotEqual(t, key, other)
+	})
+
+	t.Run("development file changed", func(t *testing.T) {
+		path := filepath.Join(packageRoot, "_dev", "build", "build.yml")
+		require.NoError(t, os.WriteFile(path, []byte("ecs: 8.12\n"), 0644))
+		defer os.WriteFile(path, []byte("ecs: 8.11\n"), 0644)
+
+		other, err := buildCacheKey(options)
+		require.NoError(t, err)
+		assert.NotEqual(t, key, other)
+	})
+
+	t.Run("linked file changed", func(t *testing.T) {
+		path := filepath.Join(repositoryRoot.Name(), "shared.yml")
+		require.NoError(t, os.WriteFile(path, []byte("foo: baz\n"), 0644))
+		defer os.WriteFile(path, []byte("foo: bar\n"), 0644)
+
+		other, err := buildCacheKey(options)
+		require.NoError(t, err)
+		assert.NotEqual(t, key, other)
+	})
+}
+
+func TestBuildCache(t *testing.T) {
+	buildDir := t.TempDir()
+	buildPackageRoot := filepath.Join(buildDir, "packages", "foo", "1.0.0")
+	target := filepath.Join(buildDir, "packages", "foo-1.0.0.zip")
+	cache := newBuildCache(buildPackageRoot)
+	assert.Equal(t, filepath.Join(buildDir, "cache", "foo-1.0.0.json"), cache.path)
+
+	options := BuildOptions{CreateZip: true}
+
+	_, found := cache.lookup("key", options)
+	assert.False(t, found, "nothing built yet")
+
+	assert.Error(t, cache.store("key", target, options), "target doesn't exist")
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(target), 0755))
+	require.NoError(t, os.WriteFile(target, []byte("zip"), 0644))
+	require.NoError(t, cache.store("key", target, options))
+	cached, found := cache.lookup("key", options)
+	assert.True(t, found)
+	assert.Equal(t, target, cached)
+
+	require.NoError(t, os.WriteFile(target, []byte("modified zip"), 0644))
+	_, found = cache.lookup("key", options)
+	assert.False(t, found, "target modified")
+
+	require.NoError(t, os.WriteFile(target, []byte("zip"), 0644))
+	_, found = cache.lookup("key", options)
+	assert.True(t, found, "target restored")
+
+	require.NoError(t, os.Remove(target))
+	_, found = cache.lookup("key", options)
+	assert.False(t, found, "target doesn't exist")
+	require.NoError(t, os.WriteFile(target, []byte("zip"), 0644))
+
+	_, found = cache.lookup("other", options)
+	assert.False(t, found, "different key")
+
+	options.SignPackage = true
+	_, found = cache.lookup("key", options)
+	assert.False(t, found, "signature doesn't exist")
+
+	require.NoError(t, cache.invalidate())
+	require.NoError(t, cache.invalidate())
+	options.SignPackage = false
+	_, found = cache.lookup("key", options)
+	assert.False(t, found, "invalidated")
+}
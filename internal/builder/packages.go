@@ -36,6 +36,9 @@ type BuildOptions struct {
 	SkipValidation bool
 	UpdateReadmes  bool
 	SchemaURLs     fields.SchemaURLs
+
+	// UseCache skips the build if the package hasn't changed since the last time it was built.
+	UseCache bool
 }
 
 // BuildDirectory function locates the target build directory. If the directory doesn't exist, it will create it.
@@ -175,8 +178,44 @@ func BuildPackage(options BuildOptions) (string, error) {
 	}
 	logger.Debugf("Build directory: %s\n", buildPackageRoot)
 
+	if !options.UseCache {
+		return buildPackage(options, buildPackageRoot)
+	}
+
+	cache := newBuildCache(buildPackageRoot)
+	key, err := buildCacheKey(options)
+	if err != nil {
+		return "", fmt.Errorf("calculating build cache key failed: %w", err)
+	}
+	if target, found := cache.lookup(key, options); found {
+		logger.Infof("Package not changed since last build, skipping build (path: %s)", target)
+		return target, nil
+	}
+	err = cache.invalidate()
+	if err != nil {
+		return "", err
+	}
+
+	target, err := buildPackage(options, buildPackageRoot)
+	if err != nil {
+		return "", err
+	}
+
+	// Calculate the key again, as building the package can update files in the source, such as readmes.
+	key, err = buildCacheKey(options)
+	if err != nil {
+		return "", fmt.Errorf("calculating build cache key failed: %w", err)
+	}
+	err = cache.store(key, target, options)
+	if err != nil {
+		return "", err
+	}
+	return target, nil
+}
+
+func buildPackage(options BuildOptions, buildPackageRoot string) (string, error) {
 	logger.Debugf("Clear target directory (path: %s)", buildPackageRoot)
-	err = files.ClearDir(buildPackageRoot)
+	err := files.ClearDir(buildPackageRoot)
 	if err != nil {
 		return "", fmt.Errorf("clearing package contents failed: %w", err)
 	}
@@ -267,8 +306,13 @@ func buildZippedPackage(options BuildOptions, buildPackageRoot string) (string,
 		return "", fmt.Errorf("can't evaluate path for the zipped package: %w", err)
 	}
 
-	logger.Debugf("Compress using archives.Zip (destination: %s)", zippedPackagePath)
-	err = files.Zip(buildPackageRoot, zippedPackagePath)
+	modTime, err := zipModTime(options.PackageRoot)
+	if err != nil {
+		return "", fmt.Errorf("can't determine the modification time of the files in the zipped package: %w", err)
+	}
+
+	logger.Debugf("Compress using archives.Zip (destination: %s, modification time: %s)", zippedPackagePath, modTime)
+	err = files.Zip(buildPackageRoot, zippedPackagePath, modTime)
 	if err != nil {
 		return "", fmt.Errorf("can't compress the built package (compressed file path: %s): %w", zippedPackagePath, err)
 	}
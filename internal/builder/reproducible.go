@@ -0,0 +1,164 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package builder
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/magefile/mage/sh"
+
+	"github.com/elastic/elastic-package/internal/logger"
+)
+
+const sourceDateEpochEnv = "SOURCE_DATE_EPOCH"
+
+// defaultZipModTime is the modification time of the entries of zipped packages when no other
+// time can be determined. It is the earliest time that can be represented in zip files.
+var defaultZipModTime = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// zipModTime returns the modification time used for all the entries of the zipped package.
+// It is obtained from the SOURCE_DATE_EPOCH environment variable if set, or from the date
+// of the last commit of the package otherwise.
+func zipModTime(packageRoot string) (time.Time, error) {
+	if epoch, found := os.LookupEnv(sourceDateEpochEnv); found {
+		seconds, err := strconv.ParseInt(strings.TrimSpace(epoch), 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid value in %s environment variable (%q): %w", sourceDateEpochEnv, epoch, err)
+		}
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+
+	output, err := sh.Output("git", "-C", packageRoot, "log", "-1", "--format=%ct", "--", ".")
+	if err != nil || output == "" {
+		logger.Debugf("Can't obtain the date of the last commit of the package, using %s for files in the zip", defaultZipModTime)
+		return defaultZipModTime, nil
+	}
+	seconds, err := strconv.ParseInt(output, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date of the last commit (%q): %w", output, err)
+	}
+	return time.Unix(seconds, 0).UTC(), nil
+}
+
+// BuildReproduciblePackage builds the zipped package twice, and checks that both builds produce
+// the same archive. It returns the path to the zipped package and its SHA-256 checksum.
+func BuildReproduciblePackage(options BuildOptions) (string, string, error) {
+	if !options.CreateZip {
+		return "", "", fmt.Errorf("reproducible builds can only be verified for zipped packages")
+	}
+
+	firstOptions := options
+	firstOptions.UseCache = false
+	firstOptions.SignPackage = false
+	logger.Debug("Build package for the first time")
+	target, err := BuildPackage(firstOptions)
+	if err != nil {
+		return "", "", fmt.Errorf("first build failed: %w", err)
+	}
+	first, err := os.ReadFile(target)
+	if err != nil {
+		return "", "", fmt.Errorf("reading zipped package failed: %w", err)
+	}
+
+	secondOptions := options
+	secondOptions.UseCache = false
+	logger.Debug("Build package for the second time")
+	target, err = BuildPackage(secondOptions)
+	if err != nil {
+		return "", "", fmt.Errorf("second build failed: %w", err)
+	}
+	second, err := os.ReadFile(target)
+	if err != nil {
+		return "", "", fmt.Errorf("reading zipped package failed: %w", err)
+	}
+
+	firstChecksum, secondChecksum := sha256Checksum(first), sha256Checksum(second)
+	if firstChecksum != secondChecksum {
+		differences, err := zipDifferences(first, second)
+		if err != nil {
+			return "", "", fmt.Errorf("package build is not reproducible (checksums: %s, %s), and archives couldn't be compared: %w", firstChecksum, secondChecksum, err)
+		}
+		return "", "", fmt.Errorf("package build is not reproducible (checksums: %s, %s), different entries: %s", firstChecksum, secondChecksum, strings.Join(differences, ", "))
+	}
+	return target, secondChecksum, nil
+}
+
+// PackageChecksum returns the SHA-256 checksum of the file.
+func PackageChecksum(path string) (string, error) {
+	d, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return sha256Checksum(d), nil
+}
+
+func sha256Checksum(d []byte) string {
+	sum := sha256.Sum256(d)
+	return hex.EncodeToString(sum[:])
+}
+
+// zipDifferences returns the names of the entries that are different in both archives.
+func zipDifferences(first, second []byte) ([]string, error) {
+	firstEntries, err := zipEntries(first)
+	if err != nil {
+		return nil, err
+	}
+	secondEntries, err := zipEntries(second)
+	if err != nil {
+		return nil, err
+	}
+
+	var differences []string
+	for name, entry := range firstEntries {
+		if other, found := secondEntries[name]; !found || entry != other {
+			differences = append(differences, name)
+		}
+	}
+	for name := range secondEntries {
+		if _, found := firstEntries[name]; !found {
+			differences = append(differences, name)
+		}
+	}
+	if len(differences) == 0 {
+		// Same entries, but something else is different, as their order.
+		differences = append(differences, "(order or metadata of the archive)")
+	}
+	slices.Sort(differences)
+	return differences, nil
+}
+
+// zipEntries returns a summary of the headers and the content of each entry in the archive.
+func zipEntries(d []byte) (map[string]string, error) {
+	reader, err := zip.NewReader(bytes.NewReader(d), int64(len(d)))
+	if err != nil {
+		return nil, fmt.Errorf("can't open zip archive: %w", err)
+	}
+	entries := make(map[string]string)
+	for _, f := range reader.File {
+		r, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("can't open %s: %w", f.Name, err)
+		}
+		h := sha256.New()
+		_, err = io.Copy(h, r)
+		r.Close()
+		if err != nil {
+			return nil, fmt.Errorf("can't read %s: %w", f.Name, err)
+		}
+		entries[filepath.ToSlash(f.Name)] = fmt.Sprintf("%x %s %s", h.Sum(nil), f.Mode(), f.Modified.UTC())
+	}
+	return entries, nil
+}
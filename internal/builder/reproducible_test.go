@@ -0,0 +1,61 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package builder
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestZipModTime(t *testing.T) {
+	t.Run("source date epoch", func(t *testing.T) {
+		t.Setenv(sourceDateEpochEnv, "1700000000")
+		modTime, err := zipModTime(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, time.Unix(1700000000, 0).UTC(), modTime)
+	})
+
+	t.Run("invalid source date epoch", func(t *testing.T) {
+		t.Setenv(sourceDateEpochEnv, "yesterday")
+		_, err := zipModTime(t.TempDir())
+		assert.Error(t, err)
+	})
+
+	t.Run("outside of git repository", func(t *testing.T) {
+		modTime, err := zipModTime(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, defaultZipModTime, modTime)
+	})
+}
+
+func TestZipDifferences(t *testing.T) {
+	createZip := func(t *testing.T, files map[string]string, order []string) []byte {
+		var buf bytes.Buffer
+		w := zip.NewWriter(&buf)
+		for _, name := range order {
+			f, err := w.CreateHeader(&zip.FileHeader{Name: name, Modified: defaultZipModTime})
+			require.NoError(t, err)
+			_, err = f.Write([]byte(files[name]))
+			require.NoError(t, err)
+		}
+		require.NoError(t, w.Close())
+		return buf.Bytes()
+	}
+
+	first := createZip(t, map[string]string{"a": "a", "b": "b", "c": "c"}, []string{"a", "b", "c"})
+
+	differences, err := zipDifferences(first, createZip(t, map[string]string{"a": "a", "b": "x", "d": "d"}, []string{"a", "b", "d"}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b", "c", "d"}, differences)
+
+	differences, err = zipDifferences(first, createZip(t, map[string]string{"a": "a", "b": "b", "c": "c"}, []string{"c", "b", "a"}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"(order or metadata of the archive)"}, differences)
+}
@@ -0,0 +1,373 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package builder
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/elastic/elastic-package/internal/files"
+	"github.com/elastic/elastic-package/internal/packages"
+	"github.com/elastic/elastic-package/internal/packages/buildmanifest"
+	"github.com/elastic/elastic-package/internal/version"
+)
+
+const (
+	cycloneDXFormat      = "CycloneDX"
+	cycloneDXSpecVersion = "1.5"
+	sbomFileSuffix       = ".cdx.json"
+
+	sbomPropertySourcePath    = "elastic-package:source_path"
+	sbomPropertySourcePackage = "elastic-package:source_package"
+	sbomPropertyPackageType   = "elastic-package:package_type"
+)
+
+// mlModelDirs are the directories of the built package that contain machine learning assets.
+var mlModelDirs = []string{
+	"elasticsearch/ml_model",
+	"kibana/ml_module",
+}
+
+// cycloneDXBOM is a software bill of materials in CycloneDX JSON format.
+// See https://cyclonedx.org/docs/1.5/json/ for the details of the format.
+type cycloneDXBOM struct {
+	BOMFormat    string                `json:"bomFormat"`
+	SpecVersion  string                `json:"specVersion"`
+	Version      int                   `json:"version"`
+	Metadata     cycloneDXMetadata     `json:"metadata"`
+	Components   []cycloneDXComponent  `json:"components,omitempty"`
+	Dependencies []cycloneDXDependency `json:"dependencies,omitempty"`
+}
+
+type cycloneDXMetadata struct {
+	Timestamp string             `json:"timestamp"`
+	Tools     cycloneDXTools     `json:"tools"`
+	Component cycloneDXComponent `json:"component"`
+}
+
+type cycloneDXTools struct {
+	Components []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXComponent struct {
+	BOMRef             string                       `json:"bom-ref,omitempty"`
+	Type               string                       `json:"type"`
+	Group              string                       `json:"group,omitempty"`
+	Name               string                       `json:"name"`
+	Version            string                       `json:"version,omitempty"`
+	Description        string                       `json:"description,omitempty"`
+	Scope              string                       `json:"scope,omitempty"`
+	Hashes             []cycloneDXHash              `json:"hashes,omitempty"`
+	Licenses           []cycloneDXLicenseChoice     `json:"licenses,omitempty"`
+	ExternalReferences []cycloneDXExternalReference `json:"externalReferences,omitempty"`
+	Properties         []cycloneDXProperty          `json:"properties,omitempty"`
+}
+
+type cycloneDXHash struct {
+	Algorithm string `json:"alg"`
+	Content   string `json:"content"`
+}
+
+type cycloneDXLicenseChoice struct {
+	License cycloneDXLicense `json:"license"`
+}
+
+type cycloneDXLicense struct {
+	ID string `json:"id"`
+}
+
+type cycloneDXExternalReference struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+type cycloneDXProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cycloneDXDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+// SBOMPath returns the path of the software bill of materials of the zipped package.
+func SBOMPath(zippedPackagePath string) string {
+	return strings.TrimSuffix(zippedPackagePath, ".zip") + sbomFileSuffix
+}
+
+// WriteSBOM generates the software bill of materials of the built package in CycloneDX format,
+// and writes it next to the zipped package. It returns the path to the written file.
+func WriteSBOM(options BuildOptions, zippedPackagePath string) (string, error) {
+	bom, err := newSBOM(options, zippedPackagePath)
+	if err != nil {
+		return "", fmt.Errorf("generating software bill of materials failed: %w", err)
+	}
+
+	d, err := json.MarshalIndent(bom, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("encoding software bill of materials failed: %w", err)
+	}
+
+	path := SBOMPath(zippedPackagePath)
+	err = os.WriteFile(path, append(d, '\n'), 0644)
+	if err != nil {
+		return "", fmt.Errorf("writing software bill of materials failed: %w", err)
+	}
+	return path, nil
+}
+
+// newSBOM lists the package, the packages it requires, the ECS version its external fields are
+// imported from, the files linked from other locations of the repository, the machine learning
+// models bundled in the zipped package and its license.
+func newSBOM(options BuildOptions, zippedPackagePath string) (*cycloneDXBOM, error) {
+	manifest, err := packages.ReadPackageManifestFromPackageRoot(options.PackageRoot)
+	if err != nil {
+		return nil, fmt.Errorf("reading package manifest failed (path: %s): %w", options.PackageRoot, err)
+	}
+
+	// Use the same time as in the zipped package, so the same sources produce the same document.
+	timestamp, err := zipModTime(options.PackageRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	packageComponent := cycloneDXComponent{
+		BOMRef:      sbomRef("package", manifest.Name, manifest.Version),
+		Type:        "application",
+		Group:       "elastic",
+		Name:        manifest.Name,
+		Version:     manifest.Version,
+		Description: manifest.Description,
+		Properties: []cycloneDXProperty{
+			{Name: sbomPropertyPackageType, Value: manifest.Type},
+		},
+	}
+	if manifest.Source.License != "" {
+		packageComponent.Licenses = append(packageComponent.Licenses, cycloneDXLicenseChoice{
+			License: cycloneDXLicense{ID: manifest.Source.License},
+		})
+	}
+
+	var components []cycloneDXComponent
+	if manifest.Requires != nil {
+		for _, kind := range []struct {
+			name         string
+			dependencies []packages.PackageDependency
+		}{
+			{"input", manifest.Requires.Input},
+			{"content", manifest.Requires.Content},
+		} {
+			for _, dependency := range kind.dependencies {
+				components = append(components, cycloneDXComponent{
+					BOMRef:  sbomRef("package", dependency.Package, dependency.Version),
+					Type:    "application",
+					Group:   "elastic",
+					Name:    dependency.Package,
+					Version: dependency.Version,
+					Scope:   "required",
+					Properties: []cycloneDXProperty{
+						{Name: sbomPropertyPackageType, Value: kind.name},
+					},
+				})
+			}
+		}
+	}
+
+	ecsComponent, found, err := sbomECSComponent(options)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		components = append(components, ecsComponent)
+	}
+
+	linkComponents, err := sbomLinkedFileComponents(options)
+	if err != nil {
+		return nil, err
+	}
+	components = append(components, linkComponents...)
+
+	fileComponents, err := sbomBundledFileComponents(zippedPackagePath)
+	if err != nil {
+		return nil, err
+	}
+	for _, fileComponent := range fileComponents {
+		// Bundled files can also be linked files, keep a single component for them.
+		i := slices.IndexFunc(components, func(c cycloneDXComponent) bool { return c.BOMRef == fileComponent.BOMRef })
+		if i >= 0 {
+			components[i].Type = fileComponent.Type
+			continue
+		}
+		components = append(components, fileComponent)
+	}
+
+	dependency := cycloneDXDependency{Ref: packageComponent.BOMRef}
+	for _, component := range components {
+		dependency.DependsOn = append(dependency.DependsOn, component.BOMRef)
+	}
+
+	return &cycloneDXBOM{
+		BOMFormat:   cycloneDXFormat,
+		SpecVersion: cycloneDXSpecVersion,
+		Version:     1,
+		Metadata: cycloneDXMetadata{
+			Timestamp: timestamp.UTC().Format(time.RFC3339),
+			Tools: cycloneDXTools{
+				Components: []cycloneDXComponent{
+					{Type: "application", Group: "elastic", Name: "elastic-package", Version: version.Tag},
+				},
+			},
+			Component: packageComponent,
+		},
+		Components:   components,
+		Dependencies: []cycloneDXDependency{dependency},
+	}, nil
+}
+
+// sbomECSComponent returns the ECS version used to resolve external fields, if any.
+func sbomECSComponent(options BuildOptions) (cycloneDXComponent, bool, error) {
+	bm, ok, err := buildmanifest.ReadBuildManifest(options.PackageRoot)
+	if err != nil {
+		return cycloneDXComponent{}, false, fmt.Errorf("can't read build manifest: %w", err)
+	}
+	if !ok || !bm.HasDependencies() {
+		return cycloneDXComponent{}, false, nil
+	}
+
+	ecsVersion := strings.TrimPrefix(bm.Dependencies.ECS.Reference, "git@")
+	component := cycloneDXComponent{
+		BOMRef:      sbomRef("ecs", ecsVersion),
+		Type:        "data",
+		Group:       "elastic",
+		Name:        "ecs",
+		Version:     ecsVersion,
+		Description: "Elastic Common Schema, source of the external fields of the package",
+		Properties: []cycloneDXProperty{
+			{Name: sbomPropertySourcePath, Value: bm.Dependencies.ECS.Reference},
+		},
+	}
+	if base := options.SchemaURLs.ECSBase(); base != "" {
+		component.ExternalReferences = append(component.ExternalReferences, cycloneDXExternalReference{
+			Type: "distribution",
+			URL:  base,
+		})
+	}
+	return component, true, nil
+}
+
+// sbomLinkedFileComponents returns the files included in the package from other locations
+// of the repository, with their source paths and checksums.
+func sbomLinkedFileComponents(options BuildOptions) ([]cycloneDXComponent, error) {
+	linksFS, err := files.CreateLinksFSFromPath(options.RepositoryRoot, options.PackageRoot)
+	if err != nil {
+		return nil, fmt.Errorf("creating links filesystem failed: %w", err)
+	}
+	links, err := linksFS.ListLinkedFiles()
+	if err != nil {
+		return nil, fmt.Errorf("listing linked files failed: %w", err)
+	}
+
+	var components []cycloneDXComponent
+	for _, l := range links {
+		sourcePath, err := filepath.Rel(options.RepositoryRoot.Name(), filepath.Join(l.WorkDir, filepath.FromSlash(l.IncludedFilePath)))
+		if err != nil {
+			return nil, fmt.Errorf("can't find path of linked file relative to the repository: %w", err)
+		}
+		targetPath := filepath.ToSlash(l.TargetRelPath)
+		component := cycloneDXComponent{
+			BOMRef: sbomRef("file", targetPath),
+			Type:   "file",
+			Name:   targetPath,
+			Hashes: []cycloneDXHash{{Algorithm: "SHA-256", Content: l.IncludedFileContentsChecksum}},
+			Properties: []cycloneDXProperty{
+				{Name: sbomPropertySourcePath, Value: filepath.ToSlash(sourcePath)},
+			},
+		}
+		if l.IncludedPackageName != "" {
+			component.Properties = append(component.Properties, cycloneDXProperty{
+				Name:  sbomPropertySourcePackage,
+				Value: l.IncludedPackageName,
+			})
+		}
+		components = append(components, component)
+	}
+	slices.SortFunc(components, func(a, b cycloneDXComponent) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return components, nil
+}
+
+// sbomBundledFileComponents returns the machine learning models and the license file bundled
+// in the zipped package. They are read from the archive, as the build directory may not match
+// it when the package has not been built again.
+func sbomBundledFileComponents(zippedPackagePath string) ([]cycloneDXComponent, error) {
+	reader, err := zip.OpenReader(zippedPackagePath)
+	if err != nil {
+		return nil, fmt.Errorf("can't open zipped package: %w", err)
+	}
+	defer reader.Close()
+
+	var components []cycloneDXComponent
+	for _, f := range reader.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		// Entries are placed in a root folder named after the package and its version.
+		_, name, found := strings.Cut(f.Name, "/")
+		if !found {
+			continue
+		}
+
+		var componentType string
+		switch {
+		case name == licenseTextFileName:
+			componentType = "file"
+		case slices.ContainsFunc(mlModelDirs, func(dir string) bool { return strings.HasPrefix(name, dir+"/") }):
+			componentType = "machine-learning-model"
+		default:
+			continue
+		}
+
+		checksum, err := zipEntryChecksum(f)
+		if err != nil {
+			return nil, err
+		}
+		components = append(components, cycloneDXComponent{
+			BOMRef: sbomRef("file", name),
+			Type:   componentType,
+			Name:   name,
+			Hashes: []cycloneDXHash{{Algorithm: "SHA-256", Content: checksum}},
+		})
+	}
+	return components, nil
+}
+
+func zipEntryChecksum(f *zip.File) (string, error) {
+	r, err := f.Open()
+	if err != nil {
+		return "", fmt.Errorf("can't open %s: %w", f.Name, err)
+	}
+	defer r.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", fmt.Errorf("can't read %s: %w", f.Name, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func sbomRef(kind string, parts ...string) string {
+	return kind + ":" + strings.Join(parts, "@")
+}
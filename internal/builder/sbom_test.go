@@ -0,0 +1,102 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package builder
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-package/internal/fields"
+	"github.com/elastic/elastic-package/internal/files"
+)
+
+func TestNewSBOM(t *testing.T) {
+	repositoryRoot, err := os.OpenRoot(t.TempDir())
+	require.NoError(t, err)
+	defer repositoryRoot.Close()
+
+	writeFile := func(t *testing.T, path, content string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	packageRoot := filepath.Join(repositoryRoot.Name(), "packages", "foo")
+	writeFile(t, filepath.Join(packageRoot, "manifest.yml"), `format_version: 3.0.0
+name: foo
+version: 1.2.0
+type: integration
+source:
+  license: Elastic-2.0
+requires:
+  input:
+    - package: sql
+      version: ^1.0.0
+`)
+	writeFile(t, filepath.Join(packageRoot, "_dev", "build", "build.yml"), "dependencies:\n  ecs:\n    reference: git@v8.11.0\n")
+	writeFile(t, filepath.Join(repositoryRoot.Name(), "shared", "agent.yml"), "- name: agent.id\n")
+	writeFile(t, filepath.Join(packageRoot, "data_stream", "bar", "fields", "agent.yml.link"), "../../../../../shared/agent.yml\n")
+
+	buildPackageRoot := filepath.Join(t.TempDir(), "foo", "1.2.0")
+	writeFile(t, filepath.Join(buildPackageRoot, "manifest.yml"), "name: foo\n")
+	writeFile(t, filepath.Join(buildPackageRoot, "elasticsearch", "ml_model", "model.yml"), "model")
+	writeFile(t, filepath.Join(buildPackageRoot, licenseTextFileName), "license")
+	zippedPackagePath := filepath.Join(t.TempDir(), "foo-1.2.0.zip")
+	require.NoError(t, files.Zip(buildPackageRoot, zippedPackagePath, defaultZipModTime))
+
+	// The build directory is not used, it may not match the zipped package after a cached build.
+	require.NoError(t, os.RemoveAll(buildPackageRoot))
+
+	options := BuildOptions{
+		PackageRoot:    packageRoot,
+		RepositoryRoot: repositoryRoot,
+		SchemaURLs:     fields.NewSchemaURLs(fields.WithECSBaseURL("https://example.com/ecs")),
+	}
+	bom, err := newSBOM(options, zippedPackagePath)
+	require.NoError(t, err)
+
+	assert.Equal(t, "CycloneDX", bom.BOMFormat)
+	assert.Equal(t, "package:foo@1.2.0", bom.Metadata.Component.BOMRef)
+	assert.Equal(t, []cycloneDXLicenseChoice{{License: cycloneDXLicense{ID: "Elastic-2.0"}}}, bom.Metadata.Component.Licenses)
+	assert.Equal(t, defaultZipModTime.Format("2006-01-02T15:04:05Z"), bom.Metadata.Timestamp)
+
+	components := make(map[string]cycloneDXComponent)
+	for _, c := range bom.Components {
+		components[c.BOMRef] = c
+	}
+	require.Len(t, components, 5)
+
+	assert.Equal(t, "^1.0.0", components["package:sql@^1.0.0"].Version)
+	assert.Equal(t, "required", components["package:sql@^1.0.0"].Scope)
+
+	ecs := components["ecs:v8.11.0"]
+	assert.Equal(t, "data", ecs.Type)
+	assert.Equal(t, []cycloneDXExternalReference{{Type: "distribution", URL: "https://example.com/ecs"}}, ecs.ExternalReferences)
+
+	link := components["file:data_stream/bar/fields/agent.yml"]
+	assert.Equal(t, "file", link.Type)
+	assert.Contains(t, link.Properties, cycloneDXProperty{Name: sbomPropertySourcePath, Value: "shared/agent.yml"})
+	require.Len(t, link.Hashes, 1)
+	assert.Len(t, link.Hashes[0].Content, 64)
+
+	model := components["file:elasticsearch/ml_model/model.yml"]
+	assert.Equal(t, "machine-learning-model", model.Type)
+	assert.Equal(t, []cycloneDXHash{{Algorithm: "SHA-256", Content: sha256Checksum([]byte("model"))}}, model.Hashes)
+	assert.Equal(t, "file", components["file:LICENSE.txt"].Type)
+
+	require.Len(t, bom.Dependencies, 1)
+	assert.Len(t, bom.Dependencies[0].DependsOn, 5)
+
+	_, err = json.Marshal(bom)
+	require.NoError(t, err)
+}
+
+func TestSBOMPath(t *testing.T) {
+	assert.Equal(t, filepath.Join("build", "packages", "foo-1.2.0.cdx.json"), SBOMPath(filepath.Join("build", "packages", "foo-1.2.0.zip")))
+}
@@ -0,0 +1,340 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package builder
+
+import (
+	"archive/zip"
+	"compress/flate"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	"github.com/jedib0t/go-pretty/table"
+
+	"github.com/elastic/elastic-package/internal/packages/buildmanifest"
+)
+
+// Asset categories of the files in the built package.
+const (
+	SizeCategoryDashboards      = "dashboards"
+	SizeCategoryDocs            = "docs"
+	SizeCategoryElasticsearch   = "elasticsearch"
+	SizeCategoryFields          = "fields"
+	SizeCategoryImages          = "images"
+	SizeCategoryIngestPipelines = "ingest_pipelines"
+	SizeCategoryKibana          = "kibana"
+	SizeCategoryMachineLearning = "ml_models"
+	SizeCategoryOther           = "other"
+)
+
+// Formats of the size report.
+const (
+	SizeReportFormatHuman = "human"
+	SizeReportFormatJSON  = "json"
+)
+
+const (
+	sizeReportTotalName          = "total"
+	sizeReportDataStreamsDirName = "data_stream"
+)
+
+// SizeCategories is the list of asset categories of the size report.
+var SizeCategories = []string{
+	SizeCategoryDashboards,
+	SizeCategoryDocs,
+	SizeCategoryElasticsearch,
+	SizeCategoryFields,
+	SizeCategoryImages,
+	SizeCategoryIngestPipelines,
+	SizeCategoryKibana,
+	SizeCategoryMachineLearning,
+	SizeCategoryOther,
+}
+
+// SizeReportFormats is the list of supported formats of the size report.
+var SizeReportFormats = []string{SizeReportFormatHuman, SizeReportFormatJSON}
+
+var imageExtensions = []string{".png", ".jpg", ".jpeg", ".gif", ".svg", ".webp", ".ico"}
+
+// SizeEntry contains the sizes of a group of files of the built package.
+type SizeEntry struct {
+	Files        int    `json:"files"`
+	Uncompressed uint64 `json:"uncompressed"`
+	Compressed   uint64 `json:"compressed"`
+}
+
+func (e *SizeEntry) add(uncompressed, compressed uint64) {
+	e.Files++
+	e.Uncompressed += uncompressed
+	e.Compressed += compressed
+}
+
+// SizeReport contains the breakdown of the sizes of a built package by directory and by asset category.
+type SizeReport struct {
+	Package     string                `json:"package"`
+	Version     string                `json:"version"`
+	Total       SizeEntry             `json:"total"`
+	Categories  map[string]*SizeEntry `json:"categories"`
+	Directories map[string]*SizeEntry `json:"directories"`
+}
+
+// NewSizeReport calculates the size report of the built package. The target can be a zipped
+// package, whose entries sizes are used, or a package directory, in which case compressed sizes
+// are calculated by compressing the files in the same way as they are compressed in zip files.
+func NewSizeReport(target, name, version string) (*SizeReport, error) {
+	report := SizeReport{
+		Package:     name,
+		Version:     version,
+		Categories:  make(map[string]*SizeEntry),
+		Directories: make(map[string]*SizeEntry),
+	}
+
+	info, err := os.Stat(target)
+	if err != nil {
+		return nil, fmt.Errorf("can't stat built package: %w", err)
+	}
+	if info.IsDir() {
+		err = report.addDirectory(target)
+	} else {
+		err = report.addZip(target)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("calculating size of built package failed (path: %s): %w", target, err)
+	}
+	return &report, nil
+}
+
+func (r *SizeReport) addDirectory(root string) error {
+	return filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		uncompressed, compressed, err := compressedFileSize(p)
+		if err != nil {
+			return err
+		}
+		r.add(filepath.ToSlash(rel), uncompressed, compressed)
+		return nil
+	})
+}
+
+func (r *SizeReport) addZip(zipPath string) error {
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	for _, f := range reader.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		// Entries of zipped packages are placed under a <name>-<version> directory.
+		_, rel, found := strings.Cut(filepath.ToSlash(f.Name), "/")
+		if !found {
+			rel = f.Name
+		}
+		r.add(rel, f.UncompressedSize64, f.CompressedSize64)
+	}
+	return nil
+}
+
+func (r *SizeReport) add(rel string, uncompressed, compressed uint64) {
+	r.Total.add(uncompressed, compressed)
+	for _, group := range []struct {
+		entries map[string]*SizeEntry
+		name    string
+	}{
+		{r.Categories, sizeCategory(rel)},
+		{r.Directories, sizeDirectory(rel)},
+	} {
+		entry, found := group.entries[group.name]
+		if !found {
+			entry = &SizeEntry{}
+			group.entries[group.name] = entry
+		}
+		entry.add(uncompressed, compressed)
+	}
+}
+
+// compressedFileSize returns the size of the file, and its size once compressed with the
+// default compression level, as used for zip files.
+func compressedFileSize(p string) (uint64, uint64, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	var counter byteCounter
+	w, err := flate.NewWriter(&counter, flate.DefaultCompression)
+	if err != nil {
+		return 0, 0, err
+	}
+	uncompressed, err := io.Copy(w, f)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := w.Close(); err != nil {
+		return 0, 0, err
+	}
+	return uint64(uncompressed), counter.n, nil
+}
+
+type byteCounter struct {
+	n uint64
+}
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	c.n += uint64(len(p))
+	return len(p), nil
+}
+
+// sizeCategory returns the asset category of a file, given its path relative to the package root.
+func sizeCategory(rel string) string {
+	segments := strings.Split(rel, "/")
+	switch {
+	case slices.Contains(imageExtensions, strings.ToLower(path.Ext(rel))):
+		return SizeCategoryImages
+	case slices.Contains(segments, "ml_module") || slices.Contains(segments, "ml_model") ||
+		slices.Contains(segments, "trained_model"):
+		return SizeCategoryMachineLearning
+	case slices.Contains(segments, "fields"):
+		return SizeCategoryFields
+	case segments[0] == "docs":
+		return SizeCategoryDocs
+	case slices.Contains(segments, "ingest_pipeline"):
+		return SizeCategoryIngestPipelines
+	case slices.Contains(segments, "kibana") && slices.Contains(segments, "dashboard"):
+		return SizeCategoryDashboards
+	case slices.Contains(segments, "kibana"):
+		return SizeCategoryKibana
+	case slices.Contains(segments, "elasticsearch"):
+		return SizeCategoryElasticsearch
+	default:
+		return SizeCategoryOther
+	}
+}
+
+// sizeDirectory returns the top-level directory of a file, given its path relative to the
+// package root. Data streams are reported separately, and files in the root of the package
+// are grouped under ".".
+func sizeDirectory(rel string) string {
+	segments := strings.Split(rel, "/")
+	switch {
+	case len(segments) == 1:
+		return "."
+	case segments[0] == sizeReportDataStreamsDirName && len(segments) > 2:
+		return path.Join(segments[0], segments[1])
+	default:
+		return segments[0]
+	}
+}
+
+// Format returns the report in the given format.
+func (r *SizeReport) Format(format string) (string, error) {
+	switch format {
+	case SizeReportFormatHuman:
+		return r.human(), nil
+	case SizeReportFormatJSON:
+		d, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("encoding size report failed: %w", err)
+		}
+		return string(d), nil
+	default:
+		return "", fmt.Errorf("unsupported size report format %q, supported formats: %s", format, strings.Join(SizeReportFormats, ", "))
+	}
+}
+
+func (r *SizeReport) human() string {
+	var report strings.Builder
+	fmt.Fprintf(&report, "Size of package %s %s: %s (uncompressed: %s, files: %d)\n",
+		r.Package, r.Version, humanize.Bytes(r.Total.Compressed), humanize.Bytes(r.Total.Uncompressed), r.Total.Files)
+	report.WriteString(sizeTable("Category", r.Categories))
+	report.WriteString("\n")
+	report.WriteString(sizeTable("Directory", r.Directories))
+	return report.String()
+}
+
+// sizeTable renders the entries sorted by compressed size, from bigger to smaller.
+func sizeTable(title string, entries map[string]*SizeEntry) string {
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	slices.SortFunc(names, func(a, b string) int {
+		if entries[a].Compressed != entries[b].Compressed {
+			if entries[a].Compressed > entries[b].Compressed {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a, b)
+	})
+
+	t := table.NewWriter()
+	t.AppendHeader(table.Row{title, "Files", "Compressed", "Uncompressed"})
+	for _, name := range names {
+		e := entries[name]
+		t.AppendRow(table.Row{name, e.Files, humanize.Bytes(e.Compressed), humanize.Bytes(e.Uncompressed)})
+	}
+	t.SetStyle(table.StyleRounded)
+	return t.Render()
+}
+
+// CheckBudget returns an error listing the categories whose compressed size exceeds the budget.
+func (r *SizeReport) CheckBudget(budget buildmanifest.SizeBudget) error {
+	var errs []error
+	check := func(name string, limit string, size uint64) {
+		if limit == "" {
+			return
+		}
+		maxSize, err := humanize.ParseBytes(limit)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid size budget for %s (%q): %w", name, limit, err))
+			return
+		}
+		if size > maxSize {
+			errs = append(errs, fmt.Errorf("%s size %s exceeds the budget of %s", name, humanize.Bytes(size), humanize.Bytes(maxSize)))
+		}
+	}
+
+	check(sizeReportTotalName, budget.Total, r.Total.Compressed)
+
+	categories := make([]string, 0, len(budget.Categories))
+	for category := range budget.Categories {
+		categories = append(categories, category)
+	}
+	slices.Sort(categories)
+	for _, category := range categories {
+		if !slices.Contains(SizeCategories, category) {
+			errs = append(errs, fmt.Errorf("unknown category %q in size budget, valid categories: %s", category, strings.Join(SizeCategories, ", ")))
+			continue
+		}
+		var size uint64
+		if entry, found := r.Categories[category]; found {
+			size = entry.Compressed
+		}
+		check(category, budget.Categories[category], size)
+	}
+
+	return errors.Join(errs...)
+}
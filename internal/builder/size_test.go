@@ -0,0 +1,156 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package builder
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-package/internal/files"
+	"github.com/elastic/elastic-package/internal/packages/buildmanifest"
+)
+
+func TestSizeCategory(t *testing.T) {
+	cases := map[string]string{
+		"manifest.yml":                                    SizeCategoryOther,
+		"img/screenshot.PNG":                              SizeCategoryImages,
+		"docs/README.md":                                  SizeCategoryDocs,
+		"kibana/dashboard/foo.json":                       SizeCategoryDashboards,
+		"kibana/visualization/foo.json":                   SizeCategoryKibana,
+		"kibana/ml_module/foo.json":                       SizeCategoryMachineLearning,
+		"elasticsearch/ml_model/foo.json":                 SizeCategoryMachineLearning,
+		"elasticsearch/transform/foo/transform.yml":       SizeCategoryElasticsearch,
+		"elasticsearch/transform/foo/fields/fields.yml":   SizeCategoryFields,
+		"data_stream/foo/fields/base-fields.yml":          SizeCategoryFields,
+		"data_stream/foo/elasticsearch/ingest_pipeline/a": SizeCategoryIngestPipelines,
+		"data_stream/foo/agent/stream/stream.yml.hbs":     SizeCategoryOther,
+		"data_stream/foo/sample_event.json":               SizeCategoryOther,
+	}
+	for rel, expected := range cases {
+		assert.Equal(t, expected, sizeCategory(rel), rel)
+	}
+}
+
+func TestSizeDirectory(t *testing.T) {
+	assert.Equal(t, ".", sizeDirectory("manifest.yml"))
+	assert.Equal(t, "docs", sizeDirectory("docs/README.md"))
+	assert.Equal(t, "data_stream/foo", sizeDirectory("data_stream/foo/manifest.yml"))
+	assert.Equal(t, "data_stream", sizeDirectory("data_stream/README.md"))
+}
+
+func TestNewSizeReport(t *testing.T) {
+	packageRoot := filepath.Join(t.TempDir(), "foo-1.0.0")
+	for rel, content := range map[string]string{
+		"manifest.yml":                           "name: foo\n",
+		"docs/README.md":                         strings.Repeat("README ", 1000),
+		"img/icon.svg":                           "<svg/>",
+		"data_stream/bar/fields/base-fields.yml": "- name: '@timestamp'\n",
+	} {
+		path := filepath.Join(packageRoot, filepath.FromSlash(rel))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	zipPath := filepath.Join(t.TempDir(), "foo-1.0.0.zip")
+	require.NoError(t, files.Zip(packageRoot, zipPath, defaultZipModTime))
+
+	directoryReport, err := NewSizeReport(packageRoot, "foo", "1.0.0")
+	require.NoError(t, err)
+	zipReport, err := NewSizeReport(zipPath, "foo", "1.0.0")
+	require.NoError(t, err)
+
+	for _, report := range []*SizeReport{directoryReport, zipReport} {
+		assert.Equal(t, 4, report.Total.Files)
+		assert.Equal(t, uint64(7000), report.Categories[SizeCategoryDocs].Uncompressed)
+		assert.Less(t, report.Categories[SizeCategoryDocs].Compressed, uint64(7000))
+		assert.Equal(t, 1, report.Categories[SizeCategoryImages].Files)
+		assert.Equal(t, 1, report.Directories["data_stream/bar"].Files)
+		assert.Equal(t, 1, report.Directories["."].Files)
+	}
+	assert.Equal(t, directoryReport.Total.Uncompressed, zipReport.Total.Uncompressed)
+
+	human, err := zipReport.Format(SizeReportFormatHuman)
+	require.NoError(t, err)
+	assert.Contains(t, human, "Size of package foo 1.0.0")
+	assert.Contains(t, human, "data_stream/bar")
+
+	formatted, err := zipReport.Format(SizeReportFormatJSON)
+	require.NoError(t, err)
+	var decoded SizeReport
+	require.NoError(t, json.Unmarshal([]byte(formatted), &decoded))
+	assert.Equal(t, *zipReport, decoded)
+
+	_, err = zipReport.Format("xml")
+	assert.Error(t, err)
+}
+
+func TestSizeReportCheckBudget(t *testing.T) {
+	report := SizeReport{
+		Total: SizeEntry{Files: 3, Compressed: 3000},
+		Categories: map[string]*SizeEntry{
+			SizeCategoryImages: {Files: 1, Compressed: 2000},
+			SizeCategoryDocs:   {Files: 2, Compressed: 1000},
+		},
+	}
+
+	cases := []struct {
+		title    string
+		budget   buildmanifest.SizeBudget
+		expected []string
+	}{
+		{
+			title: "empty budget",
+		},
+		{
+			title: "within budget",
+			budget: buildmanifest.SizeBudget{
+				Total:      "3kB",
+				Categories: map[string]string{SizeCategoryImages: "2kB", SizeCategoryDashboards: "1kB"},
+			},
+		},
+		{
+			title: "exceeded budgets",
+			budget: buildmanifest.SizeBudget{
+				Total:      "2kB",
+				Categories: map[string]string{SizeCategoryImages: "1kB", SizeCategoryDocs: "1kB"},
+			},
+			expected: []string{
+				"total size 3.0 kB exceeds the budget of 2.0 kB",
+				"images size 2.0 kB exceeds the budget of 1.0 kB",
+			},
+		},
+		{
+			title: "invalid budget",
+			budget: buildmanifest.SizeBudget{
+				Total:      "a lot",
+				Categories: map[string]string{"videos": "1kB"},
+			},
+			expected: []string{
+				`invalid size budget for total ("a lot")`,
+				`unknown category "videos" in size budget`,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			err := report.CheckBudget(c.budget)
+			if len(c.expected) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Len(t, strings.Split(err.Error(), "\n"), len(c.expected))
+			for _, expected := range c.expected {
+				assert.Contains(t, err.Error(), expected)
+			}
+		})
+	}
+}
@@ -88,9 +88,24 @@ const (
 	BenchStreamTimestampFieldFlagName        = "timestamp-field"
 	BenchStreamTimestampFieldFlagDescription = "name of the field that's used in the generator config as `@timestamp`"
 
+	BuildNoCacheFlagName        = "no-cache"
+	BuildNoCacheFlagDescription = "build the package even if it has not changed since the last build"
+
+	BuildSBOMFlagName        = "sbom"
+	BuildSBOMFlagDescription = "generate a software bill of materials in CycloneDX format next to the zipped package"
+
+	BuildSizeReportFlagName        = "size-report"
+	BuildSizeReportFlagDescription = "report the size of the built package by directory and asset category (\"%s\")"
+
+	BuildSizeReportOutputFlagName        = "size-report-output"
+	BuildSizeReportOutputFlagDescription = "path of the file to write the size report to, instead of printing it"
+
 	BuildSkipValidationFlagName        = "skip-validation"
 	BuildSkipValidationFlagDescription = "skip validation of the built package, use only if all validation issues have been acknowledged"
 
+	BuildVerifyReproducibleFlagName        = "verify-reproducible"
+	BuildVerifyReproducibleFlagDescription = "build the zipped package twice and verify that both builds are identical"
+
 	BuildZipFlagName        = "zip"
 	BuildZipFlagDescription = "archive the built package"
 
@@ -124,6 +139,10 @@ const (
 	DataStreamFlagName        = "data-stream"
 	DataStreamFlagDescription = "use service stack related to the data stream"
 
+	DataStreamFieldsFlagDescription = "data stream to create the fields for"
+
+	DataStreamExportFieldsFlagDescription = "data stream to export the fields of"
+
 	DataStreamsFlagName        = "data-streams"
 	DataStreamsFlagDescription = "comma-separated data streams to test"
 
@@ -133,6 +152,12 @@ const (
 	DumpOutputFlagName        = "output"
 	DumpOutputFlagDescription = "path to directory where exported assets will be stored"
 
+	ExportFieldsFormatFlagName        = "format"
+	ExportFieldsFormatFlagDescription = "format of the exported fields (\"%s\")"
+
+	ExportFieldsOutputFlagName        = "output"
+	ExportFieldsOutputFlagDescription = "path of the file to write the exported fields to (defaults to stdout)"
+
 	ExternalStackFlagName        = "external-stack"
 	ExternalStackFlagDescription = "use external stack for script tests"
 
@@ -182,15 +207,33 @@ const (
 	FilterSpecVersionFlagName        = "spec-version"
 	FilterSpecVersionFlagDescription = "Package spec version to filter by (semver)"
 
+	FromStackFlagName        = "from-stack"
+	FromStackFlagDescription = "infer fields from the documents of the data stream in the Elastic stack"
+
 	GenerateTestResultFlagName        = "generate"
 	GenerateTestResultFlagDescription = "generate test result file"
 
+	IncludeECSFlagName        = "include-ecs"
+	IncludeECSFlagDescription = "include all the fields of the ECS version the package depends on"
+
+	IndexModesFlagName        = "index-modes"
+	IndexModesFlagDescription = "run each system test once per index mode and compare the documents retrieved in each mode (comma-separated values: %s)"
+
 	PackagesFlagName        = "packages"
 	PackagesFlagDescription = "whether to return packages names or complete paths for the linked files found"
 
 	IngestPipelineIDsFlagName        = "id"
 	IngestPipelineIDsFlagDescription = "Elasticsearch ingest pipeline IDs (comma-separated values)"
 
+	LintFormatFlagName        = "format"
+	LintFormatFlagDescription = "format of the findings, machine readable formats are available for code scanning tools and pull request annotations (\"%s\")"
+
+	OfflineFlagName        = "offline"
+	OfflineFlagDescription = "compile policies locally instead of using Fleet, the Elastic stack is not needed"
+
+	ParallelScriptTestFlagName        = "parallel"
+	ParallelScriptTestFlagDescription = "number of test scripts to run in parallel (one or lower runs them sequentially)"
+
 	ProfileFlagName        = "profile"
 	ProfileFlagDescription = "select a profile to use for the stack configuration. Can also be set with %s"
 
@@ -200,6 +243,12 @@ const (
 	ProfileFormatFlagName        = "format"
 	ProfileFormatFlagDescription = "format of the profiles list (table | json)"
 
+	RecordFlagName        = "record"
+	RecordFlagDescription = "record interactions with the Elastic stack in the given directory, so they can be replayed later"
+
+	ReplayFlagName        = "replay"
+	ReplayFlagDescription = "replay interactions with the Elastic stack recorded in the given directory, the Elastic stack is not needed"
+
 	ReportFormatFlagName        = "report-format"
 	ReportFormatFlagDescription = "format of test report, eg: human, xUnit, json"
 
@@ -212,12 +261,18 @@ const (
 	ReportOutputPathFlagName        = "report-output-path"
 	ReportOutputPathFlagDescription = "output path for test report (defaults to %q in build directory)"
 
+	ReportUnusedFieldsFlagName        = "report-unused-fields"
+	ReportUnusedFieldsFlagDescription = "report fields defined in the package that are not found in any validated document"
+
 	RunPatternFlagName        = "run"
 	RunPatternFlagDescription = "run only tests matching the regular expression"
 
 	ScriptsFlagName        = "scripts"
 	ScriptsFlagDescription = "path to directory containing test scripts"
 
+	ScriptTimeoutFlagName        = "timeout"
+	ScriptTimeoutFlagDescription = "timeout for each test script (zero indicates no timeout)"
+
 	ShowAllFlagName        = "all"
 	ShowAllFlagDescription = "show all deployed package revisions"
 
@@ -265,6 +320,12 @@ const (
 	VariantFlagName        = "variant"
 	VariantFlagDescription = "service variant"
 
+	VerifyPublicKeyFlagName        = "public-key"
+	VerifyPublicKeyFlagDescription = "path to the armored public key used to verify the signature (defaults to the key configured in the application configuration)"
+
+	VerifySignatureFlagName        = "signature"
+	VerifySignatureFlagDescription = "path to the detached signature of the package (defaults to the zip path with the .sig extension)"
+
 	ConfigFileFlagName        = "config-file"
 	ConfigFileFlagDescription = "configuration file to setup service and test"
 
@@ -44,6 +44,9 @@ type clientOptions struct {
 
 	// skipTLSVerify disables TLS validation.
 	skipTLSVerify bool
+
+	// transportSetup wraps the transport used by the client.
+	transportSetup func(http.RoundTripper) http.RoundTripper
 }
 
 type ClientOption func(*clientOptions)
@@ -90,6 +93,13 @@ func OptionWithSkipTLSVerify() ClientOption {
 	}
 }
 
+// OptionWithTransportSetup adds a function that can wrap the transport used by the client.
+func OptionWithTransportSetup(setup func(http.RoundTripper) http.RoundTripper) ClientOption {
+	return func(opts *clientOptions) {
+		opts.transportSetup = setup
+	}
+}
+
 // Client is a wrapper over an Elasticsearch Client.
 type Client struct {
 	*elasticsearch.Client
@@ -135,6 +145,14 @@ func NewConfig(customOptions ...ClientOption) (elasticsearch.Config, error) {
 		}
 	}
 
+	if options.transportSetup != nil {
+		transport := config.Transport
+		if transport == nil {
+			transport = http.DefaultTransport
+		}
+		config.Transport = options.transportSetup(transport)
+	}
+
 	return config, nil
 }
 
@@ -158,3 +158,70 @@ func countLinesInBytes(data []byte) (int, error) {
 
 	return lineCount, nil
 }
+
+// ProcessorNode is a processor in the YAML or JSON definition of an ingest pipeline, keeping the
+// position of its settings in the source.
+type ProcessorNode struct {
+	// Type is the key with the type of the processor, its line is the line of the processor.
+	Type *yaml.Node
+	// Body contains the settings of the processor.
+	Body *yaml.Node
+	// OnFailure is the list of processors of the on_failure handler of the processor, if any.
+	OnFailure *yaml.Node
+	// Handler is the on_failure list that contains the processor, nil for processors that
+	// are not in failure handlers.
+	Handler *yaml.Node
+}
+
+// ProcessorNodes returns all the processors of a pipeline, including the ones in on_failure
+// handlers and in foreach processors. Processors are returned in the order they are defined,
+// each one followed by the processor of a foreach and by its on_failure handler. Definitions
+// that are not mappings have no processors.
+func ProcessorNodes(pipeline *yaml.Node) []ProcessorNode {
+	if pipeline != nil && pipeline.Kind == yaml.DocumentNode && len(pipeline.Content) > 0 {
+		pipeline = pipeline.Content[0]
+	}
+	if pipeline == nil || pipeline.Kind != yaml.MappingNode {
+		return nil
+	}
+	var p struct {
+		Processors yaml.Node
+		OnFailure  yaml.Node `yaml:"on_failure"`
+	}
+	if err := pipeline.Decode(&p); err != nil {
+		return nil
+	}
+
+	var nodes []ProcessorNode
+	var collectList func(list, handler *yaml.Node)
+	var collect func(processor, handler *yaml.Node)
+	collect = func(processor, handler *yaml.Node) {
+		if processor.Kind != yaml.MappingNode || len(processor.Content) != 2 {
+			return
+		}
+		node := ProcessorNode{Type: processor.Content[0], Body: processor.Content[1], Handler: handler}
+		var settings struct {
+			Processor yaml.Node
+			OnFailure yaml.Node `yaml:"on_failure"`
+		}
+		if node.Body.Kind == yaml.MappingNode && node.Body.Decode(&settings) == nil && settings.OnFailure.Kind == yaml.SequenceNode {
+			node.OnFailure = &settings.OnFailure
+		}
+		nodes = append(nodes, node)
+		if node.Type.Value == "foreach" {
+			collect(&settings.Processor, handler)
+		}
+		collectList(node.OnFailure, node.OnFailure)
+	}
+	collectList = func(list, handler *yaml.Node) {
+		if list == nil || list.Kind != yaml.SequenceNode {
+			return
+		}
+		for _, processor := range list.Content {
+			collect(processor, handler)
+		}
+	}
+	collectList(&p.Processors, nil)
+	collectList(&p.OnFailure, &p.OnFailure)
+	return nodes
+}
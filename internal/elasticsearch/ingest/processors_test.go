@@ -8,6 +8,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 )
 
 func TestResource_Processors(t *testing.T) {
@@ -344,3 +346,49 @@ processors:
 		})
 	}
 }
+
+func TestProcessorNodes(t *testing.T) {
+	content := `---
+processors:
+  - foreach:
+      field: tags
+      processor:
+        lowercase:
+          field: _ingest._value
+      on_failure:
+        - set:
+            field: error.message
+            value: foreach failed
+  - set:
+      field: event.kind
+      value: event
+on_failure:
+  - append:
+      field: error.message
+      value: '{{{ _ingest.on_failure_message }}}'
+`
+	var root yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte(content), &root))
+
+	nodes := ProcessorNodes(&root)
+	require.Len(t, nodes, 5)
+
+	var types []string
+	var lines []int
+	for _, node := range nodes {
+		types = append(types, node.Type.Value)
+		lines = append(lines, node.Type.Line)
+	}
+	assert.Equal(t, []string{"foreach", "lowercase", "set", "set", "append"}, types)
+	assert.Equal(t, []int{3, 6, 9, 12, 16}, lines)
+
+	assert.Nil(t, nodes[0].Handler)
+	assert.Nil(t, nodes[1].Handler)
+	assert.Same(t, nodes[0].OnFailure, nodes[2].Handler)
+	assert.Nil(t, nodes[3].Handler)
+	assert.Nil(t, nodes[3].OnFailure)
+	assert.NotNil(t, nodes[4].Handler)
+	assert.NotSame(t, nodes[2].Handler, nodes[4].Handler)
+
+	assert.Empty(t, ProcessorNodes(&yaml.Node{Kind: yaml.ScalarNode, Value: "foo"}))
+}
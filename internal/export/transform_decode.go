@@ -28,6 +28,13 @@ var encodedFields = []string{
 	panelsAttribute,
 }
 
+// DecodeSavedObject decodes the JSON-encoded attributes of a Kibana saved object,
+// including the ones of the panels embedded in dashboards. Attributes that are
+// already decoded, as in the files stored in packages, are kept as they are.
+func DecodeSavedObject(object common.MapStr) (common.MapStr, error) {
+	return decodeObject(nil, object)
+}
+
 func decodeObject(ctx *transformationContext, object common.MapStr) (common.MapStr, error) {
 	for _, fieldToDecode := range encodedFields {
 		v, err := object.GetValue(fieldToDecode)
@@ -37,15 +44,21 @@ func decodeObject(ctx *transformationContext, object common.MapStr) (common.MapS
 			return nil, fmt.Errorf("retrieving value failed (key: %s): %w", fieldToDecode, err)
 		}
 
+		encoded, ok := v.(string)
+		if !ok {
+			// Already decoded.
+			continue
+		}
+
 		var target interface{}
 		var single map[string]interface{}
 		var array []map[string]interface{}
 
-		err = json.Unmarshal([]byte(v.(string)), &single)
+		err = json.Unmarshal([]byte(encoded), &single)
 		if err == nil {
 			target = single
 		} else {
-			err = json.Unmarshal([]byte(v.(string)), &array)
+			err = json.Unmarshal([]byte(encoded), &array)
 			if err != nil {
 				return nil, fmt.Errorf("can't unmarshal encoded field (key: %s): %w", fieldToDecode, err)
 			}
@@ -72,8 +85,20 @@ func decodeEmbeddedPanels(ctx *transformationContext, object common.MapStr) (com
 	if err != nil {
 		return nil, fmt.Errorf("retrieving embedded panels failed: %w", err)
 	}
-	embeddedPanels, ok := embeddedPanelsValue.([]map[string]any)
-	if !ok {
+	var embeddedPanels []map[string]any
+	switch panels := embeddedPanelsValue.(type) {
+	case []map[string]any:
+		embeddedPanels = panels
+	case []any:
+		// Panels read from files stored in packages.
+		for _, p := range panels {
+			panel, ok := p.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("expected panel object, found %T", p)
+			}
+			embeddedPanels = append(embeddedPanels, panel)
+		}
+	default:
 		return nil, fmt.Errorf("expected list of panels, found %T", embeddedPanelsValue)
 	}
 	for i, panel := range embeddedPanels {
@@ -0,0 +1,336 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package fields
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/elastic/elastic-package/internal/files"
+	"github.com/elastic/elastic-package/internal/packages/buildmanifest"
+)
+
+// FieldConflict is a field defined with different values of the same setting in
+// different data streams, or in the package and in ECS.
+type FieldConflict struct {
+	// Name is the full name of the field.
+	Name string
+
+	// Setting is the setting with conflicting values, one of type, metric_type, unit or index.
+	Setting string
+
+	// Definitions are the values of the setting, and where they are defined.
+	Definitions []FieldConflictDefinition
+
+	// InPackage is true if the package defines the field with different values, and
+	// not only with a different value than ECS.
+	InPackage bool
+}
+
+// FieldConflictDefinition is a value of a setting of a field, and where it is defined.
+type FieldConflictDefinition struct {
+	Value    string
+	Location string
+}
+
+// String returns a description of the conflict.
+func (c FieldConflict) String() string {
+	definitions := make([]string, len(c.Definitions))
+	for i, d := range c.Definitions {
+		definitions[i] = fmt.Sprintf("%s (%s)", d.Value, d.Location)
+	}
+	return fmt.Sprintf("field %q has conflicting %s: %s", c.Name, c.Setting, strings.Join(definitions, ", "))
+}
+
+// locatedFieldDefinition is a resolved leaf field definition with the location where it is defined.
+type locatedFieldDefinition struct {
+	FieldDefinition
+
+	location string
+	external bool
+	ecs      bool
+}
+
+// conflictSettings are the settings whose values must be the same in all the definitions of a field.
+var conflictSettings = []struct {
+	name  string
+	value func(d locatedFieldDefinition) string
+
+	// optional settings are only compared when they are set.
+	optional bool
+}{
+	{name: "type", value: func(d locatedFieldDefinition) string { return conflictFieldType(d.FieldDefinition) }},
+	{name: "metric_type", value: func(d locatedFieldDefinition) string { return d.MetricType }, optional: true},
+	{name: "unit", value: func(d locatedFieldDefinition) string { return d.Unit }, optional: true},
+	{name: "index", value: func(d locatedFieldDefinition) string {
+		return strconv.FormatBool(d.Index == nil || *d.Index)
+	}},
+}
+
+// FindFieldConflicts looks for fields defined with different type, metric_type, unit or index
+// settings in the data streams of the package, or with different settings than in the ECS
+// version the package depends on. External fields are resolved before comparing them.
+func FindFieldConflicts(repositoryRoot *os.Root, packageRoot string, urls SchemaURLs) ([]FieldConflict, error) {
+	var fdm *DependencyManager
+	var ecsReference string
+	buildManifest, ok, err := buildmanifest.ReadBuildManifest(packageRoot)
+	if err != nil {
+		return nil, fmt.Errorf("can't read build manifest: %w", err)
+	}
+	if ok && buildManifest.HasDependencies() {
+		fdm, err = CreateFieldDependencyManager(buildManifest.Dependencies, urls)
+		if err != nil {
+			return nil, fmt.Errorf("can't create field dependency manager: %w", err)
+		}
+		ecsReference = buildManifest.Dependencies.ECS.Reference
+	}
+
+	fieldsDirs, err := filepath.Glob(filepath.Join(packageRoot, "data_stream", "*", "fields"))
+	if err != nil {
+		return nil, err
+	}
+	fieldsDirs = append(fieldsDirs, filepath.Join(packageRoot, "fields"))
+
+	definitions := make(map[string][]locatedFieldDefinition)
+	for _, fieldsDir := range fieldsDirs {
+		if _, err := os.Stat(fieldsDir); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		located, err := loadLocatedFieldDefinitions(repositoryRoot, packageRoot, fieldsDir, fdm)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range located {
+			definitions[d.Name] = append(definitions[d.Name], d)
+		}
+	}
+
+	var ecsSchema []FieldDefinition
+	if fdm != nil {
+		ecsSchema, err = fdm.ImportAllFields(ecsSchemaName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	names := make([]string, 0, len(definitions))
+	for name := range definitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var conflicts []FieldConflict
+	for _, name := range names {
+		fieldDefinitions := definitions[name]
+		if ecsDefinition := FindElementDefinition(name, ecsSchema); ecsDefinition != nil && hasLocalDefinition(fieldDefinitions) {
+			fieldDefinitions = append(fieldDefinitions, locatedFieldDefinition{
+				FieldDefinition: *ecsDefinition,
+				location:        "ECS " + ecsReference,
+				ecs:             true,
+			})
+		}
+		conflicts = append(conflicts, findConflicts(name, fieldDefinitions)...)
+	}
+	return conflicts, nil
+}
+
+func hasLocalDefinition(definitions []locatedFieldDefinition) bool {
+	return slices.ContainsFunc(definitions, func(d locatedFieldDefinition) bool { return !d.external })
+}
+
+func findConflicts(name string, definitions []locatedFieldDefinition) []FieldConflict {
+	var conflicts []FieldConflict
+	for _, setting := range conflictSettings {
+		conflict := FieldConflict{Name: name, Setting: setting.name}
+		var values, packageValues []string
+		for _, d := range definitions {
+			value := setting.value(d)
+			if value == "" && setting.optional {
+				continue
+			}
+			if !slices.Contains(values, value) {
+				values = append(values, value)
+			}
+			if !d.ecs && !slices.Contains(packageValues, value) {
+				packageValues = append(packageValues, value)
+			}
+			conflict.Definitions = append(conflict.Definitions, FieldConflictDefinition{
+				Value:    value,
+				Location: d.location,
+			})
+		}
+		if len(values) > 1 {
+			conflict.InPackage = len(packageValues) > 1
+			conflicts = append(conflicts, conflict)
+		}
+	}
+	return conflicts
+}
+
+// conflictFieldType returns the type used to compare field definitions. Types of
+// the keyword family are compatible between them, and leaf fields without type
+// are keywords.
+func conflictFieldType(d FieldDefinition) string {
+	switch d.Type {
+	case "constant_keyword", "wildcard":
+		return "keyword"
+	case "":
+		if len(d.Fields) == 0 {
+			return "keyword"
+		}
+		return "object"
+	case "group":
+		return "object"
+	}
+	return d.Type
+}
+
+// loadLocatedFieldDefinitions loads the leaf field definitions in the fields directory,
+// with external fields resolved, and the locations where they are defined.
+func loadLocatedFieldDefinitions(repositoryRoot *os.Root, packageRoot, fieldsDir string, fdm *DependencyManager) ([]locatedFieldDefinition, error) {
+	linksFS, err := files.CreateLinksFSFromPath(repositoryRoot, fieldsDir)
+	if err != nil {
+		return nil, fmt.Errorf("can't create links filesystem: %w", err)
+	}
+
+	paths, err := fs.Glob(linksFS, "*.yml")
+	if err != nil {
+		return nil, err
+	}
+	links, err := fs.Glob(linksFS, "*.yml.link")
+	if err != nil {
+		return nil, err
+	}
+	paths = append(paths, links...)
+
+	var result []locatedFieldDefinition
+	for _, path := range paths {
+		relPath, err := filepath.Rel(packageRoot, filepath.Join(fieldsDir, path))
+		if err != nil {
+			relPath = filepath.Join(fieldsDir, path)
+		}
+
+		body, err := fs.ReadFile(linksFS, path)
+		if err != nil {
+			return nil, fmt.Errorf("reading fields file failed: %w", err)
+		}
+
+		var root yaml.Node
+		err = yaml.Unmarshal(body, &root)
+		if err != nil {
+			return nil, fmt.Errorf("unmarshalling fields file failed (path: %s): %w", relPath, err)
+		}
+		lines := make(map[string]int)
+		externals := make(map[string]bool)
+		if len(root.Content) > 0 {
+			collectDefinitionLines(root.Content[0], "", lines, externals)
+		}
+
+		if fdm != nil {
+			body, err = injectFields(body, fdm, InjectFieldsOptions{})
+			if err != nil {
+				return nil, fmt.Errorf("loading external fields failed (path: %s): %w", relPath, err)
+			}
+		}
+		var definitions []FieldDefinition
+		err = yaml.Unmarshal(body, &definitions)
+		if err != nil {
+			return nil, fmt.Errorf("unmarshalling fields file failed (path: %s): %w", relPath, err)
+		}
+
+		flattened := flattenConflictDefinitions("", definitions)
+		defined := make(map[string]bool)
+		for _, d := range flattened {
+			defined[d.Name] = true
+		}
+		for _, d := range flattened {
+			location := relPath
+			if line, found := lines[d.Name]; found {
+				location = fmt.Sprintf("%s:%d", relPath, line)
+			}
+			result = append(result, locatedFieldDefinition{
+				FieldDefinition: d,
+				location:        location,
+				external:        externals[d.Name],
+			})
+
+			// Parents of fields defined with dotted names are objects.
+			for i := range len(d.Name) {
+				if d.Name[i] != '.' || defined[d.Name[:i]] {
+					continue
+				}
+				defined[d.Name[:i]] = true
+				result = append(result, locatedFieldDefinition{
+					FieldDefinition: FieldDefinition{Name: d.Name[:i], Type: "object"},
+					location:        location,
+				})
+			}
+		}
+	}
+	return result, nil
+}
+
+// flattenConflictDefinitions returns the definitions of the fields and their subfields, with
+// their full names.
+func flattenConflictDefinitions(prefix string, definitions []FieldDefinition) []FieldDefinition {
+	var result []FieldDefinition
+	for _, def := range definitions {
+		name := joinFieldName(prefix, def.Name)
+		if def.Type == "alias" {
+			continue
+		}
+		children := def.Fields
+		def.Name = name
+		def.Fields = nil
+		result = append(result, def)
+		if len(children) > 0 {
+			result = append(result, flattenConflictDefinitions(name, children)...)
+		}
+	}
+	return result
+}
+
+// collectDefinitionLines collects the lines where the fields in the given sequence node
+// are defined, and the fields that are external.
+func collectDefinitionLines(node *yaml.Node, prefix string, lines map[string]int, externals map[string]bool) {
+	if node.Kind != yaml.SequenceNode {
+		return
+	}
+	for _, item := range node.Content {
+		if item.Kind != yaml.MappingNode {
+			continue
+		}
+		var name string
+		var external bool
+		var fields *yaml.Node
+		for i := 0; i+1 < len(item.Content); i += 2 {
+			switch item.Content[i].Value {
+			case "name":
+				name = item.Content[i+1].Value
+			case "external":
+				external = true
+			case "fields":
+				fields = item.Content[i+1]
+			}
+		}
+		name = joinFieldName(prefix, name)
+		lines[name] = item.Line
+		if external {
+			externals[name] = true
+		}
+		if fields != nil {
+			collectDefinitionLines(fields, name, lines, externals)
+		}
+	}
+}
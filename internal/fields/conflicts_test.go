@@ -0,0 +1,134 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package fields
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindFieldConflicts(t *testing.T) {
+	ecsPath, err := filepath.Abs("testdata/ecs_nested_v8.10.0.yml")
+	require.NoError(t, err)
+
+	packageRoot := t.TempDir()
+	writeFile := func(path, content string) {
+		path = filepath.Join(packageRoot, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	writeFile("_dev/build/build.yml", "dependencies:\n  ecs:\n    reference: file://"+ecsPath+"\n")
+	writeFile("data_stream/first/fields/fields.yml", `- name: foo
+  type: group
+  fields:
+    - name: id
+      type: keyword
+    - name: count
+      type: long
+      metric_type: gauge
+    - name: size
+      type: long
+      unit: byte
+    - name: label
+      type: keyword
+      index: false
+- name: bar
+  type: keyword
+- name: host.name
+  external: ecs
+- name: source.port
+  type: keyword
+- name: untyped
+`)
+	writeFile("data_stream/second/fields/fields.yml", `- name: foo.id
+  type: long
+- name: foo.count
+  type: long
+  metric_type: counter
+- name: foo.size
+  type: long
+- name: foo.label
+  type: constant_keyword
+- name: bar.baz
+  type: keyword
+- name: host.name
+  type: keyword
+- name: untyped
+  type: keyword
+`)
+
+	repositoryRoot, err := os.OpenRoot(packageRoot)
+	require.NoError(t, err)
+	defer repositoryRoot.Close()
+
+	conflicts, err := FindFieldConflicts(repositoryRoot, packageRoot, NewSchemaURLs())
+	require.NoError(t, err)
+
+	expected := []FieldConflict{
+		{
+			Name:    "bar",
+			Setting: "type",
+			Definitions: []FieldConflictDefinition{
+				{Value: "keyword", Location: "data_stream/first/fields/fields.yml:15"},
+				{Value: "object", Location: "data_stream/second/fields/fields.yml:10"},
+			},
+			InPackage: true,
+		},
+		{
+			Name:    "foo.count",
+			Setting: "metric_type",
+			Definitions: []FieldConflictDefinition{
+				{Value: "gauge", Location: "data_stream/first/fields/fields.yml:6"},
+				{Value: "counter", Location: "data_stream/second/fields/fields.yml:3"},
+			},
+			InPackage: true,
+		},
+		{
+			Name:    "foo.id",
+			Setting: "type",
+			Definitions: []FieldConflictDefinition{
+				{Value: "keyword", Location: "data_stream/first/fields/fields.yml:4"},
+				{Value: "long", Location: "data_stream/second/fields/fields.yml:1"},
+			},
+			InPackage: true,
+		},
+		{
+			Name:    "foo.label",
+			Setting: "index",
+			Definitions: []FieldConflictDefinition{
+				{Value: "false", Location: "data_stream/first/fields/fields.yml:12"},
+				{Value: "true", Location: "data_stream/second/fields/fields.yml:8"},
+			},
+			InPackage: true,
+		},
+		{
+			Name:    "source.port",
+			Setting: "type",
+			Definitions: []FieldConflictDefinition{
+				{Value: "keyword", Location: "data_stream/first/fields/fields.yml:19"},
+				{Value: "long", Location: "ECS file://" + ecsPath},
+			},
+		},
+	}
+	assert.Equal(t, expected, conflicts)
+}
+
+func TestFieldConflictString(t *testing.T) {
+	conflict := FieldConflict{
+		Name:    "foo",
+		Setting: "type",
+		Definitions: []FieldConflictDefinition{
+			{Value: "keyword", Location: "data_stream/first/fields/fields.yml:1"},
+			{Value: "long", Location: "data_stream/second/fields/fields.yml:4"},
+		},
+	}
+	assert.Equal(t,
+		`field "foo" has conflicting type: keyword (data_stream/first/fields/fields.yml:1), long (data_stream/second/fields/fields.yml:4)`,
+		conflict.String())
+}
@@ -0,0 +1,325 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package fields
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultDateFormat is the format used by Elasticsearch for date fields without date_format.
+const defaultDateFormat = "strict_date_optional_time||epoch_millis"
+
+const (
+	isoZone     = `(?:Z|[+-]\d{2}(?::?\d{2})?)`
+	isoFraction = `(?:[.,]\d{1,9})`
+)
+
+// dateFormats are the regular expressions of the built-in date formats of Elasticsearch.
+// Named groups are used to check the ranges of the components of the date.
+var dateFormats = map[string]string{
+	"strict_date_optional_time": `(?P<year>[+-]?\d{4})(?:-(?P<month>\d{2})(?:-(?P<day>\d{2})(?:T(?P<hour>\d{2})(?::(?P<minute>\d{2})(?::(?P<second>\d{2})` + isoFraction + `?)?)?)?` + isoZone + `?)?)?`,
+	"date_optional_time":        `(?P<year>[+-]?\d{1,9})(?:-(?P<month>\d{1,2})(?:-(?P<day>\d{1,2})(?:T(?P<hour>\d{1,2})(?::(?P<minute>\d{1,2})(?::(?P<second>\d{1,2})` + isoFraction + `?)?)?)?` + isoZone + `?)?)?`,
+
+	"strict_date_time":           `(?P<year>\d{4})-(?P<month>\d{2})-(?P<day>\d{2})T(?P<hour>\d{2}):(?P<minute>\d{2}):(?P<second>\d{2})` + isoFraction + isoZone,
+	"date_time":                  `(?P<year>\d{1,9})-(?P<month>\d{1,2})-(?P<day>\d{1,2})T(?P<hour>\d{1,2}):(?P<minute>\d{1,2}):(?P<second>\d{1,2})` + isoFraction + isoZone,
+	"strict_date_time_no_millis": `(?P<year>\d{4})-(?P<month>\d{2})-(?P<day>\d{2})T(?P<hour>\d{2}):(?P<minute>\d{2}):(?P<second>\d{2})` + isoZone,
+	"date_time_no_millis":        `(?P<year>\d{1,9})-(?P<month>\d{1,2})-(?P<day>\d{1,2})T(?P<hour>\d{1,2}):(?P<minute>\d{1,2}):(?P<second>\d{1,2})` + isoZone,
+
+	"strict_date_hour_minute_second":        `(?P<year>\d{4})-(?P<month>\d{2})-(?P<day>\d{2})T(?P<hour>\d{2}):(?P<minute>\d{2}):(?P<second>\d{2})`,
+	"date_hour_minute_second":               `(?P<year>\d{1,9})-(?P<month>\d{1,2})-(?P<day>\d{1,2})T(?P<hour>\d{1,2}):(?P<minute>\d{1,2}):(?P<second>\d{1,2})`,
+	"strict_date_hour_minute_second_millis": `(?P<year>\d{4})-(?P<month>\d{2})-(?P<day>\d{2})T(?P<hour>\d{2}):(?P<minute>\d{2}):(?P<second>\d{2})\.\d{1,3}`,
+	"date_hour_minute_second_millis":        `(?P<year>\d{1,9})-(?P<month>\d{1,2})-(?P<day>\d{1,2})T(?P<hour>\d{1,2}):(?P<minute>\d{1,2}):(?P<second>\d{1,2})\.\d{1,3}`,
+
+	"strict_date":               `(?P<year>\d{4})-(?P<month>\d{2})-(?P<day>\d{2})`,
+	"date":                      `(?P<year>\d{1,9})-(?P<month>\d{1,2})-(?P<day>\d{1,2})`,
+	"strict_year_month_day":     `(?P<year>\d{4})-(?P<month>\d{2})-(?P<day>\d{2})`,
+	"year_month_day":            `(?P<year>\d{1,9})-(?P<month>\d{1,2})-(?P<day>\d{1,2})`,
+	"strict_year_month":         `(?P<year>\d{4})-(?P<month>\d{2})`,
+	"year_month":                `(?P<year>\d{1,9})-(?P<month>\d{1,2})`,
+	"strict_year":               `(?P<year>\d{4})`,
+	"year":                      `(?P<year>\d{1,9})`,
+	"basic_date":                `(?P<year>\d{4})(?P<month>\d{2})(?P<day>\d{2})`,
+	"basic_date_time":           `(?P<year>\d{4})(?P<month>\d{2})(?P<day>\d{2})T(?P<hour>\d{2})(?P<minute>\d{2})(?P<second>\d{2})\.\d{3}` + isoZone,
+	"basic_date_time_no_millis": `(?P<year>\d{4})(?P<month>\d{2})(?P<day>\d{2})T(?P<hour>\d{2})(?P<minute>\d{2})(?P<second>\d{2})` + isoZone,
+
+	"epoch_millis": `-?\d+(?:\.\d+)?`,
+	"epoch_second": `-?\d+(?:\.\d+)?`,
+}
+
+// dateFormatAliases are built-in formats that are parsed as other formats.
+var dateFormatAliases = map[string]string{
+	"strict_date_optional_time_nanos": "strict_date_optional_time",
+	"iso8601":                         "date_optional_time",
+}
+
+var (
+	dateFormatRegexpsMutex sync.Mutex
+	dateFormatRegexps      = make(map[string]*regexp.Regexp)
+)
+
+// dateFormatRegexp returns the regular expression for the given date format, built-in or
+// custom. It returns nil if the format is not supported, and values cannot be validated.
+func dateFormatRegexp(format string) *regexp.Regexp {
+	dateFormatRegexpsMutex.Lock()
+	defer dateFormatRegexpsMutex.Unlock()
+
+	if re, found := dateFormatRegexps[format]; found {
+		return re
+	}
+
+	name := format
+	if alias, found := dateFormatAliases[name]; found {
+		name = alias
+	}
+	expr, found := dateFormats[name]
+	if !found {
+		expr, found = javaDateFormatExpression(format)
+	}
+	var re *regexp.Regexp
+	if found {
+		re = regexp.MustCompile("^" + expr + "$")
+	}
+	dateFormatRegexps[format] = re
+	return re
+}
+
+// isEpochDateFormat returns true if the format accepts numeric values.
+func isEpochDateFormat(format string) bool {
+	return format == "epoch_millis" || format == "epoch_second"
+}
+
+// ensureDateFormatMatches validates that the value of a date field can be parsed with any of
+// the formats in the date format of the field.
+func ensureDateFormatMatches(key string, val any, dateFormat string) error {
+	if dateFormat == "" {
+		dateFormat = defaultDateFormat
+	}
+	formats := strings.Split(dateFormat, "||")
+	for i := range formats {
+		formats[i] = strings.TrimSpace(formats[i])
+	}
+
+	value, isString := val.(string)
+	if !isString {
+		// Numbers are accepted by epoch formats, or if their string representation
+		// can be parsed with other formats.
+		for _, format := range formats {
+			if isEpochDateFormat(format) {
+				return nil
+			}
+		}
+		value = numberString(val)
+	}
+
+	var rangeErr error
+	for _, format := range formats {
+		re := dateFormatRegexp(format)
+		if re == nil {
+			// Unsupported format, value cannot be validated.
+			return nil
+		}
+		err := ensureDateComponentsInRange(re, value)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, errDateFormatNotMatching) {
+			rangeErr = err
+		}
+	}
+	switch {
+	case rangeErr != nil:
+		return fmt.Errorf("field %q's value %q is not a valid date: %w", key, value, rangeErr)
+	case !isString:
+		return fmt.Errorf("field %q's value %v is numeric, but the date format %q doesn't accept numbers", key, val, dateFormat)
+	default:
+		return fmt.Errorf("field %q's value %q does not match the date format %q", key, value, dateFormat)
+	}
+}
+
+// numberString returns the string representation of a numeric value, without exponents.
+func numberString(val any) string {
+	if f, ok := val.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprintf("%v", val)
+}
+
+var errDateFormatNotMatching = errors.New("value doesn't match the date format")
+
+// ensureDateComponentsInRange checks that the value matches the regular expression of the
+// format, and that the captured components of the date are in their valid ranges.
+func ensureDateComponentsInRange(re *regexp.Regexp, value string) error {
+	match := re.FindStringSubmatch(value)
+	if match == nil {
+		return errDateFormatNotMatching
+	}
+
+	components := make(map[string]int)
+	for i, name := range re.SubexpNames() {
+		if name == "" || match[i] == "" {
+			continue
+		}
+		n, err := strconv.Atoi(match[i])
+		if err != nil {
+			continue
+		}
+		components[name] = n
+	}
+
+	ranges := []struct {
+		name     string
+		min, max int
+	}{
+		{"month", 1, 12},
+		{"hour", 0, 23},
+		{"minute", 0, 59},
+		{"second", 0, 59},
+	}
+	for _, r := range ranges {
+		if n, found := components[r.name]; found && (n < r.min || n > r.max) {
+			return fmt.Errorf("%s %d out of range (%d-%d)", r.name, n, r.min, r.max)
+		}
+	}
+	if day, found := components["day"]; found {
+		maxDay := 31
+		if month, found := components["month"]; found {
+			year, found := components["year"]
+			if !found {
+				// Leap year, so 29th of February is accepted.
+				year = 2000
+			}
+			maxDay = time.Date(year, time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC).Day()
+		}
+		if day < 1 || day > maxDay {
+			return fmt.Errorf("day %d out of range (1-%d)", day, maxDay)
+		}
+	}
+	return nil
+}
+
+// javaDateFormatExpression converts a custom date format, defined with the syntax of Java
+// DateTimeFormatter patterns, into a regular expression. It returns false if the pattern
+// contains elements that are not supported.
+func javaDateFormatExpression(pattern string) (string, bool) {
+	var expr strings.Builder
+	optionalSections := 0
+	for i := 0; i < len(pattern); {
+		c := pattern[i]
+		switch {
+		case c == '\'':
+			// Quoted literal, two quotes are a literal quote.
+			end := strings.IndexByte(pattern[i+1:], '\'')
+			if end < 0 {
+				return "", false
+			}
+			literal := pattern[i+1 : i+1+end]
+			if literal == "" {
+				literal = "'"
+			}
+			expr.WriteString(regexp.QuoteMeta(literal))
+			i += end + 2
+			continue
+		case c == '[':
+			expr.WriteString("(?:")
+			optionalSections++
+		case c == ']':
+			if optionalSections == 0 {
+				return "", false
+			}
+			expr.WriteString(")?")
+			optionalSections--
+		case (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+			count := 1
+			for i+count < len(pattern) && pattern[i+count] == c {
+				count++
+			}
+			letterExpr, ok := javaDateFormatLetterExpression(c, count)
+			if !ok {
+				return "", false
+			}
+			expr.WriteString(letterExpr)
+			i += count
+			continue
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+		i++
+	}
+	if optionalSections > 0 {
+		return "", false
+	}
+	return expr.String(), true
+}
+
+func javaDateFormatLetterExpression(letter byte, count int) (string, bool) {
+	digits := func(name string) string {
+		n := `\d{1,2}`
+		if count > 1 {
+			n = fmt.Sprintf(`\d{%d}`, count)
+		}
+		if name == "" {
+			return n
+		}
+		return fmt.Sprintf(`(?P<%s>%s)`, name, n)
+	}
+	text := func(short int) string {
+		if count < 4 {
+			return fmt.Sprintf(`[A-Za-z]{%d}`, short)
+		}
+		return `[A-Za-z]+`
+	}
+
+	switch letter {
+	case 'y', 'u':
+		if count == 2 {
+			return `\d{2}`, true
+		}
+		return `(?P<year>[+-]?\d{4,9})`, true
+	case 'M', 'L':
+		if count >= 3 {
+			return text(3), true
+		}
+		return digits("month"), true
+	case 'd':
+		return digits("day"), true
+	case 'D':
+		return `\d{1,3}`, true
+	case 'H':
+		return digits("hour"), true
+	case 'k', 'h', 'K':
+		return digits(""), true
+	case 'm':
+		return digits("minute"), true
+	case 's':
+		return digits("second"), true
+	case 'S', 'n':
+		return fmt.Sprintf(`\d{%d}`, count), true
+	case 'a':
+		return `(?i:am|pm)`, true
+	case 'E', 'e':
+		if count <= 2 && letter == 'e' {
+			return `\d`, true
+		}
+		return text(3), true
+	case 'X':
+		if count == 1 {
+			return `(?:Z|[+-]\d{2}(?:\d{2})?)`, true
+		}
+		return isoZone, true
+	case 'x':
+		return `[+-]\d{2}(?::?\d{2})?`, true
+	case 'Z':
+		return `(?:Z|[+-]\d{2}:?\d{2})`, true
+	case 'z', 'V':
+		return `[A-Za-z][A-Za-z0-9_/+-]*`, true
+	}
+	return "", false
+}
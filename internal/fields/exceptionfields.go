@@ -29,7 +29,7 @@ func (v *Validator) listExceptionFieldsMapElement(root string, elem common.MapSt
 				all = append(all, fields...)
 			}
 		case map[string]any:
-			if isFieldTypeFlattened(key, v.Schema) {
+			if isFieldTypeFlattened(key, v.index()) {
 				// Do not traverse into objects with flattened data types
 				// because the entire object is mapped as a single field.
 				continue
@@ -37,7 +37,7 @@ func (v *Validator) listExceptionFieldsMapElement(root string, elem common.MapSt
 			fields := v.listExceptionFieldsMapElement(key, val)
 			all = append(all, fields...)
 		default:
-			if skipLeafOfObject(root, name, v.specVersion, v.Schema) {
+			if skipLeafOfObject(root, name, v.specVersion, v.index()) {
 				logger.Tracef("Skip validating leaf of object (spec %q): %q", v.specVersion, key)
 				all = append(all, key)
 				// Till some versions we skip some validations on leaf of objects, check if it is the case.
@@ -58,7 +58,7 @@ func (v *Validator) listExceptionFieldsScalarElement(key string, val any) []stri
 		return nil // root key is always valid
 	}
 
-	definition := FindElementDefinition(key, v.Schema)
+	definition := v.index().find(key)
 	if definition == nil {
 		return nil
 	}
@@ -0,0 +1,151 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package fields
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	geoPointWKTRegexp     = regexp.MustCompile(`(?i)^\s*POINT\s*\(\s*(\S+)\s+(\S+)(?:\s+\S+)?\s*\)\s*$`)
+	geoPointGeohashRegexp = regexp.MustCompile(`^[0-9b-hjkmnp-z]{1,12}$`)
+)
+
+// ensureValidGeoPoint validates a geo_point value in any of the encodings accepted by
+// Elasticsearch: an object with lat and lon, a GeoJSON point, a "lat,lon" string, a WKT
+// point, a geohash, or an array with longitude and latitude. The key is used to validate
+// values of the lat and lon subfields when the object has been traversed.
+func ensureValidGeoPoint(key string, val any) error {
+	err := validateGeoPoint(key, val)
+	if err != nil {
+		return fmt.Errorf("field %q's value %v is not a valid geo_point: %w", key, val, err)
+	}
+	return nil
+}
+
+func validateGeoPoint(key string, val any) error {
+	switch val := val.(type) {
+	case map[string]any:
+		if _, found := val["coordinates"]; found {
+			return validateGeoJSONPoint(val)
+		}
+		lat, found := val["lat"]
+		if !found {
+			return errors.New("missing lat")
+		}
+		lon, found := val["lon"]
+		if !found {
+			return errors.New("missing lon")
+		}
+		for k := range val {
+			if k != "lat" && k != "lon" {
+				return fmt.Errorf("unexpected key %q", k)
+			}
+		}
+		return validateLatLon(lat, lon)
+	case []any:
+		return validateGeoPointCoordinates(val)
+	case string:
+		return validateGeoPointString(val)
+	case float64, json.Number:
+		// Value of a subfield of an object already traversed.
+		switch {
+		case strings.HasSuffix(key, ".lat"):
+			return validateCoordinate("latitude", val, 90)
+		case strings.HasSuffix(key, ".lon"):
+			return validateCoordinate("longitude", val, 180)
+		}
+		return errors.New("numbers are only accepted in arrays of coordinates")
+	default:
+		return fmt.Errorf("unexpected type %T", val)
+	}
+}
+
+func validateGeoJSONPoint(point map[string]any) error {
+	if pointType, _ := point["type"].(string); !strings.EqualFold(pointType, "point") {
+		return fmt.Errorf("unexpected GeoJSON type %q, expected Point", point["type"])
+	}
+	coordinates, ok := point["coordinates"].([]any)
+	if !ok {
+		return errors.New("GeoJSON coordinates must be an array")
+	}
+	return validateGeoPointCoordinates(coordinates)
+}
+
+// validateGeoPointCoordinates validates an array with longitude, latitude and an optional altitude.
+func validateGeoPointCoordinates(coordinates []any) error {
+	if len(coordinates) < 2 || len(coordinates) > 3 {
+		return fmt.Errorf("expected longitude and latitude, and an optional altitude, found %d coordinates", len(coordinates))
+	}
+	return validateLatLon(coordinates[1], coordinates[0])
+}
+
+func validateGeoPointString(value string) error {
+	if match := geoPointWKTRegexp.FindStringSubmatch(value); match != nil {
+		return validateLatLon(match[2], match[1])
+	}
+	if lat, lon, found := strings.Cut(value, ","); found {
+		return validateLatLon(strings.TrimSpace(lat), strings.TrimSpace(lon))
+	}
+	if geoPointGeohashRegexp.MatchString(value) {
+		return nil
+	}
+	return errors.New(`expected "lat,lon", WKT point or geohash`)
+}
+
+func validateLatLon(lat, lon any) error {
+	if err := validateCoordinate("latitude", lat, 90); err != nil {
+		return err
+	}
+	return validateCoordinate("longitude", lon, 180)
+}
+
+// validateCoordinate checks that a coordinate is a number, or a string with a number, in the
+// range between -limit and limit.
+func validateCoordinate(name string, val any, limit float64) error {
+	var n float64
+	switch val := val.(type) {
+	case float64:
+		n = val
+	case json.Number:
+		f, err := val.Float64()
+		if err != nil {
+			return fmt.Errorf("invalid %s %q", name, val)
+		}
+		n = f
+	case string:
+		f, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q", name, val)
+		}
+		n = f
+	default:
+		return fmt.Errorf("invalid %s type %T", name, val)
+	}
+	if n < -limit || n > limit {
+		return fmt.Errorf("%s %v out of range (%v to %v)", name, n, -limit, limit)
+	}
+	return nil
+}
+
+// isGeoPointCoordinates returns true if the array contains the coordinates of a single point.
+func isGeoPointCoordinates(arr []any) bool {
+	if len(arr) < 2 || len(arr) > 3 {
+		return false
+	}
+	for _, e := range arr {
+		switch e.(type) {
+		case float64, json.Number:
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,333 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package fields
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/netip"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/elastic/elastic-package/internal/common"
+	"github.com/elastic/elastic-package/internal/packages/buildmanifest"
+)
+
+// inferredDateLayouts are the layouts of the string values that are inferred as dates.
+var inferredDateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+}
+
+// dynamicKeyRegexp matches keys that don't look like field names, objects with these
+// keys are inferred as flattened fields.
+var dynamicKeyRegexp = regexp.MustCompile(`[^a-zA-Z0-9_@.-]`)
+
+// FieldsInference infers the definitions of the fields found in documents.
+type FieldsInference struct {
+	known *schemaIndex
+
+	types   map[string]string
+	skipped map[string]struct{}
+}
+
+// NewFieldsInference creates a new fields inference. Fields already defined in
+// the known definitions are not inferred.
+func NewFieldsInference(known []FieldDefinition) *FieldsInference {
+	return &FieldsInference{
+		known:   newSchemaIndex(known),
+		types:   make(map[string]string),
+		skipped: make(map[string]struct{}),
+	}
+}
+
+// KnownFieldDefinitions returns the definitions of the fields defined in the given fields
+// directory, and the ones that can be imported from the external schemas the package depends on.
+func KnownFieldDefinitions(repositoryRoot *os.Root, packageRoot string, fieldsDir string, urls SchemaURLs) ([]FieldDefinition, error) {
+	validator, err := CreateValidator(repositoryRoot, packageRoot, fieldsDir,
+		WithSchemaURLs(urls),
+		WithEnabledImportAllECSSChema(false),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("can't load fields definitions: %w", err)
+	}
+	known := validator.Schema
+
+	buildManifest, ok, err := buildmanifest.ReadBuildManifest(packageRoot)
+	if err != nil {
+		return nil, fmt.Errorf("can't read build manifest: %w", err)
+	}
+	if !ok || !buildManifest.HasDependencies() {
+		return known, nil
+	}
+
+	fdm, err := CreateFieldDependencyManager(buildManifest.Dependencies, urls)
+	if err != nil {
+		return nil, fmt.Errorf("can't create field dependency manager: %w", err)
+	}
+	schema, err := fdm.ImportAllFields(ecsSchemaName)
+	if err != nil {
+		return nil, err
+	}
+	return append(known, schema...), nil
+}
+
+// AddDocument infers the types of the fields in the document.
+func (fi *FieldsInference) AddDocument(doc common.MapStr) {
+	fi.addObject("", doc)
+}
+
+// Skipped returns the fields found in documents that are already defined.
+func (fi *FieldsInference) Skipped() []string {
+	skipped := make([]string, 0, len(fi.skipped))
+	for name := range fi.skipped {
+		skipped = append(skipped, name)
+	}
+	sort.Strings(skipped)
+	return skipped
+}
+
+// Definitions returns the definitions of the inferred fields, nested in groups.
+func (fi *FieldsInference) Definitions() []FieldDefinition {
+	names := make([]string, 0, len(fi.types))
+	for name := range fi.types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parents := make(map[string]bool)
+	for _, name := range names {
+		for i := range len(name) {
+			if name[i] == '.' {
+				parents[name[:i]] = true
+			}
+		}
+	}
+
+	var definitions []FieldDefinition
+	for _, name := range names {
+		if fi.hasLeafAncestor(name) {
+			// Included in the ancestor, that is stored as flattened.
+			continue
+		}
+		fieldType := fi.types[name]
+		if parents[name] {
+			// Fields that are also parents of other fields are stored as flattened.
+			fieldType = "flattened"
+		}
+		definitions = addNestedDefinition(definitions, strings.Split(name, "."), fieldType)
+	}
+	return definitions
+}
+
+func (fi *FieldsInference) hasLeafAncestor(name string) bool {
+	for i := range len(name) {
+		if name[i] != '.' {
+			continue
+		}
+		if _, found := fi.types[name[:i]]; found {
+			return true
+		}
+	}
+	return false
+}
+
+func addNestedDefinition(definitions []FieldDefinition, path []string, fieldType string) []FieldDefinition {
+	if len(path) == 1 {
+		return append(definitions, FieldDefinition{Name: path[0], Type: fieldType})
+	}
+
+	i := slices.IndexFunc(definitions, func(d FieldDefinition) bool {
+		return d.Name == path[0] && d.Type == "group"
+	})
+	if i < 0 {
+		definitions = append(definitions, FieldDefinition{Name: path[0], Type: "group"})
+		i = len(definitions) - 1
+	}
+	definitions[i].Fields = addNestedDefinition(definitions[i].Fields, path[1:], fieldType)
+	return definitions
+}
+
+func (fi *FieldsInference) addObject(root string, obj map[string]any) {
+	for key, value := range obj {
+		name := key
+		if root != "" {
+			name = root + "." + key
+		}
+		fi.addValue(name, value)
+	}
+}
+
+func (fi *FieldsInference) addValue(name string, value any) {
+	if obj, ok := value.(common.MapStr); ok {
+		value = map[string]any(obj)
+	}
+
+	switch value := value.(type) {
+	case nil:
+		return
+	case []any:
+		for _, elem := range value {
+			fi.addValue(name, elem)
+		}
+		return
+	case map[string]any:
+		if len(value) == 0 {
+			return
+		}
+		if fi.isKnown(name) {
+			if definition := fi.known.find(name); definition != nil && isGroupDefinition(definition) {
+				// Known groups can contain unknown fields.
+				fi.addObject(name, value)
+			} else {
+				fi.skipped[name] = struct{}{}
+			}
+			return
+		}
+		if isGeoPointObject(value) {
+			fi.addType(name, "geo_point")
+			return
+		}
+		for key := range value {
+			if dynamicKeyRegexp.MatchString(key) {
+				fi.addType(name, "flattened")
+				return
+			}
+		}
+		fi.addObject(name, value)
+	default:
+		if fi.isKnown(name) {
+			fi.skipped[name] = struct{}{}
+			return
+		}
+		fi.addType(name, inferValueType(value))
+	}
+}
+
+func isGroupDefinition(definition *FieldDefinition) bool {
+	switch definition.Type {
+	case "", "group", "nested":
+		return true
+	}
+	return false
+}
+
+func (fi *FieldsInference) addType(name, fieldType string) {
+	current, found := fi.types[name]
+	if !found {
+		fi.types[name] = fieldType
+		return
+	}
+	fi.types[name] = mergeInferredTypes(current, fieldType)
+}
+
+func (fi *FieldsInference) isKnown(name string) bool {
+	if skipValidationForField(name) {
+		return true
+	}
+	if fi.known.find(name) != nil {
+		return true
+	}
+	return isFlattenedSubfield(name, fi.known) || !isParentEnabled(name, fi.known)
+}
+
+func isGeoPointObject(obj map[string]any) bool {
+	if len(obj) != 2 {
+		return false
+	}
+	for _, key := range []string{"lat", "lon"} {
+		if inferValueType(obj[key]) != "long" && inferValueType(obj[key]) != "double" {
+			return false
+		}
+	}
+	return true
+}
+
+func inferValueType(value any) string {
+	switch value := value.(type) {
+	case bool:
+		return "boolean"
+	case json.Number:
+		if _, err := value.Int64(); err == nil {
+			return "long"
+		}
+		return "double"
+	case float64:
+		if value == math.Trunc(value) {
+			return "long"
+		}
+		return "double"
+	case int, int32, int64, uint, uint32, uint64:
+		return "long"
+	case string:
+		if _, err := netip.ParseAddr(value); err == nil {
+			return "ip"
+		}
+		for _, layout := range inferredDateLayouts {
+			if _, err := time.Parse(layout, value); err == nil {
+				return "date"
+			}
+		}
+		return "keyword"
+	default:
+		return "keyword"
+	}
+}
+
+// mergeInferredTypes returns a type that can hold values of both types.
+func mergeInferredTypes(a, b string) string {
+	if a == b {
+		return a
+	}
+	switch {
+	case a == "flattened" || b == "flattened":
+		return "flattened"
+	case (a == "long" && b == "double") || (a == "double" && b == "long"):
+		return "double"
+	default:
+		return "keyword"
+	}
+}
+
+// inferredFieldDefinition is the representation of inferred fields in fields files.
+type inferredFieldDefinition struct {
+	Name   string                    `yaml:"name"`
+	Type   string                    `yaml:"type"`
+	Fields []inferredFieldDefinition `yaml:"fields,omitempty"`
+}
+
+// MarshalInferredDefinitions encodes inferred field definitions as a fields file.
+func MarshalInferredDefinitions(definitions []FieldDefinition) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	err := enc.Encode(toInferredFieldDefinitions(definitions))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode fields definitions: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func toInferredFieldDefinitions(definitions []FieldDefinition) []inferredFieldDefinition {
+	result := make([]inferredFieldDefinition, len(definitions))
+	for i, d := range definitions {
+		result[i] = inferredFieldDefinition{
+			Name:   d.Name,
+			Type:   d.Type,
+			Fields: toInferredFieldDefinitions(d.Fields),
+		}
+	}
+	return result
+}
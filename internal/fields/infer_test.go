@@ -0,0 +1,118 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package fields
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-package/internal/common"
+	"github.com/elastic/elastic-package/internal/formatter"
+)
+
+func TestFieldsInference(t *testing.T) {
+	known := []FieldDefinition{
+		{Name: "@timestamp", Type: "date"},
+		{Name: "source.ip", Type: "ip"},
+		{Name: "foo", Type: "group", Fields: []FieldDefinition{
+			{Name: "known", Type: "keyword"},
+		}},
+		{Name: "labels", Type: "object"},
+	}
+
+	docs := []string{
+		`{
+			"@timestamp": "2024-01-01T00:00:00.000Z",
+			"source": {"ip": "10.0.0.1", "port": 1234},
+			"event": {"dataset": "foo.bar"},
+			"labels": {"a": "b"},
+			"foo": {
+				"known": "x",
+				"count": 1,
+				"ratio": 1,
+				"enabled": true,
+				"client_ip": "192.168.1.1",
+				"created": "2024-01-01 10:00:00",
+				"message": "hello",
+				"location": {"lat": 40.1, "lon": -3.7},
+				"annotations": {"kubernetes.io/name": "foo"},
+				"tags": ["a", "b"],
+				"items": [{"id": 1}, {"id": 2, "name": "two"}],
+				"mixed": "a",
+				"nothing": null
+			}
+		}`,
+		`{
+			"foo": {
+				"ratio": 2.5,
+				"mixed": 3,
+				"empty": {}
+			}
+		}`,
+	}
+
+	inference := NewFieldsInference(known)
+	for _, doc := range docs {
+		var m common.MapStr
+		require.NoError(t, formatter.JSONUnmarshalUsingNumber([]byte(doc), &m))
+		inference.AddDocument(m)
+	}
+
+	expected := []FieldDefinition{
+		{Name: "foo", Type: "group", Fields: []FieldDefinition{
+			{Name: "annotations", Type: "flattened"},
+			{Name: "client_ip", Type: "ip"},
+			{Name: "count", Type: "long"},
+			{Name: "created", Type: "date"},
+			{Name: "enabled", Type: "boolean"},
+			{Name: "items", Type: "group", Fields: []FieldDefinition{
+				{Name: "id", Type: "long"},
+				{Name: "name", Type: "keyword"},
+			}},
+			{Name: "location", Type: "geo_point"},
+			{Name: "message", Type: "keyword"},
+			{Name: "mixed", Type: "keyword"},
+			{Name: "ratio", Type: "double"},
+			{Name: "tags", Type: "keyword"},
+		}},
+		{Name: "source", Type: "group", Fields: []FieldDefinition{
+			{Name: "port", Type: "long"},
+		}},
+	}
+	assert.Equal(t, expected, inference.Definitions())
+	assert.Equal(t, []string{"@timestamp", "event", "foo.known", "labels", "source.ip"}, inference.Skipped())
+}
+
+func TestFieldsInferenceParentConflict(t *testing.T) {
+	inference := NewFieldsInference(nil)
+	inference.AddDocument(common.MapStr{"a": "value"})
+	inference.AddDocument(common.MapStr{"a": map[string]any{"b": "value"}, "a-b": true})
+
+	expected := []FieldDefinition{
+		{Name: "a", Type: "flattened"},
+		{Name: "a-b", Type: "boolean"},
+	}
+	assert.Equal(t, expected, inference.Definitions())
+}
+
+func TestMarshalInferredDefinitions(t *testing.T) {
+	definitions := []FieldDefinition{
+		{Name: "foo", Type: "group", Fields: []FieldDefinition{
+			{Name: "count", Type: "long"},
+		}},
+	}
+	d, err := MarshalInferredDefinitions(definitions)
+	require.NoError(t, err)
+
+	expected := `- name: foo
+  type: group
+  fields:
+    - name: count
+      type: long
+`
+	assert.Equal(t, expected, string(d))
+}
@@ -0,0 +1,328 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package fields
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// jsonSchema is a JSON Schema document, or a subschema of it.
+type jsonSchema struct {
+	Schema      string `json:"$schema,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	Type    any      `json:"type,omitempty"`
+	Pattern string   `json:"pattern,omitempty"`
+	Enum    []string `json:"enum,omitempty"`
+	Const   *string  `json:"const,omitempty"`
+
+	Items                *jsonSchema            `json:"items,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	PatternProperties    map[string]*jsonSchema `json:"patternProperties,omitempty"`
+	AdditionalProperties any                    `json:"additionalProperties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+
+	AnyOf []*jsonSchema `json:"anyOf,omitempty"`
+}
+
+// JSONSchemaFieldDefinitions returns the resolved definitions of the fields in the fields directory,
+// with external fields imported. If includeECS is true, all the fields of the ECS version the package
+// depends on are included too.
+func JSONSchemaFieldDefinitions(repositoryRoot *os.Root, packageRoot, fieldsDir string, urls SchemaURLs, includeECS bool) ([]FieldDefinition, error) {
+	if includeECS {
+		return KnownFieldDefinitions(repositoryRoot, packageRoot, fieldsDir, urls)
+	}
+
+	validator, err := CreateValidator(repositoryRoot, packageRoot, fieldsDir,
+		WithSchemaURLs(urls),
+		WithEnabledImportAllECSSChema(false),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("can't load fields definitions: %w", err)
+	}
+	return validator.Schema, nil
+}
+
+// MarshalJSONSchema converts the field definitions into a JSON Schema document that can be used
+// to validate documents with the fields as nested objects. Objects don't accept undefined
+// properties, except when they are flattened, have an object type, or belong to families of
+// fields that are not validated by the fields validator. If a field is defined more than
+// once, the first definition is used.
+func MarshalJSONSchema(title string, definitions []FieldDefinition) ([]byte, error) {
+	root := newJSONSchemaObject()
+	root.Schema = jsonSchemaDraft
+	root.Title = title
+	addJSONSchemaDefinitions(root, "", definitions)
+	openSkippedFieldFamilies(root)
+
+	d, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode JSON schema: %w", err)
+	}
+	return append(d, '\n'), nil
+}
+
+func newJSONSchemaObject() *jsonSchema {
+	return &jsonSchema{
+		Type:                 "object",
+		AdditionalProperties: false,
+	}
+}
+
+func addJSONSchemaDefinitions(root *jsonSchema, prefix string, definitions []FieldDefinition) {
+	for _, def := range definitions {
+		name := joinFieldName(prefix, def.Name)
+		if def.Type == "alias" {
+			// Aliases are not stored in documents.
+			continue
+		}
+		if def.Type == "" && len(def.Fields) == 0 {
+			// Leaf fields without type are keywords.
+			def.Type = "keyword"
+		}
+
+		parent := root
+		segments := strings.Split(name, ".")
+		for _, segment := range segments[:len(segments)-1] {
+			parent = parent.property(segment, newJSONSchemaObject)
+			if parent == nil {
+				break
+			}
+		}
+		if parent == nil {
+			// An ancestor is already defined as something that is not an object.
+			continue
+		}
+
+		last := segments[len(segments)-1]
+		switch def.Type {
+		case "", "group", "nested", "object":
+			object := parent.property(last, func() *jsonSchema { return fieldJSONSchema(def) })
+			if object != nil {
+				addJSONSchemaDefinitions(root, name, def.Fields)
+			}
+		default:
+			parent.property(last, func() *jsonSchema { return fieldJSONSchema(def) })
+		}
+	}
+}
+
+// openSkippedFieldFamilies allows undefined properties in the families of fields that are
+// not validated by the fields validator, as they are present in most documents, but not
+// defined in all packages.
+func openSkippedFieldFamilies(root *jsonSchema) {
+	if root.Properties == nil {
+		root.Properties = make(map[string]*jsonSchema)
+	}
+	for _, family := range skippedFieldFamilies {
+		property, found := root.Properties[family]
+		if !found {
+			root.Properties[family] = &jsonSchema{}
+			continue
+		}
+		property.open()
+	}
+}
+
+// open allows undefined properties in this object and its descendants.
+func (s *jsonSchema) open() {
+	if s.AdditionalProperties == false {
+		s.AdditionalProperties = nil
+	}
+	for _, property := range s.Properties {
+		property.open()
+	}
+	for _, property := range s.PatternProperties {
+		property.open()
+	}
+	if additional, ok := s.AdditionalProperties.(*jsonSchema); ok {
+		additional.open()
+	}
+	if s.Items != nil {
+		s.Items.open()
+	}
+	for _, alternative := range s.AnyOf {
+		alternative.open()
+	}
+}
+
+// property returns the object schema of the property with the given name. The property
+// is created with the given function if it doesn't exist. It returns nil if the property
+// exists, but it is not an object.
+func (s *jsonSchema) property(segment string, create func() *jsonSchema) *jsonSchema {
+	var property *jsonSchema
+	if segment == "*" {
+		// Additional properties only apply to the properties not defined explicitly,
+		// what is closer to how wildcards are resolved than a pattern matching anything.
+		additional, found := s.AdditionalProperties.(*jsonSchema)
+		if !found {
+			additional = create()
+			s.AdditionalProperties = additional
+		}
+		property = additional
+	} else {
+		properties := &s.Properties
+		if strings.Contains(segment, "*") {
+			properties = &s.PatternProperties
+			segment = wildcardJSONSchemaPattern(segment)
+		}
+		if *properties == nil {
+			*properties = make(map[string]*jsonSchema)
+		}
+
+		var found bool
+		property, found = (*properties)[segment]
+		if !found {
+			property = create()
+			(*properties)[segment] = property
+		}
+	}
+
+	// Nested fields can be arrays of objects, their properties are defined in the items.
+	for _, alternative := range property.AnyOf {
+		if alternative.Type == "array" && alternative.Items != nil && alternative.Items.Type == "object" {
+			return alternative.Items
+		}
+	}
+	// Objects accepting any property, like flattened fields, cannot have properties. Objects
+	// with wildcard properties can have other properties defined explicitly.
+	if property.Type != "object" || property.AdditionalProperties == nil {
+		return nil
+	}
+	return property
+}
+
+// wildcardJSONSchemaPattern converts a name segment with wildcards into a regular expression.
+// Wildcards match any non-empty sequence of characters.
+func wildcardJSONSchemaPattern(segment string) string {
+	parts := strings.Split(segment, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return "^" + strings.Join(parts, ".+") + "$"
+}
+
+// fieldJSONSchema returns the schema of the values of a field.
+func fieldJSONSchema(def FieldDefinition) *jsonSchema {
+	switch def.Type {
+	case "", "group":
+		schema := newJSONSchemaObject()
+		schema.Description = def.Description
+		return schema
+	case "nested":
+		schema := newJSONSchemaObject()
+		return &jsonSchema{
+			Description: def.Description,
+			AnyOf: []*jsonSchema{
+				schema,
+				{Type: "array", Items: schema},
+			},
+		}
+	case "object":
+		if def.ObjectType == "" && len(def.Fields) > 0 {
+			// Object used as group.
+			schema := newJSONSchemaObject()
+			schema.Description = def.Description
+			return schema
+		}
+		schema := &jsonSchema{
+			Description: def.Description,
+			Type:        "object",
+		}
+		if def.ObjectType != "" {
+			valueDef := def
+			valueDef.Type = def.ObjectType
+			valueDef.ObjectType = ""
+			valueDef.Description = ""
+			schema.AdditionalProperties = fieldJSONSchema(valueDef)
+		}
+		return schema
+	case "flattened":
+		return &jsonSchema{
+			Description: def.Description,
+			Type:        "object",
+		}
+	}
+
+	value := valueJSONSchema(def)
+	schema := &jsonSchema{Description: def.Description}
+	if slices.Contains(def.Normalize, "array") {
+		schema.Type = "array"
+		schema.Items = value
+		return schema
+	}
+	schema.AnyOf = []*jsonSchema{value, {Type: "array", Items: value}}
+	return schema
+}
+
+// valueJSONSchema returns the schema of a single value of a field.
+func valueJSONSchema(def FieldDefinition) *jsonSchema {
+	var schema jsonSchema
+	switch def.Type {
+	case "keyword", "text", "match_only_text", "wildcard", "version", "constant_keyword":
+		schema.Type = "string"
+		schema.Pattern = def.Pattern
+		schema.Enum = jsonSchemaEnum(def)
+		if def.Type == "constant_keyword" && def.Value != "" {
+			schema.Const = &def.Value
+		}
+	case "ip":
+		schema.Type = "string"
+		schema.Pattern = def.Pattern
+	case "date":
+		// Dates can be formatted strings, or numbers since epoch if there is no pattern.
+		schema.Type = []string{"string", "number"}
+		if def.Pattern != "" {
+			schema.Type = "string"
+			schema.Pattern = def.Pattern
+		}
+	case "long", "integer", "short", "byte", "unsigned_long":
+		schema.Type = "integer"
+	case "float", "double", "half_float", "scaled_float":
+		schema.Type = "number"
+	case "boolean":
+		schema.Type = "boolean"
+	case "geo_point":
+		schema.AnyOf = []*jsonSchema{
+			{
+				Type: "object",
+				Properties: map[string]*jsonSchema{
+					"lat": {Type: "number"},
+					"lon": {Type: "number"},
+				},
+				Required: []string{"lat", "lon"},
+			},
+			{Type: "string"},
+			{Type: "array", Items: &jsonSchema{Type: "number"}},
+		}
+	}
+	return &schema
+}
+
+// jsonSchemaEnum returns the values accepted by a field with allowed or expected values.
+func jsonSchemaEnum(def FieldDefinition) []string {
+	values := def.AllowedValues.Values()
+	if len(def.ExpectedValues) == 0 {
+		return values
+	}
+	if len(values) == 0 {
+		return def.ExpectedValues
+	}
+	var enum []string
+	for _, value := range values {
+		if slices.Contains(def.ExpectedValues, value) {
+			enum = append(enum, value)
+		}
+	}
+	return enum
+}
@@ -0,0 +1,131 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package fields
+
+import (
+	"testing"
+
+	"github.com/elastic/gojsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const jsonSchemaTestFields = `- name: data_stream
+  type: group
+  fields:
+    - name: dataset
+      type: constant_keyword
+      value: example.logs
+- name: message
+  type: match_only_text
+- name: log.level
+  type: keyword
+  allowed_values:
+    - name: info
+    - name: error
+- name: event.kind
+  type: keyword
+  expected_values: [event, alert]
+- name: example
+  type: group
+  fields:
+    - name: code
+      type: keyword
+      pattern: '^[A-Z]{3}$'
+    - name: count
+      type: long
+    - name: ratio
+      type: scaled_float
+    - name: tags
+      type: keyword
+      normalize: [array]
+    - name: started
+      type: date
+    - name: client.ip
+      type: ip
+    - name: location
+      type: geo_point
+    - name: labels
+      type: object
+      object_type: long
+    - name: raw
+      type: flattened
+    - name: items
+      type: nested
+      fields:
+        - name: id
+          type: keyword
+    - name: '*.duration'
+      type: long
+    - name: old
+      type: alias
+      path: example.code
+    - name: untyped
+`
+
+func TestMarshalJSONSchema(t *testing.T) {
+	var definitions []FieldDefinition
+	require.NoError(t, yaml.Unmarshal([]byte(jsonSchemaTestFields), &definitions))
+
+	d, err := MarshalJSONSchema("example.logs", definitions)
+	require.NoError(t, err)
+	schema, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(d))
+	require.NoError(t, err)
+
+	cases := []struct {
+		title string
+		doc   string
+		valid bool
+	}{
+		{
+			title: "valid document",
+			doc: `{
+				"data_stream": {"dataset": "example.logs"},
+				"message": "hello",
+				"log": {"level": "info"},
+				"event": {"kind": "alert"},
+				"example": {
+					"code": "ABC",
+					"count": 3,
+					"ratio": 0.5,
+					"tags": ["a", "b"],
+					"started": "2024-01-01T00:00:00Z",
+					"client": {"ip": "10.0.0.1"},
+					"location": {"lat": 1.5, "lon": 2.5},
+					"labels": {"a": 1, "b": 2},
+					"raw": {"any": {"thing": true}},
+					"items": [{"id": "1"}, {"id": "2"}],
+					"request": {"duration": 10},
+					"untyped": "value"
+				}
+			}`,
+			valid: true,
+		},
+		{title: "arrays of values", doc: `{"example": {"count": [1, 2], "started": 1700000000}}`, valid: true},
+		{title: "fields not validated", doc: `{"agent": {"id": "1"}, "event": {"module": "example"}}`, valid: true},
+		{title: "wrong constant keyword", doc: `{"data_stream": {"dataset": "other"}}`},
+		{title: "not allowed value", doc: `{"log": {"level": "debug"}}`},
+		{title: "not expected value", doc: `{"event": {"kind": "metric"}}`},
+		{title: "not matching pattern", doc: `{"example": {"code": "abc"}}`},
+		{title: "wrong type", doc: `{"example": {"count": "3"}}`},
+		{title: "not integer", doc: `{"example": {"count": 1.5}}`},
+		{title: "not an array", doc: `{"example": {"tags": "a"}}`},
+		{title: "wrong object type", doc: `{"example": {"labels": {"a": "b"}}}`},
+		{title: "wrong nested field", doc: `{"example": {"items": [{"id": 1}]}}`},
+		{title: "undefined field", doc: `{"example": {"undefined": "a"}}`},
+		{title: "alias in document", doc: `{"example": {"old": "ABC"}}`},
+		{title: "wrong wildcard field", doc: `{"example": {"request": {"duration": "10"}}}`},
+		{title: "object in field without type", doc: `{"example": {"untyped": {"a": "b"}}}`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			result, err := schema.Validate(gojsonschema.NewStringLoader(c.doc))
+			require.NoError(t, err)
+			assert.Equal(t, c.valid, result.Valid(), "%v", result.Errors())
+		})
+	}
+}
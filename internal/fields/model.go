@@ -22,8 +22,11 @@ type FieldDefinition struct {
 	AllowedValues  AllowedValues     `yaml:"allowed_values"`
 	ExpectedValues []string          `yaml:"expected_values"`
 	Pattern        string            `yaml:"pattern"`
+	DateFormat     string            `yaml:"date_format"`
+	ScalingFactor  float64           `yaml:"scaling_factor"`
 	Unit           string            `yaml:"unit"`
 	MetricType     string            `yaml:"metric_type"`
+	Dimension      bool              `yaml:"dimension"`
 	External       string            `yaml:"external"`
 	Index          *bool             `yaml:"index"`
 	Enabled        *bool             `yaml:"enabled"`
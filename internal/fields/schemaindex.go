@@ -0,0 +1,213 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package fields
+
+import (
+	"strings"
+)
+
+// schemaIndex is a compiled version of a schema that allows to find field definitions
+// without walking the whole tree of definitions on each lookup. Lookups have the same
+// semantics as FindElementDefinition.
+type schemaIndex struct {
+	// schema is the schema the index has been compiled from.
+	schema []FieldDefinition
+
+	// definitions contains the definitions in the schema, in the order they are found by
+	// a depth-first traversal.
+	definitions []FieldDefinition
+
+	// exact contains the position in definitions of the first definition of each full name
+	// without wildcards.
+	exact map[string]int
+
+	// root is the root of a trie of the segments of the full names. It is used for names
+	// with wildcards, and for subfields of complex types.
+	root *schemaIndexNode
+}
+
+type schemaIndexNode struct {
+	// first is the position of the first definition found in this node or its descendants.
+	first int
+
+	// definitions contains the positions of the definitions whose full name ends in this node.
+	definitions []int
+
+	// children are the nodes of the next segment, by their literal name.
+	children map[string]*schemaIndexNode
+
+	// patterns are the nodes of the next segment, when this segment contains wildcards.
+	patterns []schemaIndexPattern
+}
+
+type schemaIndexPattern struct {
+	segment string
+	node    *schemaIndexNode
+}
+
+func newSchemaIndex(schema []FieldDefinition) *schemaIndex {
+	idx := schemaIndex{
+		schema: schema,
+		exact:  make(map[string]int),
+		root:   &schemaIndexNode{},
+	}
+	idx.add("", schema)
+	return &idx
+}
+
+// compiledFrom returns true if the index has been compiled from the given schema.
+func (idx *schemaIndex) compiledFrom(schema []FieldDefinition) bool {
+	if len(idx.schema) != len(schema) {
+		return false
+	}
+	return len(schema) == 0 || &idx.schema[0] == &schema[0]
+}
+
+func (idx *schemaIndex) add(root string, definitions []FieldDefinition) {
+	for _, def := range definitions {
+		key := strings.TrimLeft(root+"."+def.Name, ".")
+
+		position := len(idx.definitions)
+		idx.definitions = append(idx.definitions, def)
+		if !strings.Contains(key, "*") {
+			if _, found := idx.exact[key]; !found {
+				idx.exact[key] = position
+			}
+		}
+		node := idx.root
+		for _, segment := range strings.Split(key, ".") {
+			node = node.child(segment, position)
+		}
+		node.definitions = append(node.definitions, position)
+
+		idx.add(key, def.Fields)
+		idx.add(key, def.MultiFields)
+	}
+}
+
+func (n *schemaIndexNode) child(segment string, position int) *schemaIndexNode {
+	if strings.Contains(segment, "*") {
+		for _, pattern := range n.patterns {
+			if pattern.segment == segment {
+				return pattern.node
+			}
+		}
+		node := &schemaIndexNode{first: position}
+		n.patterns = append(n.patterns, schemaIndexPattern{segment: segment, node: node})
+		return node
+	}
+
+	if n.children == nil {
+		n.children = make(map[string]*schemaIndexNode)
+	}
+	node, found := n.children[segment]
+	if !found {
+		node = &schemaIndexNode{first: position}
+		n.children[segment] = node
+	}
+	return node
+}
+
+// find looks for the definition of the given key, it returns nil if not found.
+func (idx *schemaIndex) find(searchedKey string) *FieldDefinition {
+	if position, found := idx.lookup(searchedKey); found {
+		def := idx.definitions[position]
+		return &def
+	}
+
+	// No definition found, check if the parent is an object with object type.
+	parent := idx.findParent(searchedKey)
+	if parent != nil && parent.Type == "object" && parent.ObjectType != "" {
+		fd := *parent
+		fd.Name = searchedKey
+		fd.Type = parent.ObjectType
+		fd.ObjectType = ""
+		return &fd
+	}
+
+	return nil
+}
+
+// lookup returns the position of the first definition that matches with the searched key,
+// as compareKeys would do in a depth-first traversal of the schema.
+func (idx *schemaIndex) lookup(searchedKey string) (int, bool) {
+	best := -1
+	if position, found := idx.exact[searchedKey]; found {
+		best = position
+	}
+	idx.match(idx.root, searchedKey, &best)
+	return best, best >= 0
+}
+
+// match looks in the descendants of the node for definitions matching with the key, and
+// updates best if any of them is found before the current best match.
+func (idx *schemaIndex) match(node *schemaIndexNode, key string, best *int) {
+	segment, rest, last := key, "", true
+	if i := strings.IndexByte(key, '.'); i >= 0 {
+		segment, rest, last = key[:i], key[i+1:], false
+	}
+
+	visit := func(child *schemaIndexNode) {
+		if *best >= 0 && child.first >= *best {
+			// Nothing in this branch can be found before the current match.
+			return
+		}
+		if last {
+			if len(child.definitions) > 0 && (*best < 0 || child.definitions[0] < *best) {
+				*best = child.definitions[0]
+			}
+			return
+		}
+		if !strings.Contains(rest, ".") {
+			// Workaround for potential subfields of certain types as geo_point or histogram.
+			for _, position := range child.definitions {
+				if *best >= 0 && position >= *best {
+					break
+				}
+				if validSubField(idx.definitions[position], "."+rest) {
+					*best = position
+					break
+				}
+			}
+		}
+		idx.match(child, rest, best)
+	}
+
+	if child, found := node.children[segment]; found {
+		visit(child)
+	}
+	for _, pattern := range node.patterns {
+		if compareKeys(pattern.segment, FieldDefinition{}, segment) {
+			visit(pattern.node)
+		}
+	}
+}
+
+// findParent looks for the definition of the parent of the given key.
+func (idx *schemaIndex) findParent(key string) *FieldDefinition {
+	lastDotIndex := strings.LastIndex(key, ".")
+	if lastDotIndex < 0 {
+		// Field at the root level cannot be a multifield.
+		return nil
+	}
+	return idx.find(key[:lastDotIndex])
+}
+
+// findAncestor looks for the closest ancestor of the given key that fulfills the condition.
+func (idx *schemaIndex) findAncestor(key string, cond func(string, *FieldDefinition) bool) (string, *FieldDefinition) {
+	for strings.Contains(key, ".") {
+		i := strings.LastIndex(key, ".")
+		key = key[:i]
+		ancestor := idx.find(key)
+		if ancestor == nil {
+			continue
+		}
+		if cond(key, ancestor) {
+			return key, ancestor
+		}
+	}
+
+	return "", nil
+}
@@ -0,0 +1,160 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package fields
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-package/internal/common"
+	"github.com/elastic/elastic-package/internal/packages/buildmanifest"
+)
+
+var schemaIndexTestFields = []FieldDefinition{
+	{
+		Name: "foo",
+		Type: "group",
+		Fields: []FieldDefinition{
+			{Name: "bar", Type: "keyword", MultiFields: []FieldDefinition{{Name: "text", Type: "match_only_text"}}},
+			{Name: "*.count", Type: "long"},
+			{Name: "location", Type: "geo_point"},
+			{Name: "hist", Type: "histogram"},
+		},
+	},
+	{Name: "foo.bar", Type: "long"},
+	{Name: "foo.*", Type: "keyword"},
+	{Name: "labels", Type: "object", ObjectType: "keyword"},
+	{Name: "attributes", Type: "flattened"},
+	{Name: "disabled", Type: "object", Enabled: new(bool)},
+	{Name: "imported", External: "ecs"},
+}
+
+func loadSchemaIndexTestSchema(tb testing.TB) []FieldDefinition {
+	deps := buildmanifest.Dependencies{
+		ECS: buildmanifest.ECSDependency{
+			Reference: "file://./testdata/ecs_nested_v8.10.0.yml",
+		},
+	}
+	dm, err := CreateFieldDependencyManager(deps, NewSchemaURLs())
+	require.NoError(tb, err)
+	ecs, err := dm.ImportAllFields(ecsSchemaName)
+	require.NoError(tb, err)
+
+	return append(append([]FieldDefinition{}, schemaIndexTestFields...), ecs...)
+}
+
+func TestSchemaIndexFind(t *testing.T) {
+	schema := loadSchemaIndexTestSchema(t)
+	index := newSchemaIndex(schema)
+
+	keys := []string{
+		"",
+		"foo",
+		"foo.bar",
+		"foo.bar.text",
+		"foo.other",
+		"foo.some.count",
+		"foo..count",
+		"foo.some.other",
+		"foo.location.lat",
+		"foo.location.lon",
+		"foo.location.alt",
+		"foo.hist.values",
+		"foo.hist.counts",
+		"foo.hist.lat",
+		"labels.a",
+		"labels.a.b",
+		"attributes.a.b",
+		"disabled.a",
+		"imported.lat",
+		"undefined",
+		"undefined.field",
+	}
+	for _, leaf := range leafDefinitions("", schema) {
+		keys = append(keys, leaf.Name, leaf.Name+".lat", leaf.Name+".undefined")
+	}
+
+	for _, key := range keys {
+		assert.Equal(t, FindElementDefinition(key, schema), index.find(key), "key: %q", key)
+	}
+}
+
+func TestSchemaIndexCompiledFrom(t *testing.T) {
+	v := Validator{Schema: schemaIndexTestFields}
+	assert.NotNil(t, v.index().find("foo.bar"))
+
+	v.Schema = []FieldDefinition{{Name: "other", Type: "keyword"}}
+	assert.Nil(t, v.index().find("foo.bar"))
+	assert.NotNil(t, v.index().find("other"))
+}
+
+// largeTestDocument returns a document with all the keyword fields in the schema, and its keys.
+// Fields at the root level, also defined as objects, or with restricted values, are not included.
+func largeTestDocument(tb testing.TB, schema []FieldDefinition) (common.MapStr, []string) {
+	var names []string
+	for _, leaf := range leafDefinitions("", schema) {
+		names = append(names, leaf.Name)
+	}
+	doc := common.MapStr{}
+	var keys []string
+	for _, leaf := range leafDefinitions("", schema) {
+		isObject := slices.ContainsFunc(names, func(name string) bool {
+			return strings.HasPrefix(name, leaf.Name+".")
+		})
+		restricted := leaf.Pattern != "" || len(leaf.AllowedValues) > 0 || len(leaf.ExpectedValues) > 0
+		if leaf.Type != "keyword" || isObject || restricted || !strings.Contains(leaf.Name, ".") {
+			continue
+		}
+		_, err := doc.Put(leaf.Name, "value")
+		require.NoError(tb, err)
+		keys = append(keys, leaf.Name)
+	}
+
+	// Documents are validated as they are decoded from JSON.
+	d, err := json.Marshal(doc)
+	require.NoError(tb, err)
+	var decoded common.MapStr
+	require.NoError(tb, json.Unmarshal(d, &decoded))
+	return decoded, keys
+}
+
+func BenchmarkFindElementDefinition(b *testing.B) {
+	schema := loadSchemaIndexTestSchema(b)
+	_, keys := largeTestDocument(b, schema)
+
+	b.Run("linear", func(b *testing.B) {
+		for b.Loop() {
+			for _, key := range keys {
+				FindElementDefinition(key, schema)
+			}
+		}
+	})
+
+	b.Run("indexed", func(b *testing.B) {
+		index := newSchemaIndex(schema)
+		for b.Loop() {
+			for _, key := range keys {
+				index.find(key)
+			}
+		}
+	})
+}
+
+func BenchmarkValidateDocumentMap(b *testing.B) {
+	schema := loadSchemaIndexTestSchema(b)
+	doc, _ := largeTestDocument(b, schema)
+	v := Validator{Schema: schema, disabledNormalization: true}
+
+	require.Empty(b, v.ValidateDocumentMap(doc))
+
+	for b.Loop() {
+		v.ValidateDocumentMap(doc)
+	}
+}
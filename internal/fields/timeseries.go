@@ -0,0 +1,205 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package fields
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/elastic/elastic-package/internal/common"
+	"github.com/elastic/elastic-package/internal/multierror"
+)
+
+const timestampFieldName = "@timestamp"
+
+// timeSeriesDocument contains the values of the dimension and metric fields of a document.
+type timeSeriesDocument struct {
+	position   int
+	source     common.MapStr
+	timestamp  any
+	dimensions map[string]any
+	metrics    map[string]any
+}
+
+func (d timeSeriesDocument) String() string {
+	if d.timestamp == nil {
+		return fmt.Sprintf("document #%d", d.position)
+	}
+	return fmt.Sprintf("document #%d (%s: %v)", d.position, timestampFieldName, d.timestamp)
+}
+
+// sourceJSON returns the document as JSON, to include it in the reported errors.
+func (d timeSeriesDocument) sourceJSON() string {
+	content, err := json.Marshal(d.source)
+	if err != nil {
+		return fmt.Sprintf("%v", d.source)
+	}
+	return string(content)
+}
+
+// ValidateTimeSeriesDocuments checks that the documents can be stored in a time series data stream
+// without being rejected or merged with other documents. Documents are expected to have values for
+// all the dimensions declared in the package, as they are identified by the values of their
+// dimensions and their timestamp. Documents with the same dimensions and timestamp are reported,
+// as only one of them is kept. Values of metrics are expected to be numeric. Errors include the
+// offending documents.
+func (v *Validator) ValidateTimeSeriesDocuments(docs []common.MapStr) multierror.Error {
+	var errs multierror.Error
+
+	tsDocs := make([]timeSeriesDocument, len(docs))
+	for i, doc := range docs {
+		tsDocs[i] = timeSeriesDocument{
+			position:   i,
+			source:     doc,
+			dimensions: make(map[string]any),
+			metrics:    make(map[string]any),
+		}
+		v.collectTimeSeriesValues(&tsDocs[i], "", doc)
+	}
+
+	// Dimensions declared with wildcards, or as objects, can have different keys on each document,
+	// only dimensions with fixed names are expected in all documents.
+	var declared []string
+	for _, def := range leafDefinitions("", v.packageFields) {
+		if def.Dimension && def.Type != "object" && !strings.Contains(def.Name, "*") {
+			declared = append(declared, def.Name)
+		}
+	}
+
+	var ids []string
+	groups := make(map[string][]timeSeriesDocument)
+	for _, doc := range tsDocs {
+		var missing []string
+		for _, name := range declared {
+			if _, found := doc.dimensions[name]; !found {
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) > 0 {
+			errs = append(errs, fmt.Errorf("%s is missing dimension fields %s, document: %s", doc, strings.Join(missing, ", "), doc.sourceJSON()))
+		}
+
+		for _, name := range sortedKeys(doc.metrics) {
+			if !isNumericMetricValue(doc.metrics[name]) {
+				errs = append(errs, fmt.Errorf("%s has non-numeric value %v in metric field %q, document: %s", doc, doc.metrics[name], name, doc.sourceJSON()))
+			}
+		}
+
+		if doc.timestamp == nil {
+			continue
+		}
+		id := timeSeriesID(doc)
+		if _, found := groups[id]; !found {
+			ids = append(ids, id)
+		}
+		groups[id] = append(groups[id], doc)
+	}
+
+	for _, id := range ids {
+		group := groups[id]
+		if len(group) < 2 {
+			continue
+		}
+		names := make([]string, len(group))
+		sources := make([]string, len(group))
+		for i, doc := range group {
+			names[i] = doc.String()
+			sources[i] = doc.sourceJSON()
+		}
+		errs = append(errs, fmt.Errorf("%s have the same dimensions and timestamp, only one of them is kept (%s), documents: %s",
+			strings.Join(names, ", "), id, strings.Join(sources, ", ")))
+	}
+
+	return errs
+}
+
+// collectTimeSeriesValues looks for the values of dimension and metric fields in the element.
+// Documents can have the fields as nested objects or with dotted keys.
+func (v *Validator) collectTimeSeriesValues(doc *timeSeriesDocument, root string, elem common.MapStr) {
+	for name, val := range elem {
+		key := strings.TrimLeft(root+"."+name, ".")
+		if key == timestampFieldName {
+			doc.timestamp = singleValue(val)
+			continue
+		}
+
+		def := v.index().find(key)
+		if m, ok := val.(map[string]any); ok && (def == nil || !isTimeSeriesObjectValue(*def)) {
+			v.collectTimeSeriesValues(doc, key, m)
+			continue
+		}
+		if m, ok := val.(common.MapStr); ok && (def == nil || !isTimeSeriesObjectValue(*def)) {
+			v.collectTimeSeriesValues(doc, key, m)
+			continue
+		}
+		if def == nil {
+			continue
+		}
+
+		if def.Dimension {
+			doc.dimensions[key] = val
+		}
+		if def.MetricType != "" && !isTimeSeriesObjectValue(*def) {
+			doc.metrics[key] = val
+		}
+	}
+}
+
+// isTimeSeriesObjectValue returns true for the fields whose values are objects, and must not be
+// traversed when looking for dimensions and metrics.
+func isTimeSeriesObjectValue(def FieldDefinition) bool {
+	switch def.Type {
+	case "flattened", "geo_point", "histogram", "aggregate_metric_double", "exponential_histogram":
+		return true
+	}
+	return false
+}
+
+// isNumericMetricValue returns true if the value, or all the values if it is an array, are numbers.
+func isNumericMetricValue(val any) bool {
+	switch val := val.(type) {
+	case float64, float32, int, int64, int32, uint64, json.Number:
+		return true
+	case []any:
+		for _, elem := range val {
+			if !isNumericMetricValue(elem) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// singleValue returns the first element of arrays with a single element, as the ones found in
+// documents retrieved with synthetic source.
+func singleValue(val any) any {
+	if values, ok := val.([]any); ok && len(values) == 1 {
+		return values[0]
+	}
+	return val
+}
+
+// timeSeriesID returns a string that identifies the document by the values of its dimensions
+// and its timestamp, similar to the _tsid used by Elasticsearch to route documents.
+func timeSeriesID(doc timeSeriesDocument) string {
+	var parts []string
+	for _, name := range sortedKeys(doc.dimensions) {
+		parts = append(parts, fmt.Sprintf("%s=%v", name, singleValue(doc.dimensions[name])))
+	}
+	parts = append(parts, fmt.Sprintf("%s=%v", timestampFieldName, doc.timestamp))
+	return strings.Join(parts, ", ")
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,125 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package fields
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-package/internal/common"
+)
+
+var timeSeriesTestFields = []FieldDefinition{
+	{Name: "@timestamp", Type: "date"},
+	{
+		Name: "host",
+		Type: "group",
+		Fields: []FieldDefinition{
+			{Name: "name", Type: "keyword", Dimension: true},
+		},
+	},
+	{
+		Name: "process",
+		Type: "group",
+		Fields: []FieldDefinition{
+			{Name: "pid", Type: "long", Dimension: true},
+			{Name: "cpu.pct", Type: "scaled_float", MetricType: "gauge"},
+			{Name: "io.read", Type: "long", MetricType: "counter"},
+			{Name: "latency", Type: "histogram", MetricType: "gauge"},
+		},
+	},
+	{Name: "labels", Type: "object", ObjectType: "keyword", Dimension: true},
+	{Name: "message", Type: "keyword"},
+}
+
+func decodeTimeSeriesTestDocs(t *testing.T, docs ...string) []common.MapStr {
+	var result []common.MapStr
+	for _, doc := range docs {
+		var m common.MapStr
+		require.NoError(t, json.Unmarshal([]byte(doc), &m))
+		result = append(result, m)
+	}
+	return result
+}
+
+func TestValidateTimeSeriesDocuments(t *testing.T) {
+	cases := []struct {
+		title    string
+		docs     []string
+		expected []string
+	}{
+		{
+			title: "valid documents",
+			docs: []string{
+				`{"@timestamp": "2024-01-01T00:00:00Z", "host": {"name": "a"}, "process": {"pid": 1, "cpu": {"pct": 0.5}}}`,
+				`{"@timestamp": "2024-01-01T00:00:00Z", "host": {"name": "a"}, "process": {"pid": 2, "cpu": {"pct": 0.5}}}`,
+				`{"@timestamp": "2024-01-01T00:00:10Z", "host": {"name": "a"}, "process": {"pid": 1, "io": {"read": [1, 2]}}}`,
+				`{"@timestamp": "2024-01-01T00:00:10Z", "host.name": "a", "process.pid": 2, "process.latency": {"values": [1], "counts": [2]}}`,
+			},
+		},
+		{
+			title: "documents from synthetic source",
+			docs: []string{
+				`{"@timestamp": ["2024-01-01T00:00:00Z"], "host.name": ["a"], "process.pid": [1], "process.cpu.pct": [0.5]}`,
+				`{"@timestamp": ["2024-01-01T00:00:00Z"], "host.name": ["a"], "process.pid": [2], "process.cpu.pct": [0.5]}`,
+			},
+		},
+		{
+			title: "dimensions in objects",
+			docs: []string{
+				`{"@timestamp": "2024-01-01T00:00:00Z", "host": {"name": "a"}, "process": {"pid": 1}, "labels": {"a": "1"}}`,
+				`{"@timestamp": "2024-01-01T00:00:00Z", "host": {"name": "a"}, "process": {"pid": 1}, "labels": {"b": "1"}}`,
+			},
+		},
+		{
+			title: "missing dimensions",
+			docs: []string{
+				`{"@timestamp": "2024-01-01T00:00:00Z", "host": {"name": "a"}}`,
+				`{"@timestamp": "2024-01-01T00:00:10Z", "message": "foo"}`,
+			},
+			expected: []string{
+				`document #0 (@timestamp: 2024-01-01T00:00:00Z) is missing dimension fields process.pid, document: {"@timestamp":"2024-01-01T00:00:00Z","host":{"name":"a"}}`,
+				`document #1 (@timestamp: 2024-01-01T00:00:10Z) is missing dimension fields host.name, process.pid, document: {"@timestamp":"2024-01-01T00:00:10Z","message":"foo"}`,
+			},
+		},
+		{
+			title: "colliding documents",
+			docs: []string{
+				`{"@timestamp": "2024-01-01T00:00:00Z", "host": {"name": "a"}, "process": {"pid": 1, "cpu": {"pct": 0.5}}}`,
+				`{"@timestamp": "2024-01-01T00:00:00Z", "host": {"name": "b"}, "process": {"pid": 1, "cpu": {"pct": 0.6}}}`,
+				`{"@timestamp": "2024-01-01T00:00:00Z", "host.name": "a", "process.pid": 1, "process.cpu.pct": 0.7}`,
+			},
+			expected: []string{
+				`document #0 (@timestamp: 2024-01-01T00:00:00Z), document #2 (@timestamp: 2024-01-01T00:00:00Z) have the same dimensions and timestamp, only one of them is kept (host.name=a, process.pid=1, @timestamp=2024-01-01T00:00:00Z), documents: ` +
+					`{"@timestamp":"2024-01-01T00:00:00Z","host":{"name":"a"},"process":{"cpu":{"pct":0.5},"pid":1}}, {"@timestamp":"2024-01-01T00:00:00Z","host.name":"a","process.cpu.pct":0.7,"process.pid":1}`,
+			},
+		},
+		{
+			title: "non-numeric metrics",
+			docs: []string{
+				`{"@timestamp": "2024-01-01T00:00:00Z", "host": {"name": "a"}, "process": {"pid": 1, "cpu": {"pct": "0.5"}}}`,
+			},
+			expected: []string{
+				`document #0 (@timestamp: 2024-01-01T00:00:00Z) has non-numeric value 0.5 in metric field "process.cpu.pct", document: {"@timestamp":"2024-01-01T00:00:00Z","host":{"name":"a"},"process":{"cpu":{"pct":"0.5"},"pid":1}}`,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			v := Validator{Schema: timeSeriesTestFields, packageFields: timeSeriesTestFields}
+			errs := v.ValidateTimeSeriesDocuments(decodeTimeSeriesTestDocs(t, c.docs...))
+
+			var messages []string
+			for _, err := range errs {
+				messages = append(messages, err.Error())
+			}
+			assert.Equal(t, c.expected, messages)
+		})
+	}
+}
@@ -0,0 +1,249 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package fields
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SeenFields returns the keys of the fields found in the documents validated by this validator.
+// Fields are only tracked when the validator is created WithSeenFieldsTracking.
+func (v *Validator) SeenFields() []string {
+	v.seenFieldsMutex.Lock()
+	defer v.seenFieldsMutex.Unlock()
+
+	seen := make([]string, 0, len(v.seenFields))
+	for key := range v.seenFields {
+		seen = append(seen, key)
+	}
+	sort.Strings(seen)
+	return seen
+}
+
+func (v *Validator) addSeenField(key string) {
+	if !v.trackSeenFields {
+		return
+	}
+
+	v.seenFieldsMutex.Lock()
+	defer v.seenFieldsMutex.Unlock()
+
+	if v.seenFields == nil {
+		v.seenFields = make(map[string]struct{})
+	}
+	v.seenFields[key] = struct{}{}
+}
+
+// FieldsUsage aggregates the fields seen by multiple validators, to find the fields
+// that are defined in fields directories, but never found in validated documents.
+// It is safe for concurrent use.
+type FieldsUsage struct {
+	mutex sync.Mutex
+	dirs  map[string]*fieldsDirUsage
+}
+
+type fieldsDirUsage struct {
+	definitions []FieldDefinition
+	seen        map[string]struct{}
+}
+
+// NewFieldsUsage creates a new tracker of fields usage.
+func NewFieldsUsage() *FieldsUsage {
+	return &FieldsUsage{
+		dirs: make(map[string]*fieldsDirUsage),
+	}
+}
+
+// Add aggregates the fields seen by the validator to the usage of its fields directory.
+func (u *FieldsUsage) Add(v *Validator) {
+	if v.fieldsDir == "" {
+		return
+	}
+	seen := v.SeenFields()
+
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	dir, found := u.dirs[v.fieldsDir]
+	if !found {
+		dir = &fieldsDirUsage{
+			definitions: v.packageFields,
+			seen:        make(map[string]struct{}),
+		}
+		u.dirs[v.fieldsDir] = dir
+	}
+	for _, key := range seen {
+		dir.seen[key] = struct{}{}
+	}
+}
+
+// FieldsDirs returns the fields directories with tracked usage.
+func (u *FieldsUsage) FieldsDirs() []string {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	dirs := make([]string, 0, len(u.dirs))
+	for dir := range u.dirs {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// UnseenFields returns the fields defined in the fields directory that haven't been
+// found in any validated document.
+func (u *FieldsUsage) UnseenFields(fieldsDir string) []string {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	dir, found := u.dirs[fieldsDir]
+	if !found {
+		return nil
+	}
+
+	var unseen []string
+	for _, leaf := range leafDefinitions("", dir.definitions) {
+		if !dir.isSeen(leaf.Name, leaf) {
+			unseen = append(unseen, leaf.Name)
+		}
+	}
+	sort.Strings(unseen)
+	return unseen
+}
+
+// SeenLines returns the lines of the fields file that belong to the definition of fields
+// seen in documents validated with the given fields directory. The number of lines of the
+// file is used as the end of the last definition.
+func (u *FieldsUsage) SeenLines(fieldsDir, path string, lines int) (map[int]bool, error) {
+	d, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading fields file failed: %w", err)
+	}
+	var root yaml.Node
+	err = yaml.Unmarshal(d, &root)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshalling fields file failed (path: %s): %w", path, err)
+	}
+
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	dir, found := u.dirs[fieldsDir]
+	if !found {
+		dir = &fieldsDirUsage{}
+	}
+
+	seenLines := make(map[int]bool)
+	if len(root.Content) > 0 {
+		dir.markSeenLines(seenLines, "", root.Content[0], lines)
+	}
+	return seenLines, nil
+}
+
+// markSeenLines marks the lines of the definitions in the node that contain seen fields,
+// it returns true if any field has been seen.
+func (d *fieldsDirUsage) markSeenLines(lines map[int]bool, prefix string, node *yaml.Node, lastLine int) bool {
+	if node.Kind != yaml.SequenceNode {
+		return false
+	}
+
+	anySeen := false
+	for i, item := range node.Content {
+		end := lastLine
+		if i+1 < len(node.Content) {
+			end = node.Content[i+1].Line - 1
+		}
+
+		var def FieldDefinition
+		var fields *yaml.Node
+		if item.Kind == yaml.MappingNode {
+			for j := 0; j+1 < len(item.Content); j += 2 {
+				switch item.Content[j].Value {
+				case "name":
+					def.Name = item.Content[j+1].Value
+				case "type":
+					def.Type = item.Content[j+1].Value
+				case "fields":
+					fields = item.Content[j+1]
+				}
+			}
+		}
+		name := joinFieldName(prefix, def.Name)
+
+		seen := false
+		childrenStart, childrenEnd := end+1, end
+		if fields != nil && len(fields.Content) > 0 {
+			childrenStart = fields.Content[0].Line
+			childrenEnd = end
+			seen = d.markSeenLines(lines, name, fields, end)
+		} else if def.Type != "alias" {
+			seen = d.isSeen(name, def)
+		}
+		if !seen {
+			continue
+		}
+		anySeen = true
+		for line := item.Line; line <= end; line++ {
+			if line < childrenStart || line > childrenEnd {
+				lines[line] = true
+			}
+		}
+	}
+	return anySeen
+}
+
+// isSeen returns true if the field with the given name and definition has been found
+// in any document.
+func (d *fieldsDirUsage) isSeen(name string, def FieldDefinition) bool {
+	if _, found := d.seen[name]; found {
+		return true
+	}
+	objectLike := false
+	switch def.Type {
+	case "", "group", "nested", "object", "flattened":
+		objectLike = true
+	}
+	for key := range d.seen {
+		if compareKeys(name, def, key) {
+			return true
+		}
+		if objectLike && strings.HasPrefix(key, name+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// leafDefinitions returns the definitions of the fields that can hold values, with their
+// full names. Aliases are not included as they don't appear in documents.
+func leafDefinitions(prefix string, definitions []FieldDefinition) []FieldDefinition {
+	var leaves []FieldDefinition
+	for _, def := range definitions {
+		name := joinFieldName(prefix, def.Name)
+		if len(def.Fields) > 0 {
+			leaves = append(leaves, leafDefinitions(name, def.Fields)...)
+			continue
+		}
+		if def.Type == "alias" {
+			continue
+		}
+		def.Name = name
+		leaves = append(leaves, def)
+	}
+	return leaves
+}
+
+func joinFieldName(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
@@ -0,0 +1,92 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package fields
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-package/internal/common"
+)
+
+const usageTestFields = `- name: foo
+  type: group
+  fields:
+    - name: seen
+      type: keyword
+    - name: unseen
+      type: long
+- name: labels
+  type: object
+  object_type: keyword
+- name: attributes
+  type: flattened
+- name: source.geo.location
+  type: geo_point
+- name: old
+  type: alias
+  path: foo.seen
+- name: message
+  type: text
+`
+
+func TestFieldsUsage(t *testing.T) {
+	dir := t.TempDir()
+	fieldsDir := filepath.Join(dir, "fields")
+	require.NoError(t, os.MkdirAll(fieldsDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(fieldsDir, "fields.yml"), []byte(usageTestFields), 0644))
+
+	repositoryRoot, err := os.OpenRoot(dir)
+	require.NoError(t, err)
+	defer repositoryRoot.Close()
+
+	docs := []common.MapStr{
+		{"foo": map[string]any{"seen": "a"}},
+		{
+			"labels":     map[string]any{"a": "b"},
+			"attributes": map[string]any{"c": map[string]any{"d": "e"}},
+			"source":     map[string]any{"geo": map[string]any{"location": map[string]any{"lat": 1.0, "lon": 2.0}}},
+		},
+	}
+
+	usage := NewFieldsUsage()
+	for _, doc := range docs {
+		validator, err := CreateValidator(repositoryRoot, dir, fieldsDir, WithDisabledDependencyManagement(), WithSeenFieldsTracking(true))
+		require.NoError(t, err)
+		require.Empty(t, validator.ValidateDocumentMap(doc))
+		usage.Add(validator)
+	}
+
+	assert.Equal(t, []string{fieldsDir}, usage.FieldsDirs())
+	assert.Equal(t, []string{"foo.unseen", "message"}, usage.UnseenFields(fieldsDir))
+
+	seenLines, err := usage.SeenLines(fieldsDir, filepath.Join(fieldsDir, "fields.yml"), 19)
+	require.NoError(t, err)
+	expectedLines := map[int]bool{
+		1: true, 2: true, 3: true, 4: true, 5: true,
+		8: true, 9: true, 10: true, 11: true, 12: true, 13: true, 14: true,
+	}
+	assert.Equal(t, expectedLines, seenLines)
+}
+
+func TestSeenFieldsTrackingDisabled(t *testing.T) {
+	dir := t.TempDir()
+	fieldsDir := filepath.Join(dir, "fields")
+	require.NoError(t, os.MkdirAll(fieldsDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(fieldsDir, "fields.yml"), []byte(usageTestFields), 0644))
+
+	repositoryRoot, err := os.OpenRoot(dir)
+	require.NoError(t, err)
+	defer repositoryRoot.Close()
+
+	validator, err := CreateValidator(repositoryRoot, dir, fieldsDir, WithDisabledDependencyManagement())
+	require.NoError(t, err)
+	require.Empty(t, validator.ValidateDocumentMap(common.MapStr{"foo": map[string]any{"seen": "a"}}))
+	assert.Empty(t, validator.SeenFields())
+}
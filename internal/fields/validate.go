@@ -11,6 +11,8 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"math"
+	"math/big"
 	"net"
 	"os"
 	"regexp"
@@ -18,6 +20,8 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/cbroglie/mustache"
@@ -160,6 +164,22 @@ type Validator struct {
 	injectFieldsOptions InjectFieldsOptions
 
 	schemaURLs SchemaURLs
+
+	// fieldsDir is the directory with the fields files of the validated data stream or package.
+	fieldsDir string
+
+	// packageFields contains the definitions of the fields defined in the fields directory.
+	packageFields []FieldDefinition
+
+	// trackSeenFields enables tracking of the fields found in validated documents.
+	trackSeenFields bool
+
+	// seenFields contains the keys of the fields found in validated documents.
+	seenFieldsMutex sync.Mutex
+	seenFields      map[string]struct{}
+
+	// schemaIndex is the compiled schema used to look for field definitions.
+	schemaIndex atomic.Pointer[schemaIndex]
 }
 
 // ValidatorOption represents an optional flag that can be passed to  CreateValidatorForDirectory.
@@ -267,6 +287,15 @@ func WithSchemaURLs(schemaURLs SchemaURLs) ValidatorOption {
 	}
 }
 
+// WithSeenFieldsTracking configures the validator to keep track of the fields found in the
+// validated documents, so they can be aggregated in a FieldsUsage.
+func WithSeenFieldsTracking(track bool) ValidatorOption {
+	return func(v *Validator) error {
+		v.trackSeenFields = track
+		return nil
+	}
+}
+
 // CreateValidator creates a validator for a given fields directory, contained under the indicated repository and package roots.
 func CreateValidator(repositoryRoot *os.Root, packageRoot string, fieldsDir string, opts ...ValidatorOption) (v *Validator, err error) {
 	v = new(Validator)
@@ -279,6 +308,8 @@ func CreateValidator(repositoryRoot *os.Root, packageRoot string, fieldsDir stri
 	}
 
 	v.allowedCIDRs = initializeAllowedCIDRsList()
+	v.fieldsDir = fieldsDir
+	v.seenFields = make(map[string]struct{})
 
 	if _, err := os.Stat(fieldsDir); err == nil {
 		linksFS, err := files.CreateLinksFSFromPath(repositoryRoot, fieldsDir)
@@ -299,12 +330,25 @@ func CreateValidator(repositoryRoot *os.Root, packageRoot string, fieldsDir stri
 		if err != nil {
 			return nil, fmt.Errorf("can't load fields from directory (path: %s): %w", fieldsDir, err)
 		}
+		v.packageFields = fields
 		v.Schema = append(fields, v.Schema...)
 	}
+	v.schemaIndex.Store(newSchemaIndex(v.Schema))
 
 	return v, nil
 }
 
+// index returns the compiled schema of the validator. It is compiled again if the schema
+// has been replaced since the last time it was compiled.
+func (v *Validator) index() *schemaIndex {
+	idx := v.schemaIndex.Load()
+	if idx == nil || !idx.compiledFrom(v.Schema) {
+		idx = newSchemaIndex(v.Schema)
+		v.schemaIndex.Store(idx)
+	}
+	return idx
+}
+
 func initDependencyManagement(packageRoot string, specVersion semver.Version, importECSSchema bool, urls SchemaURLs) (*DependencyManager, []FieldDefinition, error) {
 	buildManifest, ok, err := buildmanifest.ReadBuildManifest(packageRoot)
 	if err != nil {
@@ -658,9 +702,19 @@ func (v *Validator) validateMapElement(root string, elem common.MapStr, doc comm
 				}
 			}
 		case map[string]any:
-			if isFieldTypeFlattened(key, v.Schema) {
+			if isFieldTypeFlattened(key, v.index()) {
 				// Do not traverse into objects with flattened data types
 				// because the entire object is mapped as a single field.
+				v.addSeenField(key)
+				continue
+			}
+			if isFieldTypeGeoPoint(key, v.index()) {
+				// Geo points can be objects, validate them as a single value.
+				v.addSeenField(key)
+				err := v.validateScalarElement(key, val, doc)
+				if err != nil {
+					errs = append(errs, err...)
+				}
 				continue
 			}
 			err := v.validateMapElement(key, val, doc)
@@ -668,7 +722,8 @@ func (v *Validator) validateMapElement(root string, elem common.MapStr, doc comm
 				errs = append(errs, err...)
 			}
 		default:
-			if skipLeafOfObject(root, name, v.specVersion, v.Schema) {
+			v.addSeenField(key)
+			if skipLeafOfObject(root, name, v.specVersion, v.index()) {
 				// Till some versions we skip some validations on leaf of objects, check if it is the case.
 				break
 			}
@@ -690,18 +745,18 @@ func (v *Validator) validateScalarElement(key string, val any, doc common.MapStr
 		return nil // root key is always valid
 	}
 
-	definition := FindElementDefinition(key, v.Schema)
+	definition := v.index().find(key)
 	if definition == nil {
 		switch {
 		case skipValidationForField(key):
 			return nil // generic field, let's skip validation for now
-		case isFlattenedSubfield(key, v.Schema):
+		case isFlattenedSubfield(key, v.index()):
 			return nil // flattened subfield, it will be stored as member of the flattened ancestor.
 		case isArrayOfObjects(val):
 			return multierror.Error{fmt.Errorf(`field %q is used as array of objects, expected explicit definition with type group or nested`, key)}
-		case couldBeMultifield(key, v.Schema):
+		case couldBeMultifield(key, v.index()):
 			return multierror.Error{fmt.Errorf(`field %q is undefined, could be a multifield`, key)}
-		case !isParentEnabled(key, v.Schema):
+		case !isParentEnabled(key, v.index()):
 			return nil // parent mapping is disabled
 		default:
 			return multierror.Error{fmt.Errorf(`field %q is undefined`, key)}
@@ -728,7 +783,7 @@ func (v *Validator) SanitizeSyntheticSourceDocs(docs []common.MapStr) ([]common.
 	for _, doc := range docs {
 		for key, contents := range doc {
 			shouldBeArray := false
-			definition := FindElementDefinition(key, v.Schema)
+			definition := v.index().find(key)
 			if definition != nil {
 				shouldBeArray = v.shouldValueBeArray(definition)
 			}
@@ -751,7 +806,7 @@ func (v *Validator) SanitizeSyntheticSourceDocs(docs []common.MapStr) ([]common.
 				}
 			}
 		}
-		expandedDoc, newMultifields, err := createDocExpandingObjects(doc, v.Schema)
+		expandedDoc, newMultifields, err := createDocExpandingObjects(doc, v.index())
 		if err != nil {
 			return nil, fmt.Errorf("failure while expanding objects from doc: %w", err)
 		}
@@ -786,7 +841,7 @@ func (v *Validator) shouldValueBeArray(definition *FieldDefinition) bool {
 	return false
 }
 
-func createDocExpandingObjects(doc common.MapStr, schema []FieldDefinition) (common.MapStr, []string, error) {
+func createDocExpandingObjects(doc common.MapStr, schema *schemaIndex) (common.MapStr, []string, error) {
 	keys := make([]string, 0)
 	for k := range doc {
 		keys = append(keys, k)
@@ -827,18 +882,23 @@ func createDocExpandingObjects(doc common.MapStr, schema []FieldDefinition) (com
 // in every (most?) documents collected by Elastic Agent, but aren't defined in any integration in `fields.yml` files.
 // FIXME https://github.com/elastic/elastic-package/issues/147
 func skipValidationForField(key string) bool {
-	return isFieldFamilyMatching("agent", key) ||
-		isFieldFamilyMatching("elastic_agent", key) ||
-		isFieldFamilyMatching("cloud", key) || // too many common fields
-		isFieldFamilyMatching("event", key) || // too many common fields
-		isFieldFamilyMatching("host", key) || // too many common fields
-		isFieldFamilyMatching("metricset", key) || // field is deprecated
-		isFieldFamilyMatching("event.module", key) // field is deprecated
+	return slices.ContainsFunc(skippedFieldFamilies, func(family string) bool {
+		return isFieldFamilyMatching(family, key)
+	})
+}
+
+var skippedFieldFamilies = []string{
+	"agent",
+	"elastic_agent",
+	"cloud",     // too many common fields
+	"event",     // too many common fields
+	"host",      // too many common fields
+	"metricset", // field is deprecated
 }
 
 // skipLeafOfObject checks if the element is a child of an object that was skipped in some previous
 // version of the spec. This is relevant in documents that store fields without subobjects.
-func skipLeafOfObject(root, name string, specVersion semver.Version, schema []FieldDefinition) bool {
+func skipLeafOfObject(root, name string, specVersion semver.Version, schema *schemaIndex) bool {
 	// We are only skipping validation of these fields on versions older than 3.0.1.
 	if !specVersion.LessThan(semver3_0_1) {
 		return false
@@ -853,7 +913,7 @@ func skipLeafOfObject(root, name string, specVersion semver.Version, schema []Fi
 	if root != "" {
 		key = root + "." + name
 	}
-	_, ancestor := findAncestorElementDefinition(key, schema, func(key string, def *FieldDefinition) bool {
+	_, ancestor := schema.findAncestor(key, func(key string, def *FieldDefinition) bool {
 		// Don't look for ancestors beyond root, these objects have been already traversed.
 		if len(key) < len(root) {
 			return false
@@ -871,13 +931,18 @@ func isFieldFamilyMatching(family, key string) bool {
 	return key == family || strings.HasPrefix(key, family+".")
 }
 
-func isFieldTypeFlattened(key string, fieldDefinitions []FieldDefinition) bool {
-	definition := FindElementDefinition(key, fieldDefinitions)
+func isFieldTypeFlattened(key string, schema *schemaIndex) bool {
+	definition := schema.find(key)
 	return definition != nil && definition.Type == "flattened"
 }
 
-func couldBeMultifield(key string, fieldDefinitions []FieldDefinition) bool {
-	parent := findParentElementDefinition(key, fieldDefinitions)
+func isFieldTypeGeoPoint(key string, schema *schemaIndex) bool {
+	definition := schema.find(key)
+	return definition != nil && definition.Type == "geo_point"
+}
+
+func couldBeMultifield(key string, schema *schemaIndex) bool {
+	parent := schema.findParent(key)
 	if parent == nil {
 		// Parent is not defined, so not sure what this can be.
 		return false
@@ -893,8 +958,8 @@ func couldBeMultifield(key string, fieldDefinitions []FieldDefinition) bool {
 // isParentEnabled returns true by default unless the parent field exists and enabled is set false
 // This is needed in order to correctly validate the fields that should not be mapped
 // because parent field mapping was disabled
-func isParentEnabled(key string, fieldDefinitions []FieldDefinition) bool {
-	parent := findParentElementDefinition(key, fieldDefinitions)
+func isParentEnabled(key string, schema *schemaIndex) bool {
+	parent := schema.findParent(key)
 	if parent != nil && parent.Enabled != nil && !*parent.Enabled {
 		return false
 	}
@@ -915,8 +980,8 @@ func isArrayOfObjects(val any) bool {
 	return false
 }
 
-func isFlattenedSubfield(key string, schema []FieldDefinition) bool {
-	_, ancestor := findAncestorElementDefinition(key, schema, func(_ string, def *FieldDefinition) bool {
+func isFlattenedSubfield(key string, schema *schemaIndex) bool {
+	_, ancestor := schema.findAncestor(key, func(_ string, def *FieldDefinition) bool {
 		return def.Type == "flattened"
 	})
 
@@ -957,6 +1022,8 @@ func findElementDefinitionForRoot(root, searchedKey string, fieldDefinitions []F
 }
 
 // FindElementDefinition is a helper function used to find the fields definition in the schema.
+// It walks the whole schema, validators use a compiled index of the schema instead to look for
+// definitions in documents with many fields.
 func FindElementDefinition(searchedKey string, fieldDefinitions []FieldDefinition) *FieldDefinition {
 	return findElementDefinitionForRoot("", searchedKey, fieldDefinitions)
 }
@@ -971,22 +1038,6 @@ func findParentElementDefinition(key string, fieldDefinitions []FieldDefinition)
 	return FindElementDefinition(parentKey, fieldDefinitions)
 }
 
-func findAncestorElementDefinition(key string, fieldDefinitions []FieldDefinition, cond func(string, *FieldDefinition) bool) (string, *FieldDefinition) {
-	for strings.Contains(key, ".") {
-		i := strings.LastIndex(key, ".")
-		key = key[:i]
-		ancestor := FindElementDefinition(key, fieldDefinitions)
-		if ancestor == nil {
-			continue
-		}
-		if cond(key, ancestor) {
-			return key, ancestor
-		}
-	}
-
-	return "", nil
-}
-
 // compareKeys checks if `searchedKey` matches with the given `key`. `key` can contain
 // wildcards (`*`), that match any sequence of characters in `searchedKey` different to dots.
 func compareKeys(key string, def FieldDefinition, searchedKey string) bool {
@@ -1175,13 +1226,14 @@ func (v *Validator) parseSingleElementValue(key string, definition FieldDefiniti
 	// Dates are expected to be formatted as strings or as seconds or milliseconds
 	// since epoch.
 	// If it is a string and a pattern is provided, it checks if the value matches.
+	// Dates are also checked against the date format of the field.
 	case "date":
 		switch val := val.(type) {
 		case string:
 			if err := ensurePatternMatches(key, val, definition.Pattern); err != nil {
 				return multierror.Error{err}
 			}
-		case float64:
+		case float64, json.Number:
 			// date as seconds or milliseconds since epoch
 			if definition.Pattern != "" {
 				return multierror.Error{fmt.Errorf("numeric date in field %q, but pattern defined", key)}
@@ -1189,6 +1241,14 @@ func (v *Validator) parseSingleElementValue(key string, definition FieldDefiniti
 		default:
 			return invalidTypeError()
 		}
+		if err := ensureDateFormatMatches(key, val, definition.DateFormat); err != nil {
+			return multierror.Error{err}
+		}
+	// Geo points can be encoded in different ways, coordinates should be in range.
+	case "geo_point":
+		if err := ensureValidGeoPoint(key, val); err != nil {
+			return multierror.Error{err}
+		}
 	// IP values should be actual IPs, included in the ranges of IPs available
 	// in the geoip test database.
 	// If a pattern is provided, it checks if the value matches.
@@ -1259,6 +1319,15 @@ func (v *Validator) parseSingleElementValue(key string, definition FieldDefiniti
 		default:
 			return invalidTypeError()
 		}
+	// Numbers of types with limited ranges are checked to be in range. Other values, as
+	// strings, are coerced by Elasticsearch and not validated.
+	case "scaled_float", "unsigned_long":
+		switch val.(type) {
+		case float64, json.Number:
+			if err := ensureNumberInRange(key, definition, val); err != nil {
+				return multierror.Error{err}
+			}
+		}
 	// All other types are considered valid not blocking validation.
 	default:
 		return nil
@@ -1332,6 +1401,10 @@ func forEachElementValue(key string, definition FieldDefinition, val any, doc co
 	if !isArray {
 		return fn(key, definition, val, doc)
 	}
+	if definition.Type == "geo_point" && isGeoPointCoordinates(arr) {
+		// Array with the coordinates of a single point.
+		return fn(key, definition, val, doc)
+	}
 	var errs multierror.Error
 	for _, element := range arr {
 		err := fn(key, definition, element, doc)
@@ -1345,6 +1418,53 @@ func forEachElementValue(key string, definition FieldDefinition, val any, doc co
 	return nil
 }
 
+// defaultScalingFactor is the scaling factor of scaled_float fields without scaling_factor.
+const defaultScalingFactor = 1000
+
+// maxUnsignedLong is the maximum value of unsigned_long fields.
+var maxUnsignedLong = new(big.Int).SetUint64(math.MaxUint64)
+
+// maxUnsignedLongFloat is the maximum value of unsigned_long fields, as a float64. It is rounded
+// up to 2^64, so the maximum value is still in range when decoded as float64.
+const maxUnsignedLongFloat = float64(math.MaxUint64)
+
+// ensureNumberInRange validates that numeric values fit in the range of the type of the field.
+// Values of scaled_float fields are stored as longs after multiplying them by the scaling factor.
+func ensureNumberInRange(key string, definition FieldDefinition, val any) error {
+	value := numberString(val)
+	switch definition.Type {
+	case "unsigned_long":
+		var inRange bool
+		if f, ok := val.(float64); ok {
+			inRange = f >= 0 && f <= maxUnsignedLongFloat
+		} else {
+			n, _, err := big.ParseFloat(value, 10, 128, big.ToNearestEven)
+			if err != nil {
+				return fmt.Errorf("field %q's value %s is not a valid unsigned_long: %w", key, value, err)
+			}
+			// Decimals are truncated by Elasticsearch.
+			i, _ := n.Int(nil)
+			inRange = n.Sign() >= 0 && i.Cmp(maxUnsignedLong) <= 0
+		}
+		if !inRange {
+			return fmt.Errorf("field %q's value %s is out of the range of unsigned_long (0 to %d)", key, value, uint64(math.MaxUint64))
+		}
+	case "scaled_float":
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("field %q's value %s is not a valid scaled_float: %w", key, value, err)
+		}
+		scalingFactor := definition.ScalingFactor
+		if scalingFactor == 0 {
+			scalingFactor = defaultScalingFactor
+		}
+		if scaled := math.Round(n * scalingFactor); scaled > math.MaxInt64 || scaled < math.MinInt64 {
+			return fmt.Errorf("field %q's value %s is out of the range of scaled_float with scaling factor %v, scaled values must fit in a long", key, value, scalingFactor)
+		}
+	}
+	return nil
+}
+
 // ensurePatternMatches validates the document's field value matches the field
 // definitions regular expression pattern.
 func ensurePatternMatches(key, value, pattern string) error {
@@ -6,6 +6,7 @@ package fields
 
 import (
 	"encoding/json"
+	"math"
 	"net"
 	"os"
 	"path/filepath"
@@ -456,6 +457,314 @@ func Test_parseElementValue(t *testing.T) {
 			},
 			fail: true,
 		},
+		{
+			key:   "date with default format",
+			value: "2020-11-02T18:01:03.123+01:00",
+			definition: FieldDefinition{
+				Type: "date",
+			},
+		},
+		{
+			key:   "date only with default format",
+			value: "2020-11-02",
+			definition: FieldDefinition{
+				Type: "date",
+			},
+		},
+		{
+			key:   "epoch millis string with default format",
+			value: "1420070400001",
+			definition: FieldDefinition{
+				Type: "date",
+			},
+		},
+		{
+			key:   "date not matching default format",
+			value: "10 Oct 2020 3:42PM",
+			definition: FieldDefinition{
+				Type: "date",
+			},
+			fail: true,
+			assertError: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `does not match the date format "strict_date_optional_time||epoch_millis"`)
+			},
+		},
+		{
+			key:   "date with month out of range",
+			value: "2020-13-02T18:01:03Z",
+			definition: FieldDefinition{
+				Type: "date",
+			},
+			fail: true,
+			assertError: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, "month 13 out of range (1-12)")
+			},
+		},
+		{
+			key:   "date with day out of range",
+			value: "2021-02-29",
+			definition: FieldDefinition{
+				Type: "date",
+			},
+			fail: true,
+			assertError: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, "day 29 out of range (1-28)")
+			},
+		},
+		{
+			key:   "date with custom format",
+			value: "02/11/2020 18:01:03",
+			definition: FieldDefinition{
+				Type:       "date",
+				DateFormat: "dd/MM/yyyy HH:mm:ss",
+			},
+		},
+		{
+			key:   "date with custom format and optional section",
+			value: "2020-11-02 18:01",
+			definition: FieldDefinition{
+				Type:       "date",
+				DateFormat: "yyyy-MM-dd HH:mm[:ss]",
+			},
+		},
+		{
+			key:   "date with one of multiple formats",
+			value: "Nov 02 2020",
+			definition: FieldDefinition{
+				Type:       "date",
+				DateFormat: "strict_date_time||MMM dd yyyy",
+			},
+		},
+		{
+			key:   "date not matching custom format",
+			value: "2020-11-02T18:01:03Z",
+			definition: FieldDefinition{
+				Type:       "date",
+				DateFormat: "dd/MM/yyyy HH:mm:ss",
+			},
+			fail: true,
+		},
+		{
+			key:   "numeric date without epoch format",
+			value: float64(1420070400001),
+			definition: FieldDefinition{
+				Type:       "date",
+				DateFormat: "strict_date_time",
+			},
+			fail: true,
+			assertError: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, `is numeric, but the date format "strict_date_time" doesn't accept numbers`)
+			},
+		},
+		{
+			key:   "numeric date with epoch seconds format",
+			value: float64(1420070400),
+			definition: FieldDefinition{
+				Type:       "date",
+				DateFormat: "epoch_second",
+			},
+		},
+		{
+			key:   "date with unsupported format",
+			value: "anything",
+			definition: FieldDefinition{
+				Type:       "date",
+				DateFormat: "QQQ",
+			},
+		},
+		// geo_point
+		{
+			key:   "geo_point as object",
+			value: map[string]any{"lat": 41.12, "lon": -71.34},
+			definition: FieldDefinition{
+				Type: "geo_point",
+			},
+		},
+		{
+			key:   "geo_point as GeoJSON",
+			value: map[string]any{"type": "Point", "coordinates": []any{-71.34, 41.12}},
+			definition: FieldDefinition{
+				Type: "geo_point",
+			},
+		},
+		{
+			key:   "geo_point as string",
+			value: "41.12,-71.34",
+			definition: FieldDefinition{
+				Type: "geo_point",
+			},
+		},
+		{
+			key:   "geo_point as WKT",
+			value: "POINT (-71.34 41.12)",
+			definition: FieldDefinition{
+				Type: "geo_point",
+			},
+		},
+		{
+			key:   "geo_point as geohash",
+			value: "drm3btev3e86",
+			definition: FieldDefinition{
+				Type: "geo_point",
+			},
+		},
+		{
+			key:   "geo_point as array",
+			value: []any{-71.34, 41.12},
+			definition: FieldDefinition{
+				Type: "geo_point",
+			},
+		},
+		{
+			key:   "array of geo_points",
+			value: []any{"41.12,-71.34", []any{-71.34, 41.12}},
+			definition: FieldDefinition{
+				Type: "geo_point",
+			},
+		},
+		{
+			key:   "geo_point as number",
+			value: 41.12,
+			definition: FieldDefinition{
+				Type: "geo_point",
+			},
+			fail: true,
+		},
+		{
+			key:   "geo_point.lat",
+			value: 41.12,
+			definition: FieldDefinition{
+				Type: "geo_point",
+			},
+		},
+		{
+			key:   "geo_point with latitude out of range",
+			value: map[string]any{"lat": 91.0, "lon": -71.34},
+			definition: FieldDefinition{
+				Type: "geo_point",
+			},
+			fail: true,
+			assertError: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, "latitude 91 out of range (-90 to 90)")
+			},
+		},
+		{
+			key:   "geo_point with longitude out of range",
+			value: "POINT (-181 41.12)",
+			definition: FieldDefinition{
+				Type: "geo_point",
+			},
+			fail: true,
+			assertError: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, "longitude -181 out of range (-180 to 180)")
+			},
+		},
+		{
+			key:   "geo_point with missing longitude",
+			value: map[string]any{"lat": 41.12},
+			definition: FieldDefinition{
+				Type: "geo_point",
+			},
+			fail: true,
+		},
+		{
+			key:   "bad geo_point string",
+			value: "somewhere",
+			definition: FieldDefinition{
+				Type: "geo_point",
+			},
+			fail: true,
+		},
+		// scaled_float and unsigned_long
+		{
+			key:   "scaled_float",
+			value: 12.34,
+			definition: FieldDefinition{
+				Type: "scaled_float",
+			},
+		},
+		{
+			key:   "scaled_float out of range",
+			value: 1e17,
+			definition: FieldDefinition{
+				Type:          "scaled_float",
+				ScalingFactor: 100,
+			},
+			fail: true,
+			assertError: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, "out of the range of scaled_float with scaling factor 100")
+			},
+		},
+		{
+			key:   "unsigned_long",
+			value: json.Number("18446744073709551615"),
+			definition: FieldDefinition{
+				Type: "unsigned_long",
+			},
+		},
+		{
+			key:   "negative unsigned_long",
+			value: float64(-1),
+			definition: FieldDefinition{
+				Type: "unsigned_long",
+			},
+			fail: true,
+			assertError: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, "out of the range of unsigned_long")
+			},
+		},
+		{
+			key:   "unsigned_long too big",
+			value: json.Number("18446744073709551616"),
+			definition: FieldDefinition{
+				Type: "unsigned_long",
+			},
+			fail: true,
+		},
+		{
+			key:   "unsigned_long zero",
+			value: json.Number("0"),
+			definition: FieldDefinition{
+				Type: "unsigned_long",
+			},
+		},
+		{
+			key:   "unsigned_long max as float64",
+			value: float64(18446744073709551615),
+			definition: FieldDefinition{
+				Type: "unsigned_long",
+			},
+		},
+		{
+			key:   "unsigned_long too big as float64",
+			value: math.Nextafter(float64(math.MaxUint64), math.Inf(1)),
+			definition: FieldDefinition{
+				Type: "unsigned_long",
+			},
+			fail: true,
+		},
+		{
+			key:   "unsigned_long as string",
+			value: "18446744073709551615",
+			definition: FieldDefinition{
+				Type: "unsigned_long",
+			},
+		},
+		{
+			key:   "scaled_float as string",
+			value: "12.34",
+			definition: FieldDefinition{
+				Type: "scaled_float",
+			},
+		},
+		{
+			key:   "scaled_float max",
+			value: json.Number("92233720368547758.07"),
+			definition: FieldDefinition{
+				Type:          "scaled_float",
+				ScalingFactor: 100,
+			},
+		},
 		// ip
 		{
 			key:   "ip",
@@ -979,6 +1288,55 @@ func TestValidateGeoPoint(t *testing.T) {
 	require.Empty(t, errs)
 }
 
+func TestValidateGeoPointObjects(t *testing.T) {
+	v := Validator{
+		Schema: []FieldDefinition{
+			{Name: "location", Type: "geo_point"},
+		},
+		disabledDependencyManagement: true,
+	}
+
+	errs := v.ValidateDocumentMap(common.MapStr{
+		"location": map[string]any{"type": "Point", "coordinates": []any{-71.34, 41.12}},
+	})
+	assert.Empty(t, errs)
+
+	errs = v.ValidateDocumentMap(common.MapStr{
+		"location": map[string]any{"lat": 91.0, "lon": -71.34},
+	})
+	require.Len(t, errs, 1)
+	assert.ErrorContains(t, errs[0], "latitude 91 out of range (-90 to 90)")
+
+	errs = v.ValidateDocumentMap(common.MapStr{
+		"location.lon": 181.0,
+	})
+	require.Len(t, errs, 1)
+	assert.ErrorContains(t, errs[0], "longitude 181 out of range (-180 to 180)")
+}
+
+func TestValidateUnsignedLongBounds(t *testing.T) {
+	v := Validator{
+		Schema: []FieldDefinition{
+			{Name: "counter", Type: "unsigned_long"},
+		},
+		disabledDependencyManagement: true,
+	}
+
+	errs := v.ValidateDocumentBody(json.RawMessage(`{"counter": 18446744073709551615}`))
+	assert.Empty(t, errs)
+
+	errs = v.ValidateDocumentBody(json.RawMessage(`{"counter": 0}`))
+	assert.Empty(t, errs)
+
+	errs = v.ValidateDocumentBody(json.RawMessage(`{"counter": -1}`))
+	require.Len(t, errs, 1)
+	assert.ErrorContains(t, errs[0], "out of the range of unsigned_long")
+
+	errs = v.ValidateDocumentBody(json.RawMessage(`{"counter": 18446744073709555000}`))
+	require.Len(t, errs, 1)
+	assert.ErrorContains(t, errs[0], "out of the range of unsigned_long")
+}
+
 func TestValidateExternalMultiField(t *testing.T) {
 	repositoryRoot, packageRoot, fieldsDir := pathsForValidator(t, "parallel", "mongodb", "status")
 	validator, err := CreateValidator(repositoryRoot, packageRoot, fieldsDir,
@@ -1057,7 +1415,7 @@ func TestValidateStackVersionsWithEcsMappings(t *testing.T) {
 }
 
 func TestSkipLeafOfObject(t *testing.T) {
-	schema := []FieldDefinition{
+	schema := newSchemaIndex([]FieldDefinition{
 		{
 			Name: "foo",
 			Type: "keyword",
@@ -1084,7 +1442,7 @@ func TestSkipLeafOfObject(t *testing.T) {
 				},
 			},
 		},
-	}
+	})
 
 	cases := []struct {
 		name     string
@@ -14,10 +14,13 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 // Zip function creates the .zip archive from the source path (built package content).
-func Zip(sourcePath, destinationFile string) error {
+// Entries are added in lexical order, with the given modification time and normalized
+// permissions, so archives created from the same content are identical.
+func Zip(sourcePath, destinationFile string, modTime time.Time) error {
 	out, err := os.Create(destinationFile)
 	if err != nil {
 		return err
@@ -26,7 +29,7 @@ func Zip(sourcePath, destinationFile string) error {
 
 	folderName := folderNameFromFileName(destinationFile)
 	z := zip.NewWriter(out)
-	err = addFSWithPrefix(z, os.DirFS(sourcePath), folderName)
+	err = addFSWithPrefix(z, os.DirFS(sourcePath), folderName, modTime)
 	if err != nil {
 		return fmt.Errorf("failed to add files to package zip: %w", err)
 	}
@@ -52,7 +55,7 @@ func folderNameFromFileName(filename string) string {
 // addFSWithPrefix adds the files from fs.FS to the archive adding as a first folder of the zip the given package (e.g. nginx-1.0.0)
 // Implementation based on AddFS method from archive/zip package in Go 1.20+
 // https://cs.opensource.google/go/go/+/refs/tags/go1.25.4:src/archive/zip/writer.go;l=503
-func addFSWithPrefix(zw *zip.Writer, fsys fs.FS, prefix string) error {
+func addFSWithPrefix(zw *zip.Writer, fsys fs.FS, prefix string, modTime time.Time) error {
 	return fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
@@ -78,6 +81,8 @@ func addFSWithPrefix(zw *zip.Writer, fsys fs.FS, prefix string) error {
 			h.Name += "/"
 		}
 		h.Method = zip.Deflate
+		h.Modified = modTime.UTC()
+		h.SetMode(normalizedFileMode(info.Mode()))
 		fw, err := zw.CreateHeader(h)
 		if err != nil {
 			return err
@@ -94,3 +99,16 @@ func addFSWithPrefix(zw *zip.Writer, fsys fs.FS, prefix string) error {
 		return err
 	})
 }
+
+// normalizedFileMode returns the permissions stored in archives for the given file mode,
+// so they don't depend on the umask or the file system where the package was built.
+func normalizedFileMode(mode fs.FileMode) fs.FileMode {
+	switch {
+	case mode.IsDir():
+		return fs.ModeDir | 0755
+	case mode&0111 != 0:
+		return 0755
+	default:
+		return 0644
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"path"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -21,7 +22,7 @@ func TestZip(t *testing.T) {
 	destinationName := "packagename-1.0.0"
 	destinationFile := filepath.Join(t.TempDir(), destinationName+".zip")
 
-	err := Zip(sourcePath, destinationFile)
+	err := Zip(sourcePath, destinationFile, time.Unix(0, 0))
 	require.NoError(t, err)
 
 	reader, err := zip.OpenReader(destinationFile)
@@ -58,3 +59,51 @@ func TestZip(t *testing.T) {
 	})
 	require.NoError(t, err)
 }
+
+func TestZipReproducible(t *testing.T) {
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	createSource := func(t *testing.T, fileTime time.Time, perm fs.FileMode) string {
+		sourcePath := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(sourcePath, "docs"), 0700))
+		for _, name := range []string{"manifest.yml", "docs/README.md", "changelog.yml"} {
+			p := filepath.Join(sourcePath, filepath.FromSlash(name))
+			require.NoError(t, os.WriteFile(p, []byte(name), perm))
+			require.NoError(t, os.Chtimes(p, fileTime, fileTime))
+		}
+		return sourcePath
+	}
+
+	first := filepath.Join(t.TempDir(), "packagename-1.0.0.zip")
+	require.NoError(t, Zip(createSource(t, time.Now(), 0600), first, modTime))
+	second := filepath.Join(t.TempDir(), "packagename-1.0.0.zip")
+	require.NoError(t, Zip(createSource(t, time.Now().Add(-time.Hour), 0640), second, modTime))
+
+	firstContent, err := os.ReadFile(first)
+	require.NoError(t, err)
+	secondContent, err := os.ReadFile(second)
+	require.NoError(t, err)
+	assert.Equal(t, firstContent, secondContent)
+
+	reader, err := zip.OpenReader(first)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	var names []string
+	for _, f := range reader.File {
+		names = append(names, f.Name)
+		assert.True(t, modTime.Equal(f.Modified), "modification time of %s: %s", f.Name, f.Modified)
+		if f.FileInfo().IsDir() {
+			assert.Equal(t, fs.ModeDir|0755, f.Mode(), f.Name)
+		} else {
+			assert.Equal(t, fs.FileMode(0644), f.Mode(), f.Name)
+		}
+	}
+	expected := []string{
+		"packagename-1.0.0/changelog.yml",
+		"packagename-1.0.0/docs/",
+		"packagename-1.0.0/docs/README.md",
+		"packagename-1.0.0/manifest.yml",
+	}
+	assert.Equal(t, expected, names)
+}
@@ -140,6 +140,11 @@ func (lfs *LinksFS) CheckLinkedFiles() ([]Link, error) {
 	return areLinkedFilesUpToDate(lfs.repositoryRoot, lfs.workDir)
 }
 
+// ListLinkedFiles returns all the linked files in the directory.
+func (lfs *LinksFS) ListLinkedFiles() ([]Link, error) {
+	return listLinkedFiles(lfs.repositoryRoot, lfs.workDir)
+}
+
 // UpdateLinkedFiles updates the checksums of all outdated linked files in the directory.
 // Returns a list of links that were updated.
 func (lfs *LinksFS) UpdateLinkedFiles() ([]Link, error) {
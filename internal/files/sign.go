@@ -103,3 +103,44 @@ func Sign(targetFile string, options SignOptions) error {
 	logger.Infof("Signature file written: %s", targetSigFile)
 	return nil
 }
+
+// Verify function verifies the detached signature of the target file using the provided public key.
+func Verify(targetFile, signatureFile, publicKeyfile string) error {
+	logger.Debugf("Read public keyfile: %s", publicKeyfile)
+	publicKey, err := os.ReadFile(publicKeyfile)
+	if err != nil {
+		return fmt.Errorf("can't read the public keyfile (path: %s): %w", publicKeyfile, err)
+	}
+
+	verificationKey, err := crypto.NewKeyFromArmored(string(publicKey))
+	if err != nil {
+		return fmt.Errorf("crypto.NewKeyFromArmored failed: %w", err)
+	}
+
+	keyRing, err := crypto.NewKeyRing(verificationKey)
+	if err != nil {
+		return fmt.Errorf("crypto.NewKeyRing failed: %w", err)
+	}
+
+	armoredSignature, err := os.ReadFile(signatureFile)
+	if err != nil {
+		return fmt.Errorf("can't read the signature file (path: %s): %w", signatureFile, err)
+	}
+
+	signature, err := crypto.NewPGPSignatureFromArmored(string(armoredSignature))
+	if err != nil {
+		return fmt.Errorf("crypto.NewPGPSignatureFromArmored failed: %w", err)
+	}
+
+	messageReader, err := os.Open(targetFile)
+	if err != nil {
+		return fmt.Errorf("os.Open failed (targetFile: %s): %w", targetFile, err)
+	}
+	defer messageReader.Close()
+
+	err = keyRing.VerifyDetachedStream(messageReader, signature, crypto.GetUnixTime())
+	if err != nil {
+		return fmt.Errorf("invalid signature (signature file: %s): %w", signatureFile, err)
+	}
+	return nil
+}
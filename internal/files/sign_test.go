@@ -0,0 +1,55 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	dir := t.TempDir()
+	passphrase := "secret"
+
+	writeKeys := func(t *testing.T, name string) (string, string) {
+		key, err := crypto.GenerateKey(name, name+"@example.com", "x25519", 0)
+		require.NoError(t, err)
+		locked, err := key.Lock([]byte(passphrase))
+		require.NoError(t, err)
+		privateKey, err := locked.Armor()
+		require.NoError(t, err)
+		publicKey, err := key.GetArmoredPublicKey()
+		require.NoError(t, err)
+
+		privateKeyfile := filepath.Join(dir, name+".private.asc")
+		publicKeyfile := filepath.Join(dir, name+".public.asc")
+		require.NoError(t, os.WriteFile(privateKeyfile, []byte(privateKey), 0600))
+		require.NoError(t, os.WriteFile(publicKeyfile, []byte(publicKey), 0644))
+		return privateKeyfile, publicKeyfile
+	}
+	privateKeyfile, publicKeyfile := writeKeys(t, "signer")
+	_, otherPublicKeyfile := writeKeys(t, "other")
+
+	t.Setenv(signerPrivateKeyfileEnv, privateKeyfile)
+	t.Setenv(signerPassphraseEnv, passphrase)
+	require.NoError(t, VerifySignerConfiguration())
+
+	target := filepath.Join(dir, "foo-1.0.0.zip")
+	require.NoError(t, os.WriteFile(target, []byte("package"), 0644))
+	require.NoError(t, Sign(target, SignOptions{PackageName: "foo", PackageVersion: "1.0.0"}))
+
+	assert.NoError(t, Verify(target, target+".sig", publicKeyfile))
+	assert.Error(t, Verify(target, target+".sig", otherPublicKeyfile), "signed with other key")
+
+	require.NoError(t, os.WriteFile(target, []byte("modified package"), 0644))
+	assert.Error(t, Verify(target, target+".sig", publicKeyfile), "modified file")
+
+	assert.Error(t, Verify(target, filepath.Join(dir, "missing.sig"), publicKeyfile), "missing signature")
+}
@@ -98,6 +98,7 @@ type configFile struct {
 	Status          struct {
 		KibanaRepository kibanaRepositorySettings `yaml:"kibana_repository,omitempty"`
 	} `yaml:"status,omitempty"`
+	Signing signingSettings `yaml:"signing,omitempty"`
 }
 
 type stack struct {
@@ -112,6 +113,10 @@ type kibanaRepositorySettings struct {
 	BaseURL string `yaml:"base_url,omitempty"`
 }
 
+type signingSettings struct {
+	PublicKeyfile string `yaml:"public_keyfile,omitempty"`
+}
+
 func checkImageRefOverride(envVar, fallback string) string {
 	refOverride := os.Getenv(envVar)
 	return stringOrDefault(refOverride, fallback)
@@ -200,6 +205,15 @@ func (ac *ApplicationConfiguration) KibanaRepositoryBaseURL() string {
 	return ac.c.Status.KibanaRepository.BaseURL
 }
 
+// SignerPublicKeyfile returns the path to the public key used to verify signed packages,
+// or an empty string if not configured.
+func (ac *ApplicationConfiguration) SignerPublicKeyfile() string {
+	if ac == nil {
+		return ""
+	}
+	return ac.c.Signing.PublicKeyfile
+}
+
 // selectElasticAgentImageName function returns the appropriate image name for Elastic-Agent depending on the stack version.
 // This is mandatory as "elastic-agent-complete" is available since 7.15.0-SNAPSHOT.
 func selectElasticAgentImageName(agentVersion, agentBaseImage string) string {
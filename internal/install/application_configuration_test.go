@@ -224,3 +224,19 @@ status:
 		})
 	}
 }
+
+func TestSignerPublicKeyfile(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	config, err := configurationFromDir(tmpDir)
+	require.NoError(t, err)
+	assert.Empty(t, config.SignerPublicKeyfile())
+
+	configFilePath := filepath.Join(tmpDir, applicationConfigurationYmlFile)
+	err = os.WriteFile(configFilePath, []byte("signing:\n  public_keyfile: /path/to/key.asc\n"), 0644)
+	require.NoError(t, err)
+
+	config, err = configurationFromDir(tmpDir)
+	require.NoError(t, err)
+	assert.Equal(t, "/path/to/key.asc", config.SignerPublicKeyfile())
+}
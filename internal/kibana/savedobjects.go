@@ -320,3 +320,75 @@ func (c *Client) ImportSavedObjects(ctx context.Context, importRequest ImportSav
 	}
 	return &results, nil
 }
+
+// SavedObject is a Kibana saved object, as returned by the saved objects API.
+type SavedObject struct {
+	ID                   string                 `json:"id"`
+	Type                 string                 `json:"type"`
+	Attributes           map[string]any         `json:"attributes,omitempty"`
+	References           []SavedObjectReference `json:"references,omitempty"`
+	MigrationVersion     map[string]string      `json:"migrationVersion,omitempty"`
+	TypeMigrationVersion string                 `json:"typeMigrationVersion,omitempty"`
+	CoreMigrationVersion string                 `json:"coreMigrationVersion,omitempty"`
+	Error                *SavedObjectError      `json:"error,omitempty"`
+}
+
+// SavedObjectReference is a reference from a saved object to another one.
+type SavedObjectReference struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// SavedObjectError is the error returned for a saved object that cannot be retrieved.
+type SavedObjectError struct {
+	StatusCode int    `json:"statusCode"`
+	Error      string `json:"error"`
+	Message    string `json:"message"`
+}
+
+func (e SavedObjectError) String() string {
+	if e.Message != "" {
+		return fmt.Sprintf("%s (status code: %d)", e.Message, e.StatusCode)
+	}
+	return fmt.Sprintf("%s (status code: %d)", e.Error, e.StatusCode)
+}
+
+type BulkGetSavedObjectsRequestObject struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+type bulkGetSavedObjectsResponse struct {
+	SavedObjects []SavedObject `json:"saved_objects"`
+}
+
+// BulkGetSavedObjects method retrieves multiple saved objects in a single request.
+// Objects that cannot be retrieved, because they don't exist or because they cannot
+// be migrated to the running version of Kibana, are returned with the Error field set.
+func (c *Client) BulkGetSavedObjects(ctx context.Context, objects []BulkGetSavedObjectsRequestObject) ([]SavedObject, error) {
+	if len(objects) == 0 {
+		return nil, nil
+	}
+
+	body, err := json.Marshal(objects)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	path := SavedObjectsAPI + "/_bulk_get"
+	statusCode, respBody, err := c.SendRequest(ctx, http.MethodPost, path, body)
+	if err != nil {
+		return nil, fmt.Errorf("could not get saved objects; API status code = %d; response body = %s: %w", statusCode, string(respBody), err)
+	}
+	if statusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not get saved objects; API status code = %d; response body = %s", statusCode, string(respBody))
+	}
+
+	var resp bulkGetSavedObjectsResponse
+	err = json.Unmarshal(respBody, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshalling response failed (body: \n%s): %w", string(respBody), err)
+	}
+	return resp.SavedObjects, nil
+}
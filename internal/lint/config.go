@@ -0,0 +1,90 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileName is the name of the file, in the root of the repository, with the configuration of the lint rules.
+const ConfigFileName = ".elastic-package-lint.yml"
+
+// Config is the configuration of the lint rules in a repository.
+type Config struct {
+	Rules map[string]RuleConfig `yaml:"rules"`
+}
+
+// RuleConfig overrides the default settings of a rule.
+type RuleConfig struct {
+	// Enabled can be set to false to disable the rule.
+	Enabled *bool `yaml:"enabled"`
+
+	// Severity overrides the default severity of the rule.
+	Severity Severity `yaml:"severity"`
+}
+
+// LoadConfig reads the configuration of the lint rules from the root of the repository. If there
+// is no configuration file, the default settings of the rules are used.
+func LoadConfig(repositoryRoot string) (Config, error) {
+	path := filepath.Join(repositoryRoot, ConfigFileName)
+	d, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Config{}, nil
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("reading lint configuration failed (path: %s): %w", path, err)
+	}
+
+	var config Config
+	err = yaml.Unmarshal(d, &config)
+	if err != nil {
+		return Config{}, fmt.Errorf("unmarshalling lint configuration failed (path: %s): %w", path, err)
+	}
+	err = config.validate()
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid lint configuration (path: %s): %w", path, err)
+	}
+	return config, nil
+}
+
+func (c Config) validate() error {
+	var errs []error
+	for id, ruleConfig := range c.Rules {
+		if _, found := rules[id]; !found {
+			errs = append(errs, fmt.Errorf("unknown rule %q", id))
+		}
+		switch ruleConfig.Severity {
+		case "", SeverityError, SeverityWarning, SeverityOff:
+		default:
+			errs = append(errs, fmt.Errorf("invalid severity %q for rule %q, valid values: %s, %s, %s", ruleConfig.Severity, id, SeverityError, SeverityWarning, SeverityOff))
+		}
+	}
+	slices.SortFunc(errs, func(a, b error) int {
+		return strings.Compare(a.Error(), b.Error())
+	})
+	return errors.Join(errs...)
+}
+
+// severity returns the severity configured for the rule.
+func (c Config) severity(rule Rule) Severity {
+	ruleConfig, found := c.Rules[rule.ID]
+	if !found {
+		return rule.Severity
+	}
+	if ruleConfig.Enabled != nil && !*ruleConfig.Enabled {
+		return SeverityOff
+	}
+	if ruleConfig.Severity != "" {
+		return ruleConfig.Severity
+	}
+	return rule.Severity
+}
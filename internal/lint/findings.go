@@ -0,0 +1,152 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/elastic/package-spec/v3/code/go/pkg/specerrors"
+
+	"github.com/elastic/elastic-package/internal/docs"
+	"github.com/elastic/elastic-package/internal/fields"
+)
+
+// Identifiers of the findings reported by other checks than the lint rules.
+const (
+	RulePackageSpec   = "package-spec"
+	RuleReadme        = "readme-up-to-date"
+	RuleFieldConflict = "field-conflict"
+)
+
+var checkDescriptions = map[string]string{
+	RulePackageSpec:   "Package is valid according to the package specification.",
+	RuleReadme:        "README files are up-to-date with their templates.",
+	RuleFieldConflict: "Fields are defined with the same settings in all the data streams of the package, and in ECS.",
+}
+
+var specErrorFilePattern = regexp.MustCompile(`file "([^"]+)"`)
+
+// ValidationFindings returns the findings for the errors found when validating the package against
+// the package specification. Errors with an assigned code are reported with the code as rule identifier.
+func ValidationFindings(err error, packageRoot string, severity Severity) []Finding {
+	if err == nil {
+		return nil
+	}
+
+	var errs []error
+	var validationErrors specerrors.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		for _, validationError := range validationErrors {
+			errs = append(errs, validationError)
+		}
+	} else {
+		errs = []error{err}
+	}
+
+	findings := make([]Finding, 0, len(errs))
+	for _, e := range errs {
+		finding := Finding{
+			RuleID:   RulePackageSpec,
+			Severity: severity,
+			File:     "manifest.yml",
+			Message:  e.Error(),
+		}
+		var validationError specerrors.ValidationError
+		if errors.As(e, &validationError) && validationError.Code() != specerrors.UnassignedCode {
+			finding.RuleID = validationError.Code()
+		}
+		if match := specErrorFilePattern.FindStringSubmatch(finding.Message); match != nil {
+			finding.File = packageRelativePath(packageRoot, match[1])
+		}
+		findings = append(findings, finding)
+	}
+	return findings
+}
+
+// ReadmeFindings returns the findings for the README files that are outdated, or couldn't be checked.
+func ReadmeFindings(readmeFiles []docs.ReadmeFile) []Finding {
+	var findings []Finding
+	for _, f := range readmeFiles {
+		finding := Finding{
+			RuleID:   RuleReadme,
+			Severity: SeverityError,
+			File:     path.Join("docs", f.FileName),
+		}
+		switch {
+		case f.Error != nil:
+			finding.Message = fmt.Sprintf("check if %s is up-to-date failed: %s", f.FileName, f.Error)
+		case !f.UpToDate:
+			finding.Message = fmt.Sprintf("%s is outdated, rebuild the package with \"elastic-package build\"", f.FileName)
+			finding.Details = f.Diff
+		default:
+			continue
+		}
+		findings = append(findings, finding)
+	}
+	return findings
+}
+
+// FieldConflictFindings returns the findings for conflicting field definitions. They are reported
+// in the first file of the package defining the field. Conflicts only with ECS are warnings.
+func FieldConflictFindings(conflicts []fields.FieldConflict) []Finding {
+	findings := make([]Finding, 0, len(conflicts))
+	for _, conflict := range conflicts {
+		finding := Finding{
+			RuleID:   RuleFieldConflict,
+			Severity: SeverityWarning,
+			Message:  conflict.String(),
+		}
+		if conflict.InPackage {
+			finding.Severity = SeverityError
+		}
+		for _, definition := range conflict.Definitions {
+			if strings.HasPrefix(definition.Location, "ECS ") {
+				continue
+			}
+			finding.File, finding.Line = splitLocation(definition.Location)
+			break
+		}
+		findings = append(findings, finding)
+	}
+	return findings
+}
+
+// ruleDescription returns the description of the rule or check with the given identifier.
+func ruleDescription(id string) string {
+	if rule, found := rules[id]; found {
+		return rule.Description
+	}
+	if description, found := checkDescriptions[id]; found {
+		return description
+	}
+	return fmt.Sprintf("Package is valid according to the package specification (%s).", id)
+}
+
+// splitLocation splits locations with the form "path:line".
+func splitLocation(location string) (string, int) {
+	file, lineStr, found := strings.Cut(location, ":")
+	if !found {
+		return filepath.ToSlash(location), 0
+	}
+	line, err := strconv.Atoi(lineStr)
+	if err != nil {
+		return filepath.ToSlash(location), 0
+	}
+	return filepath.ToSlash(file), line
+}
+
+func packageRelativePath(packageRoot, file string) string {
+	rel, err := filepath.Rel(packageRoot, file)
+	if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		file = rel
+	}
+	return filepath.ToSlash(file)
+}
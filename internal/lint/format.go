@@ -0,0 +1,228 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/elastic/elastic-package/internal/version"
+)
+
+// Formats of the findings.
+const (
+	FormatText   = "text"
+	FormatJSON   = "json"
+	FormatSARIF  = "sarif"
+	FormatGitHub = "github"
+)
+
+// Formats is the list of supported formats of the findings.
+var Formats = []string{FormatText, FormatJSON, FormatSARIF, FormatGitHub}
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	toolName     = "elastic-package"
+	toolURI      = "https://github.com/elastic/elastic-package"
+)
+
+// Format returns the findings in the given format. Paths of the files are prefixed with basePath,
+// so they can be relative to the root of the repository, as expected by code scanning tools.
+func Format(format string, findings []Finding, basePath string) (string, error) {
+	prefixed := make([]Finding, len(findings))
+	for i, finding := range findings {
+		finding.File = path.Join(basePath, finding.File)
+		prefixed[i] = finding
+	}
+
+	switch format {
+	case FormatText:
+		return formatText(prefixed), nil
+	case FormatJSON:
+		return formatJSON(prefixed)
+	case FormatSARIF:
+		return formatSARIF(prefixed)
+	case FormatGitHub:
+		return formatGitHub(prefixed), nil
+	default:
+		return "", fmt.Errorf("unsupported format %q, supported formats: %s", format, strings.Join(Formats, ", "))
+	}
+}
+
+// formatText returns the findings in a human readable form, one per line, followed by their details.
+func formatText(findings []Finding) string {
+	var sb strings.Builder
+	for _, finding := range findings {
+		fmt.Fprintf(&sb, "%s: %s\n", finding.Severity, finding)
+		if finding.Details != "" {
+			sb.WriteString(strings.TrimSuffix(finding.Details, "\n") + "\n")
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+func formatJSON(findings []Finding) (string, error) {
+	d, err := json.MarshalIndent(findings, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("encoding findings failed: %w", err)
+	}
+	return string(d), nil
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+	EndLine   int `json:"endLine,omitempty"`
+}
+
+func formatSARIF(findings []Finding) (string, error) {
+	var ruleIDs []string
+	results := make([]sarifResult, 0, len(findings))
+	for _, finding := range findings {
+		if !slices.Contains(ruleIDs, finding.RuleID) {
+			ruleIDs = append(ruleIDs, finding.RuleID)
+		}
+		location := sarifLocation{
+			PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: finding.File},
+			},
+		}
+		if finding.Line > 0 {
+			location.PhysicalLocation.Region = &sarifRegion{StartLine: finding.Line}
+			if finding.EndLine > finding.Line {
+				location.PhysicalLocation.Region.EndLine = finding.EndLine
+			}
+		}
+		results = append(results, sarifResult{
+			RuleID:    finding.RuleID,
+			Level:     sarifLevel(finding.Severity),
+			Message:   sarifMessage{Text: finding.Message},
+			Locations: []sarifLocation{location},
+		})
+	}
+
+	slices.Sort(ruleIDs)
+	rules := make([]sarifRule, 0, len(ruleIDs))
+	for _, id := range ruleIDs {
+		rules = append(rules, sarifRule{ID: id, ShortDescription: sarifMessage{Text: ruleDescription(id)}})
+	}
+
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           toolName,
+				Version:        version.Tag,
+				InformationURI: toolURI,
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+	d, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("encoding SARIF log failed: %w", err)
+	}
+	return string(d), nil
+}
+
+func sarifLevel(severity Severity) string {
+	switch severity {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// formatGitHub returns the findings as GitHub Actions workflow commands, so they are shown as
+// annotations in pull requests.
+func formatGitHub(findings []Finding) string {
+	var sb strings.Builder
+	for _, finding := range findings {
+		command := "notice"
+		switch finding.Severity {
+		case SeverityError:
+			command = "error"
+		case SeverityWarning:
+			command = "warning"
+		}
+		properties := []string{"file=" + escapeGitHubProperty(finding.File)}
+		if finding.Line > 0 {
+			properties = append(properties, fmt.Sprintf("line=%d", finding.Line))
+			if finding.EndLine > finding.Line {
+				properties = append(properties, fmt.Sprintf("endLine=%d", finding.EndLine))
+			}
+		}
+		properties = append(properties, "title="+escapeGitHubProperty(finding.RuleID))
+		fmt.Fprintf(&sb, "::%s %s::%s\n", command, strings.Join(properties, ","), escapeGitHubData(finding.Message))
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+func escapeGitHubData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+func escapeGitHubProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
@@ -0,0 +1,205 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package lint
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/elastic/package-spec/v3/code/go/pkg/specerrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-package/internal/docs"
+	"github.com/elastic/elastic-package/internal/fields"
+)
+
+var testFindings = []Finding{
+	{
+		RuleID:   "readme-up-to-date",
+		Severity: SeverityError,
+		File:     "docs/README.md",
+		Message:  "README.md is outdated",
+	},
+	{
+		RuleID:   "processor-tag",
+		Severity: SeverityWarning,
+		File:     "data_stream/access/elasticsearch/ingest_pipeline/default.yml",
+		Line:     8,
+		Message:  "grok processor has no tag",
+	},
+	{
+		RuleID:   "SVR00004",
+		Severity: SeverityInfo,
+		File:     "kibana/dashboard/apache-logs.json",
+		Message:  "references found in dashboard, first line\nsecond line: 100%",
+	},
+}
+
+func TestFormatText(t *testing.T) {
+	findings := append([]Finding{{
+		RuleID:   RuleReadme,
+		Severity: SeverityError,
+		File:     "docs/README.md",
+		Message:  "README.md is outdated",
+		Details:  "-old\n+new\n",
+	}}, testFindings[1:]...)
+	output, err := Format(FormatText, findings, "")
+	require.NoError(t, err)
+	assert.Equal(t, `error: docs/README.md: README.md is outdated (readme-up-to-date)
+-old
++new
+warning: data_stream/access/elasticsearch/ingest_pipeline/default.yml:8: grok processor has no tag (processor-tag)
+info: kibana/dashboard/apache-logs.json: references found in dashboard, first line
+second line: 100% (SVR00004)`, output)
+
+	output, err = Format(FormatText, nil, "")
+	require.NoError(t, err)
+	assert.Empty(t, output)
+}
+
+func TestFormatJSON(t *testing.T) {
+	output, err := Format(FormatJSON, testFindings, "packages/apache")
+	require.NoError(t, err)
+
+	var findings []Finding
+	require.NoError(t, json.Unmarshal([]byte(output), &findings))
+	require.Len(t, findings, 3)
+	assert.Equal(t, "packages/apache/docs/README.md", findings[0].File)
+	assert.Equal(t, 8, findings[1].Line)
+
+	output, err = Format(FormatJSON, nil, "packages/apache")
+	require.NoError(t, err)
+	assert.Equal(t, "[]", output)
+}
+
+func TestFormatSARIF(t *testing.T) {
+	output, err := Format(FormatSARIF, testFindings, "packages/apache")
+	require.NoError(t, err)
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal([]byte(output), &log))
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+
+	driver := log.Runs[0].Tool.Driver
+	assert.Equal(t, "elastic-package", driver.Name)
+	require.Len(t, driver.Rules, 3)
+	assert.Equal(t, "SVR00004", driver.Rules[0].ID)
+	assert.Equal(t, "processor-tag", driver.Rules[1].ID)
+	assert.Equal(t, rules["processor-tag"].Description, driver.Rules[1].ShortDescription.Text)
+
+	results := log.Runs[0].Results
+	require.Len(t, results, 3)
+	assert.Equal(t, "error", results[0].Level)
+	assert.Nil(t, results[0].Locations[0].PhysicalLocation.Region)
+	assert.Equal(t, "warning", results[1].Level)
+	assert.Equal(t, "packages/apache/data_stream/access/elasticsearch/ingest_pipeline/default.yml", results[1].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, &sarifRegion{StartLine: 8}, results[1].Locations[0].PhysicalLocation.Region)
+	assert.Equal(t, "note", results[2].Level)
+}
+
+func TestFormatGitHub(t *testing.T) {
+	output, err := Format(FormatGitHub, testFindings, "")
+	require.NoError(t, err)
+	assert.Equal(t, `::error file=docs/README.md,title=readme-up-to-date::README.md is outdated
+::warning file=data_stream/access/elasticsearch/ingest_pipeline/default.yml,line=8,title=processor-tag::grok processor has no tag
+::notice file=kibana/dashboard/apache-logs.json,title=SVR00004::references found in dashboard, first line%0Asecond line: 100%25`, output)
+}
+
+func TestFormatEndLine(t *testing.T) {
+	findings := []Finding{{
+		RuleID:   "pipeline-condition-field",
+		Severity: SeverityWarning,
+		File:     "data_stream/access/elasticsearch/ingest_pipeline/default.yml",
+		Line:     8,
+		EndLine:  12,
+		Message:  "condition of set processor references field \"foo\", that is never set before it runs",
+	}}
+
+	output, err := Format(FormatText, findings, "")
+	require.NoError(t, err)
+	assert.Contains(t, output, "default.yml:8-12: condition of set processor")
+
+	output, err = Format(FormatGitHub, findings, "")
+	require.NoError(t, err)
+	assert.Contains(t, output, ",line=8,endLine=12,")
+
+	output, err = Format(FormatSARIF, findings, "")
+	require.NoError(t, err)
+	var log sarifLog
+	require.NoError(t, json.Unmarshal([]byte(output), &log))
+	assert.Equal(t, &sarifRegion{StartLine: 8, EndLine: 12}, log.Runs[0].Results[0].Locations[0].PhysicalLocation.Region)
+}
+
+func TestFormatUnsupported(t *testing.T) {
+	_, err := Format("xml", testFindings, "")
+	assert.Error(t, err)
+}
+
+func TestValidationFindings(t *testing.T) {
+	packageRoot := filepath.Join(t.TempDir(), "apache")
+	err := specerrors.ValidationErrors{
+		specerrors.NewStructuredErrorf(`file "%s" is invalid: field title: Invalid type. Expected: string, given: integer`, filepath.Join(packageRoot, "manifest.yml")),
+		specerrors.NewStructuredError(errors.New(`references found in dashboard kibana/dashboard/apache-logs.json`), specerrors.CodeVisualizationByValue),
+		specerrors.NewStructuredErrorf(`file "%s" is invalid: expected type: object`, filepath.Join(packageRoot, "data_stream", "access", "fields", "base-fields.yml")),
+	}
+
+	findings := ValidationFindings(err, packageRoot, SeverityError)
+	require.Len(t, findings, 3)
+	assert.Equal(t, RulePackageSpec, findings[0].RuleID)
+	assert.Equal(t, "manifest.yml", findings[0].File)
+	assert.Equal(t, SeverityError, findings[0].Severity)
+	assert.Equal(t, specerrors.CodeVisualizationByValue, findings[1].RuleID)
+	assert.Equal(t, "data_stream/access/fields/base-fields.yml", findings[2].File)
+
+	findings = ValidationFindings(errors.New("failed to read config filter"), packageRoot, SeverityError)
+	assert.Equal(t, []Finding{{RuleID: RulePackageSpec, Severity: SeverityError, File: "manifest.yml", Message: "failed to read config filter"}}, findings)
+
+	assert.Empty(t, ValidationFindings(nil, packageRoot, SeverityError))
+}
+
+func TestReadmeFindings(t *testing.T) {
+	findings := ReadmeFindings([]docs.ReadmeFile{
+		{FileName: "README.md", UpToDate: false},
+		{FileName: "other.md", UpToDate: false, Error: errors.New("template not found")},
+	})
+	require.Len(t, findings, 2)
+	assert.Equal(t, "docs/README.md", findings[0].File)
+	assert.Contains(t, findings[0].Message, "README.md is outdated")
+	assert.Equal(t, "docs/other.md", findings[1].File)
+	assert.Contains(t, findings[1].Message, "template not found")
+}
+
+func TestFieldConflictFindings(t *testing.T) {
+	findings := FieldConflictFindings([]fields.FieldConflict{
+		{
+			Name:    "apache.status.total_bytes",
+			Setting: "type",
+			Definitions: []fields.FieldConflictDefinition{
+				{Value: "long", Location: "data_stream/status/fields/fields.yml:12"},
+				{Value: "keyword", Location: "data_stream/access/fields/fields.yml:4"},
+			},
+			InPackage: true,
+		},
+		{
+			Name:    "source.ip",
+			Setting: "type",
+			Definitions: []fields.FieldConflictDefinition{
+				{Value: "ip", Location: "ECS git@v8.11.0"},
+				{Value: "keyword", Location: "data_stream/access/fields/fields.yml"},
+			},
+		},
+	})
+	require.Len(t, findings, 2)
+	assert.Equal(t, SeverityError, findings[0].Severity)
+	assert.Equal(t, "data_stream/status/fields/fields.yml", findings[0].File)
+	assert.Equal(t, 12, findings[0].Line)
+	assert.Equal(t, SeverityWarning, findings[1].Severity)
+	assert.Equal(t, "data_stream/access/fields/fields.yml", findings[1].File)
+	assert.Zero(t, findings[1].Line)
+}
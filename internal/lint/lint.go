@@ -0,0 +1,222 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package lint
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/elastic/elastic-package/internal/fields"
+	"github.com/elastic/elastic-package/internal/packages"
+)
+
+// Severity is the severity of the findings of a rule.
+type Severity string
+
+const (
+	// SeverityError findings make linting fail.
+	SeverityError Severity = "error"
+
+	// SeverityWarning findings are reported, but don't make linting fail.
+	SeverityWarning Severity = "warning"
+
+	// SeverityInfo findings are only informative, as validation errors skipped by the package
+	// configuration.
+	SeverityInfo Severity = "info"
+
+	// SeverityOff disables the rule.
+	SeverityOff Severity = "off"
+)
+
+// Finding is an issue found by a rule in a file of the package.
+type Finding struct {
+	// RuleID is the identifier of the rule that reported the finding.
+	RuleID string `json:"rule_id"`
+
+	// Severity is the severity of the rule, as configured for the repository.
+	Severity Severity `json:"severity"`
+
+	// File is the path of the file, relative to the package root.
+	File string `json:"file"`
+
+	// Line is the line of the file where the issue was found, or zero if it applies to the whole file.
+	Line int `json:"line,omitempty"`
+
+	// EndLine is the last line of the issue when it spans several lines, as a whole processor, or zero.
+	EndLine int `json:"end_line,omitempty"`
+
+	// Message describes the issue.
+	Message string `json:"message"`
+
+	// Details contains additional information about the issue, as the differences with the expected
+	// content of a file.
+	Details string `json:"details,omitempty"`
+}
+
+func (f Finding) String() string {
+	location := f.File
+	switch {
+	case f.EndLine > f.Line && f.Line > 0:
+		location = fmt.Sprintf("%s:%d-%d", f.File, f.Line, f.EndLine)
+	case f.Line > 0:
+		location = fmt.Sprintf("%s:%d", f.File, f.Line)
+	}
+	return fmt.Sprintf("%s: %s (%s)", location, f.Message, f.RuleID)
+}
+
+// Package is the package checked by the rules.
+type Package struct {
+	// Root is the path to the root of the package.
+	Root string
+
+	// Manifest is the manifest of the package.
+	Manifest *packages.PackageManifest
+
+	// RepositoryRoot is the root of the repository, used to resolve linked files. If it is not
+	// set, only the files in the package can be resolved.
+	RepositoryRoot *os.Root
+
+	// SchemaURLs are the URLs of the external schemas the package can depend on, as ECS.
+	SchemaURLs fields.SchemaURLs
+
+	fieldDefinitions map[string][]fields.FieldDefinition
+}
+
+// RunOption configures how rules are run.
+type RunOption func(pkg *Package)
+
+// WithRepositoryRoot sets the root of the repository, used to resolve linked files.
+func WithRepositoryRoot(root *os.Root) RunOption {
+	return func(pkg *Package) {
+		pkg.RepositoryRoot = root
+	}
+}
+
+// WithSchemaURLs sets the URLs of the external schemas used to resolve field definitions.
+func WithSchemaURLs(urls fields.SchemaURLs) RunOption {
+	return func(pkg *Package) {
+		pkg.SchemaURLs = urls
+	}
+}
+
+// knownFields returns the definitions of the fields in the fields directory, relative to the package
+// root, and of the external fields the package depends on. Definitions are cached.
+func (p *Package) knownFields(fieldsDir string) ([]fields.FieldDefinition, error) {
+	if definitions, found := p.fieldDefinitions[fieldsDir]; found {
+		return definitions, nil
+	}
+
+	repositoryRoot := p.RepositoryRoot
+	if repositoryRoot == nil {
+		root, err := os.OpenRoot(p.Root)
+		if err != nil {
+			return nil, fmt.Errorf("opening package root failed: %w", err)
+		}
+		defer root.Close()
+		repositoryRoot = root
+	}
+
+	definitions, err := fields.KnownFieldDefinitions(repositoryRoot, p.Root, filepath.Join(p.Root, fieldsDir), p.SchemaURLs)
+	if err != nil {
+		return nil, fmt.Errorf("loading field definitions failed (path: %s): %w", fieldsDir, err)
+	}
+	if p.fieldDefinitions == nil {
+		p.fieldDefinitions = make(map[string][]fields.FieldDefinition)
+	}
+	p.fieldDefinitions[fieldsDir] = definitions
+	return definitions, nil
+}
+
+// finding returns a finding for the file at path, which is relative to the package root.
+func (p *Package) finding(path string, line int, format string, a ...any) Finding {
+	return Finding{
+		File:    filepath.ToSlash(path),
+		Line:    line,
+		Message: fmt.Sprintf(format, a...),
+	}
+}
+
+// CheckFunc looks for issues in the package.
+type CheckFunc func(pkg *Package) ([]Finding, error)
+
+// Rule is a check that can be enabled or disabled, and whose severity can be configured per repository.
+type Rule struct {
+	// ID identifies the rule in the configuration and in the findings.
+	ID string
+
+	// Description explains what the rule checks.
+	Description string
+
+	// Severity is the default severity of the findings of the rule.
+	Severity Severity
+
+	// Check looks for issues in the package.
+	Check CheckFunc
+}
+
+var rules = map[string]Rule{}
+
+// RegisterRule registers a lint rule.
+func RegisterRule(rule Rule) {
+	rules[rule.ID] = rule
+}
+
+// Rules returns the registered rules, sorted by their identifier.
+func Rules() []Rule {
+	result := make([]Rule, 0, len(rules))
+	for _, rule := range rules {
+		result = append(result, rule)
+	}
+	slices.SortFunc(result, func(a, b Rule) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return result
+}
+
+// Run runs the enabled rules on the package, and returns their findings sorted by file and line.
+func Run(packageRoot string, config Config, opts ...RunOption) ([]Finding, error) {
+	err := config.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := packages.ReadPackageManifestFromPackageRoot(packageRoot)
+	if err != nil {
+		return nil, fmt.Errorf("reading package manifest failed (path: %s): %w", packageRoot, err)
+	}
+	pkg := Package{Root: packageRoot, Manifest: manifest}
+	for _, opt := range opts {
+		opt(&pkg)
+	}
+
+	var findings []Finding
+	for _, rule := range Rules() {
+		severity := config.severity(rule)
+		if severity == SeverityOff {
+			continue
+		}
+		ruleFindings, err := rule.Check(&pkg)
+		if err != nil {
+			return nil, fmt.Errorf("running lint rule %q failed: %w", rule.ID, err)
+		}
+		for _, finding := range ruleFindings {
+			finding.RuleID = rule.ID
+			finding.Severity = severity
+			findings = append(findings, finding)
+		}
+	}
+
+	slices.SortStableFunc(findings, func(a, b Finding) int {
+		return cmp.Or(
+			cmp.Compare(a.File, b.File),
+			cmp.Compare(a.Line, b.Line),
+			cmp.Compare(a.RuleID, b.RuleID),
+		)
+	})
+	return findings, nil
+}
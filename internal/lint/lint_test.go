@@ -0,0 +1,175 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestPackage(t *testing.T, files map[string]string) string {
+	packageRoot := t.TempDir()
+	for path, content := range files {
+		path = filepath.Join(packageRoot, filepath.FromSlash(path))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return packageRoot
+}
+
+var testPackageFiles = map[string]string{
+	"manifest.yml": `name: apache
+title: Apache
+version: 1.0.0
+type: integration
+`,
+	"data_stream/access/manifest.yml": "title: Access logs\ntype: logs\n",
+	"data_stream/access/elasticsearch/ingest_pipeline/default.yml": `---
+description: Pipeline for access logs
+processors:
+  - set:
+      tag: set_ecs_version
+      field: ecs.version
+      value: 8.11.0
+  - grok:
+      field: message
+      patterns:
+        - '%{COMMONAPACHELOG}'
+      on_failure:
+        - append:
+            field: error.message
+            value: '{{{ _ingest.on_failure_message }}}'
+on_failure:
+  - set:
+      tag: set_error
+      field: event.kind
+      value: pipeline_error
+`,
+	"data_stream/status/manifest.yml":      "title: Status metrics\ntype: metrics\n",
+	"data_stream/status/sample_event.json": "{}",
+	"kibana/dashboard/apache-logs.json": `{
+  "attributes": {
+    "description": "",
+    "title": "[Logs Apache] Access and error logs"
+  },
+  "id": "apache-logs"
+}`,
+	"kibana/dashboard/apache-metrics.json": `{
+  "attributes": {
+    "description": "",
+    "title": "Apache metrics"
+  },
+  "id": "apache-metrics"
+}`,
+}
+
+func TestRun(t *testing.T) {
+	packageRoot := createTestPackage(t, testPackageFiles)
+
+	findings, err := Run(packageRoot, Config{})
+	require.NoError(t, err)
+	assert.Equal(t, []Finding{
+		{
+			RuleID:   "processor-tag",
+			Severity: SeverityWarning,
+			File:     "data_stream/access/elasticsearch/ingest_pipeline/default.yml",
+			Line:     8,
+			Message:  "grok processor has no tag",
+		},
+		{
+			RuleID:   "processor-tag",
+			Severity: SeverityWarning,
+			File:     "data_stream/access/elasticsearch/ingest_pipeline/default.yml",
+			Line:     13,
+			Message:  "append processor has no tag",
+		},
+		{
+			RuleID:   "sample-event",
+			Severity: SeverityWarning,
+			File:     "data_stream/access/manifest.yml",
+			Message:  `data stream has no sample_event.json, generate it with "elastic-package test system --generate"`,
+		},
+		{
+			RuleID:   "dashboard-title-prefix",
+			Severity: SeverityWarning,
+			File:     "kibana/dashboard/apache-metrics.json",
+			Line:     4,
+			Message:  `dashboard title "Apache metrics" doesn't start with a prefix including the package title, as "[Apache]"`,
+		},
+	}, withoutRules(findings, "pipeline-event-original", "pipeline-field-undefined"))
+
+	assert.Equal(t, "kibana/dashboard/apache-metrics.json:4: "+findings[len(findings)-1].Message+" (dashboard-title-prefix)", findings[len(findings)-1].String())
+}
+
+func TestRunWithConfig(t *testing.T) {
+	packageRoot := createTestPackage(t, testPackageFiles)
+	disabled := false
+
+	findings, err := Run(packageRoot, Config{
+		Rules: map[string]RuleConfig{
+			"processor-tag":          {Severity: SeverityError},
+			"sample-event":           {Enabled: &disabled},
+			"dashboard-title-prefix": {Severity: SeverityOff},
+		},
+	})
+	require.NoError(t, err)
+
+	var rules []string
+	for _, finding := range findings {
+		rules = append(rules, finding.RuleID)
+		if finding.RuleID == "processor-tag" {
+			assert.Equal(t, SeverityError, finding.Severity)
+		}
+	}
+	assert.NotContains(t, rules, "sample-event")
+	assert.NotContains(t, rules, "dashboard-title-prefix")
+	assert.Contains(t, rules, "processor-tag")
+
+	_, err = Run(packageRoot, Config{
+		Rules: map[string]RuleConfig{
+			"unknown-rule":  {Severity: SeverityError},
+			"processor-tag": {Severity: "critical"},
+		},
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `unknown rule "unknown-rule"`)
+		assert.Contains(t, err.Error(), `invalid severity "critical" for rule "processor-tag"`)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	repositoryRoot := t.TempDir()
+
+	config, err := LoadConfig(repositoryRoot)
+	require.NoError(t, err)
+	assert.Empty(t, config.Rules)
+
+	configFile := filepath.Join(repositoryRoot, ConfigFileName)
+	require.NoError(t, os.WriteFile(configFile, []byte("rules:\n  processor-tag:\n    severity: error\n  sample-event:\n    enabled: false\n"), 0644))
+	config, err = LoadConfig(repositoryRoot)
+	require.NoError(t, err)
+	assert.Equal(t, SeverityError, config.severity(rules["processor-tag"]))
+	assert.Equal(t, SeverityOff, config.severity(rules["sample-event"]))
+	assert.Equal(t, SeverityWarning, config.severity(rules["dashboard-title-prefix"]))
+
+	require.NoError(t, os.WriteFile(configFile, []byte("rules:\n  foo:\n    severity: error\n"), 0644))
+	_, err = LoadConfig(repositoryRoot)
+	assert.Error(t, err)
+}
+
+func withoutRules(findings []Finding, ruleIDs ...string) []Finding {
+	var result []Finding
+	for _, finding := range findings {
+		if !slices.Contains(ruleIDs, finding.RuleID) {
+			result = append(result, finding)
+		}
+	}
+	return result
+}
@@ -0,0 +1,101 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package lint
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/elastic/elastic-package/internal/packages"
+)
+
+const sampleEventFile = "sample_event.json"
+
+func init() {
+	RegisterRule(Rule{
+		ID:          "sample-event",
+		Description: "Data streams have a sample event, generated by system tests.",
+		Severity:    SeverityWarning,
+		Check:       checkSampleEvents,
+	})
+	RegisterRule(Rule{
+		ID:          "dashboard-title-prefix",
+		Description: `Dashboard titles start with a prefix that includes the name of the package, as "[Logs Apache]".`,
+		Severity:    SeverityWarning,
+		Check:       checkDashboardTitles,
+	})
+}
+
+func checkSampleEvents(pkg *Package) ([]Finding, error) {
+	manifests, err := filepath.Glob(filepath.Join(pkg.Root, "data_stream", "*", packages.DataStreamManifestFile))
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	for _, manifestPath := range manifests {
+		_, err := os.Stat(filepath.Join(filepath.Dir(manifestPath), sampleEventFile))
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		rel, err := filepath.Rel(pkg.Root, manifestPath)
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, pkg.finding(rel, 0, "data stream has no %s, generate it with \"elastic-package test system --generate\"", sampleEventFile))
+	}
+	return findings, nil
+}
+
+func checkDashboardTitles(pkg *Package) ([]Finding, error) {
+	dashboards, err := filepath.Glob(filepath.Join(pkg.Root, "kibana", "dashboard", "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	for _, dashboardPath := range dashboards {
+		rel, err := filepath.Rel(pkg.Root, dashboardPath)
+		if err != nil {
+			return nil, err
+		}
+		dashboard, err := readNode(dashboardPath)
+		if err != nil {
+			return nil, err
+		}
+		title := mappingValue(mappingValue(dashboard, "attributes"), "title")
+		if title == nil || title.Value == "" {
+			findings = append(findings, pkg.finding(rel, 0, "dashboard has no title"))
+			continue
+		}
+		if !hasPackagePrefix(title.Value, pkg.Manifest.Title) {
+			findings = append(findings, pkg.finding(rel, title.Line, "dashboard title %q doesn't start with a prefix including the package title, as \"[%s]\"", title.Value, pkg.Manifest.Title))
+		}
+	}
+	return findings, nil
+}
+
+// hasPackagePrefix checks if the title starts with a bracketed prefix including any of the words
+// of the package title, so prefixes as "[Logs Apache]" or "[Cisco]" are valid for packages with
+// titles as "Apache" or "Cisco ASA".
+func hasPackagePrefix(title, packageTitle string) bool {
+	if !strings.HasPrefix(title, "[") {
+		return false
+	}
+	prefix, _, found := strings.Cut(title[1:], "]")
+	if !found {
+		return false
+	}
+	prefixWords := strings.Fields(strings.ToLower(prefix))
+	return slices.ContainsFunc(strings.Fields(strings.ToLower(packageTitle)), func(word string) bool {
+		return slices.Contains(prefixWords, word)
+	})
+}
@@ -0,0 +1,474 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/elastic/elastic-package/internal/elasticsearch/ingest"
+)
+
+func init() {
+	RegisterRule(Rule{
+		ID:          "pipeline-condition-field",
+		Description: "Conditions of ingest processors reference fields that can be set before the processor runs.",
+		Severity:    SeverityWarning,
+		Check:       checkPipelineConditionFields,
+	})
+	RegisterRule(Rule{
+		ID:          "pipeline-remove-unset-field",
+		Description: "Remove processors remove fields that can be set before the processor runs.",
+		Severity:    SeverityWarning,
+		Check:       checkPipelineRemovedFields,
+	})
+	RegisterRule(Rule{
+		ID:          "pipeline-duplicate-processor",
+		Description: "Ingest pipelines don't have consecutive processors with the same settings.",
+		Severity:    SeverityWarning,
+		Check:       checkPipelineDuplicateProcessors,
+	})
+	RegisterRule(Rule{
+		ID:          "pipeline-missing-pipeline",
+		Description: "Pipeline processors reference pipelines defined in the package.",
+		Severity:    SeverityWarning,
+		Check:       checkPipelineReferences,
+	})
+	RegisterRule(Rule{
+		ID:          "pipeline-shadowed-on-failure",
+		Description: "Processors with on_failure handlers don't ignore failures, so the handlers can run.",
+		Severity:    SeverityWarning,
+		Check:       checkPipelineShadowedOnFailure,
+	})
+}
+
+// inputFields are fields expected in the documents received by pipelines, even if they are
+// not defined in the package.
+var inputFields = []string{"@timestamp", "message", "tags", "event.original"}
+
+// defaultObjectTargets are the fields written by processors that write objects, when they don't
+// have a target_field.
+var defaultObjectTargets = map[string]string{
+	"attachment":        "attachment",
+	"community_id":      "network.community_id",
+	"fingerprint":       "fingerprint",
+	"geoip":             "geoip",
+	"inference":         "ml.inference",
+	"ip_location":       "ip_location",
+	"network_direction": "network.direction",
+	"set_security_user": "user",
+	"uri_parts":         "url",
+	"user_agent":        "user_agent",
+}
+
+var (
+	conditionFieldPattern        = regexp.MustCompile(`ctx((?:\??\.[A-Za-z_@][\w@]*|\[\s*['"][^'"]+['"]\s*\])+)(\s*\()?`)
+	conditionFieldSegmentPattern = regexp.MustCompile(`\??\.([A-Za-z_@][\w@]*)|\[\s*['"]([^'"]+)['"]\s*\]`)
+	ingestPipelineReference      = regexp.MustCompile(`\{\{\s*IngestPipeline\s+"([^"]+)"\s*\}\}`)
+)
+
+// pipelineDataFlow tracks the fields that can be present in documents while the processors of
+// a pipeline run.
+type pipelineDataFlow struct {
+	produced []string
+
+	// read are the fields read by the processors that already ran, they are expected in the
+	// input documents.
+	read []string
+
+	// unknown is set after processors that can write any field, as scripts or other pipelines.
+	unknown bool
+}
+
+// canBeSet checks if the field can be in the document when a processor runs, because it is set or
+// read by a previous processor, or by any of the given fields read by the processor itself, or it
+// is a field always expected in the input documents. Mappings of the data stream are not taken
+// into account, as they don't mean that the fields are set.
+func (f *pipelineDataFlow) canBeSet(field string, reads ...string) bool {
+	if f.unknown || slices.Contains(inputFields, field) {
+		return true
+	}
+	related := func(other string) bool { return relatedFields(field, other) }
+	return slices.ContainsFunc(f.produced, related) ||
+		slices.ContainsFunc(f.read, related) ||
+		slices.ContainsFunc(reads, related)
+}
+
+// clone returns a copy of the data flow, that can be modified without affecting this one.
+func (f *pipelineDataFlow) clone() *pipelineDataFlow {
+	return &pipelineDataFlow{
+		produced: slices.Clone(f.produced),
+		read:     slices.Clone(f.read),
+		unknown:  f.unknown,
+	}
+}
+
+// relatedFields checks if the fields are the same, or one is a parent of the other one.
+func relatedFields(a, b string) bool {
+	return a == b || strings.HasPrefix(a, b+".") || strings.HasPrefix(b, a+".")
+}
+
+// processorReads returns the fields read by the processor.
+func processorReads(processor pipelineProcessor) []string {
+	var names []string
+	switch processor.Type.Value {
+	case "set", "append":
+	default:
+		names = append(names, scalarValues(mappingValue(processor.Body, "field"))...)
+	}
+	return append(names, scalarValues(mappingValue(processor.Body, "copy_from"))...)
+}
+
+// add registers the fields written by the processor.
+func (f *pipelineDataFlow) add(processor pipelineProcessor) {
+	f.read = append(f.read, processorReads(processor)...)
+	for _, target := range pipelineTargets([]pipelineProcessor{processor}) {
+		f.produced = append(f.produced, target.Field)
+	}
+
+	field := mappingValue(processor.Body, "field")
+	targetField := mappingValue(processor.Body, "target_field")
+	switch processor.Type.Value {
+	case "script", "pipeline":
+		f.unknown = true
+	case "json":
+		if addToRoot := mappingValue(processor.Body, "add_to_root"); addToRoot != nil && addToRoot.Value == "true" {
+			f.unknown = true
+		} else if targetField != nil {
+			f.produced = append(f.produced, targetField.Value)
+		} else if field != nil {
+			f.produced = append(f.produced, field.Value)
+		}
+	case "kv":
+		if targetField == nil {
+			f.unknown = true
+		} else {
+			f.produced = append(f.produced, targetField.Value)
+		}
+	case "csv":
+		f.produced = append(f.produced, scalarValues(mappingValue(processor.Body, "target_fields"))...)
+	case "dot_expander":
+		if field != nil && field.Value == "*" {
+			f.unknown = true
+		} else if field != nil {
+			f.produced = append(f.produced, field.Value)
+		}
+	default:
+		if targetField != nil {
+			f.produced = append(f.produced, scalarValues(targetField)...)
+		} else if target, found := defaultObjectTargets[processor.Type.Value]; found {
+			f.produced = append(f.produced, target)
+		}
+	}
+}
+
+// conditionFields returns the fields of the document referenced in a painless condition.
+func conditionFields(condition string) []string {
+	var result []string
+	for _, match := range conditionFieldPattern.FindAllStringSubmatch(condition, -1) {
+		var segments []string
+		for _, segment := range conditionFieldSegmentPattern.FindAllStringSubmatch(match[1], -1) {
+			segments = append(segments, segment[1]+segment[2])
+		}
+		if match[2] != "" {
+			// The last segment is a method call, as in ctx.message.contains("foo").
+			segments = segments[:len(segments)-1]
+		}
+		if len(segments) == 0 {
+			continue
+		}
+		field := strings.Join(segments, ".")
+		if !checkedTargetField(field) || slices.Contains(result, field) {
+			continue
+		}
+		result = append(result, field)
+	}
+	return result
+}
+
+// processorLines are the main processors of a pipeline, with the lines where they start and end.
+type processorLines []ingest.Processor
+
+// readProcessorLines reads the lines of the main processors of the pipeline at path, relative to
+// the package root.
+func readProcessorLines(pkg *Package, path string) (processorLines, error) {
+	d, err := os.ReadFile(filepath.Join(pkg.Root, path))
+	if err != nil {
+		return nil, err
+	}
+	ext := filepath.Ext(path)
+	pipeline := ingest.Pipeline{
+		Path:    path,
+		Name:    strings.TrimSuffix(filepath.Base(path), ext),
+		Format:  strings.TrimPrefix(ext, "."),
+		Content: d,
+	}
+	return pipeline.Processors()
+}
+
+// finding returns a finding for the processor that starts at the given line. The finding spans
+// until the last line of the main processor that contains it.
+func (l processorLines) finding(pkg *Package, path string, line int, format string, a ...any) Finding {
+	finding := pkg.finding(path, line, format, a...)
+	for _, processor := range l {
+		if processor.FirstLine <= line && line <= processor.LastLine {
+			finding.EndLine = processor.LastLine
+			break
+		}
+	}
+	return finding
+}
+
+// checkPipelineDataFlow runs the check on each processor of the pipelines of the package, with the
+// fields that can be in the document when the processor runs. The check returns the messages of
+// the findings for the processor.
+func checkPipelineDataFlow(pkg *Package, check func(processor pipelineProcessor, flow *pipelineDataFlow) []string) ([]Finding, error) {
+	paths, err := pipelineFiles(pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	for _, path := range paths {
+		pipeline, err := readNode(filepath.Join(pkg.Root, path))
+		if err != nil {
+			return nil, err
+		}
+		processors := pipelineProcessors(pipeline)
+		if len(processors) == 0 {
+			continue
+		}
+		lines, err := readProcessorLines(pkg, path)
+		if err != nil {
+			return nil, err
+		}
+		main := &pipelineDataFlow{}
+		// handlers contains the data flows of the failure handlers, by their list of processors.
+		handlers := make(map[*yaml.Node]*pipelineDataFlow)
+		for _, processor := range processors {
+			if processor.Body.Kind != yaml.MappingNode {
+				continue
+			}
+			flow := main
+			if processor.Handler != nil {
+				flow = handlers[processor.Handler]
+				if flow == nil {
+					// Handler of the pipeline, it runs after the processors that can fail.
+					flow = main.clone()
+					handlers[processor.Handler] = flow
+				}
+			}
+			if processor.OnFailure != nil {
+				// Failure handlers see the fields available when the processor runs, but the
+				// fields they set are not seen by the processors that run after it.
+				handlers[processor.OnFailure] = flow.clone()
+			}
+			for _, message := range check(processor, flow) {
+				findings = append(findings, lines.finding(pkg, path, processor.Type.Line, "%s", message))
+			}
+			flow.add(processor)
+		}
+	}
+	return findings, nil
+}
+
+func checkPipelineConditionFields(pkg *Package) ([]Finding, error) {
+	return checkPipelineDataFlow(pkg, func(processor pipelineProcessor, flow *pipelineDataFlow) []string {
+		condition := mappingValue(processor.Body, "if")
+		if condition == nil || condition.Kind != yaml.ScalarNode {
+			return nil
+		}
+		// Fields read by the processor are expected in the documents when the condition is evaluated.
+		reads := processorReads(processor)
+		var messages []string
+		for _, field := range conditionFields(condition.Value) {
+			if !flow.canBeSet(field, reads...) {
+				messages = append(messages, fmt.Sprintf("condition of %s processor references field %q, that is never set before it runs", processor.Type.Value, field))
+			}
+		}
+		return messages
+	})
+}
+
+func checkPipelineRemovedFields(pkg *Package) ([]Finding, error) {
+	return checkPipelineDataFlow(pkg, func(processor pipelineProcessor, flow *pipelineDataFlow) []string {
+		if processor.Type.Value != "remove" {
+			return nil
+		}
+		var messages []string
+		for _, field := range scalarValues(mappingValue(processor.Body, "field")) {
+			if !checkedTargetField(field) {
+				continue
+			}
+			if !flow.canBeSet(field) {
+				messages = append(messages, fmt.Sprintf("remove processor removes field %q, that is never set before it runs", field))
+			}
+		}
+		return messages
+	})
+}
+
+func checkPipelineDuplicateProcessors(pkg *Package) ([]Finding, error) {
+	paths, err := pipelineFiles(pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	for _, path := range paths {
+		pipeline, err := readNode(filepath.Join(pkg.Root, path))
+		if err != nil {
+			return nil, err
+		}
+		lines, err := readProcessorLines(pkg, path)
+		if err != nil {
+			return nil, err
+		}
+		for _, list := range processorLists(pipeline) {
+			for i := 1; i < len(list.Content); i++ {
+				previous, current := list.Content[i-1], list.Content[i]
+				if !sameProcessor(previous, current) {
+					continue
+				}
+				findings = append(findings, lines.finding(pkg, path, current.Line, "%s processor has the same settings as the previous processor", current.Content[0].Value))
+			}
+		}
+	}
+	return findings, nil
+}
+
+// processorLists returns the lists of processors of the pipeline, including the lists of on_failure handlers.
+func processorLists(pipeline *yaml.Node) []*yaml.Node {
+	var lists []*yaml.Node
+	for _, key := range []string{"processors", "on_failure"} {
+		if list := mappingValue(pipeline, key); list != nil && list.Kind == yaml.SequenceNode {
+			lists = append(lists, list)
+		}
+	}
+	for _, processor := range pipelineProcessors(pipeline) {
+		if processor.OnFailure != nil {
+			lists = append(lists, processor.OnFailure)
+		}
+	}
+	return lists
+}
+
+// sameProcessor checks if two processors have the same type and settings, ignoring their tags
+// and descriptions.
+func sameProcessor(a, b *yaml.Node) bool {
+	if a.Kind != yaml.MappingNode || b.Kind != yaml.MappingNode || len(a.Content) != 2 || len(b.Content) != 2 {
+		return false
+	}
+	if a.Content[0].Value != b.Content[0].Value {
+		return false
+	}
+	var settingsA, settingsB map[string]any
+	if a.Content[1].Decode(&settingsA) != nil || b.Content[1].Decode(&settingsB) != nil {
+		return false
+	}
+	for _, key := range []string{"tag", "description"} {
+		delete(settingsA, key)
+		delete(settingsB, key)
+	}
+	return reflect.DeepEqual(settingsA, settingsB)
+}
+
+func checkPipelineReferences(pkg *Package) ([]Finding, error) {
+	paths, err := pipelineFiles(pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	for _, path := range paths {
+		pipeline, err := readNode(filepath.Join(pkg.Root, path))
+		if err != nil {
+			return nil, err
+		}
+		lines, err := readProcessorLines(pkg, path)
+		if err != nil {
+			return nil, err
+		}
+		for _, processor := range pipelineProcessors(pipeline) {
+			if processor.Type.Value != "pipeline" {
+				continue
+			}
+			if ignoreMissing := mappingValue(processor.Body, "ignore_missing_pipeline"); ignoreMissing != nil && ignoreMissing.Value == "true" {
+				continue
+			}
+			name := mappingValue(processor.Body, "name")
+			if name == nil {
+				continue
+			}
+			match := ingestPipelineReference.FindStringSubmatch(name.Value)
+			if match == nil {
+				// Pipelines not defined in the package, as custom pipelines.
+				continue
+			}
+			found, err := pipelineExists(filepath.Join(pkg.Root, filepath.Dir(path)), match[1])
+			if err != nil {
+				return nil, err
+			}
+			if !found {
+				findings = append(findings, lines.finding(pkg, path, processor.Type.Line, "pipeline processor references pipeline %q, that is not defined in %s", match[1], filepath.ToSlash(filepath.Dir(path))))
+			}
+		}
+	}
+	return findings, nil
+}
+
+// pipelineExists checks if there is a pipeline file, or a link to a pipeline file, with the given name in the directory.
+func pipelineExists(dir, name string) (bool, error) {
+	for _, ext := range []string{".yml", ".yaml", ".json"} {
+		for _, fileName := range []string{name + ext, name + ext + ".link"} {
+			_, err := os.Stat(filepath.Join(dir, fileName))
+			if err == nil {
+				return true, nil
+			}
+			if !errors.Is(err, os.ErrNotExist) {
+				return false, err
+			}
+		}
+	}
+	return false, nil
+}
+
+func checkPipelineShadowedOnFailure(pkg *Package) ([]Finding, error) {
+	paths, err := pipelineFiles(pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	for _, path := range paths {
+		pipeline, err := readNode(filepath.Join(pkg.Root, path))
+		if err != nil {
+			return nil, err
+		}
+		lines, err := readProcessorLines(pkg, path)
+		if err != nil {
+			return nil, err
+		}
+		for _, processor := range pipelineProcessors(pipeline) {
+			ignoreFailure := mappingValue(processor.Body, "ignore_failure")
+			if ignoreFailure == nil || ignoreFailure.Value != "true" {
+				continue
+			}
+			if processor.OnFailure == nil || len(processor.OnFailure.Content) == 0 {
+				continue
+			}
+			findings = append(findings, lines.finding(pkg, path, processor.Type.Line, "on_failure handler of %s processor never runs, because the processor ignores failures", processor.Type.Value))
+		}
+	}
+	return findings, nil
+}
@@ -0,0 +1,354 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package lint
+
+import (
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/elastic/elastic-package/internal/fields"
+)
+
+func init() {
+	RegisterRule(Rule{
+		ID:          "pipeline-field-undefined",
+		Description: "Fields written by ingest processors are defined in the fields of the data stream.",
+		Severity:    SeverityWarning,
+		Check:       checkPipelineFieldsDefined,
+	})
+	RegisterRule(Rule{
+		ID:          "pipeline-field-type",
+		Description: "Fields converted or parsed by ingest processors are defined with a compatible type.",
+		Severity:    SeverityWarning,
+		Check:       checkPipelineFieldTypes,
+	})
+}
+
+var (
+	numericFieldTypes = []string{"long", "integer", "short", "byte", "unsigned_long", "double", "float", "half_float", "scaled_float"}
+	floatFieldTypes   = []string{"double", "float", "half_float", "scaled_float"}
+	stringFieldTypes  = []string{"keyword", "constant_keyword", "wildcard", "text", "match_only_text"}
+	dateFieldTypes    = []string{"date", "date_nanos"}
+
+	// convertStringFieldTypes are the types of fields that can store strings, as they parse them
+	// when documents are indexed.
+	convertStringFieldTypes = slices.Concat(stringFieldTypes, dateFieldTypes, []string{"ip", "version"})
+
+	// convertFieldTypes are the types of fields that can store the values converted by the convert processor.
+	convertFieldTypes = map[string][]string{
+		"integer": numericFieldTypes,
+		"long":    numericFieldTypes,
+		"float":   floatFieldTypes,
+		"double":  floatFieldTypes,
+		"boolean": {"boolean"},
+		"ip":      {"ip"},
+		"string":  convertStringFieldTypes,
+	}
+
+	// grokFieldTypes are the types of fields that can store the values extracted by grok patterns
+	// with a type.
+	grokFieldTypes = map[string][]string{
+		"int":   numericFieldTypes,
+		"long":  numericFieldTypes,
+		"float": floatFieldTypes,
+	}
+)
+
+// objectProcessors are processors whose target field is an object with subfields.
+var objectProcessors = []string{
+	"attachment", "dot_expander", "enrich", "geoip", "inference", "ip_location", "json", "kv",
+	"registered_domain", "uri_parts", "user_agent",
+}
+
+var (
+	grokFieldPattern    = regexp.MustCompile(`%\{\w+:([^:}]+)(?::(\w+))?\}`)
+	dissectFieldPattern = regexp.MustCompile(`%\{([^}]*)\}`)
+)
+
+// pipelineTarget is a field written by a processor, with the type of the value when it is known.
+type pipelineTarget struct {
+	Processor string
+	Field     string
+	Line      int
+
+	// Position is the position of the processor in the list of processors of the pipeline.
+	Position int
+
+	// Types are the types of fields that can store the value, empty if any type can.
+	Types []string
+
+	// ValueType describes the type of the value, for the messages.
+	ValueType string
+}
+
+// pipelineTargets returns the fields written by the processors.
+func pipelineTargets(processors []pipelineProcessor) []pipelineTarget {
+	var targets []pipelineTarget
+	var position int
+	add := func(processor pipelineProcessor, node *yaml.Node, field string, valueType string, types []string) {
+		targets = append(targets, pipelineTarget{
+			Processor: processor.Type.Value,
+			Field:     field,
+			Line:      node.Line,
+			Position:  position,
+			Types:     types,
+			ValueType: valueType,
+		})
+	}
+
+	for i, processor := range processors {
+		position = i
+		field := mappingValue(processor.Body, "field")
+		targetField := mappingValue(processor.Body, "target_field")
+		switch processor.Type.Value {
+		case "set", "append":
+			if field != nil {
+				add(processor, field, field.Value, "", nil)
+			}
+		case "rename":
+			if targetField != nil {
+				add(processor, targetField, targetField.Value, "", nil)
+			}
+		case "convert":
+			convertType := mappingValue(processor.Body, "type")
+			node := targetField
+			if node == nil {
+				node = field
+			}
+			if node != nil && convertType != nil {
+				add(processor, node, node.Value, convertType.Value, convertFieldTypes[convertType.Value])
+			}
+		case "date":
+			if targetField != nil {
+				add(processor, targetField, targetField.Value, "date", dateFieldTypes)
+			} else {
+				add(processor, processor.Type, "@timestamp", "date", dateFieldTypes)
+			}
+		case "grok":
+			patterns := mappingValue(processor.Body, "patterns")
+			if patterns == nil || patterns.Kind != yaml.SequenceNode {
+				continue
+			}
+			for _, pattern := range patterns.Content {
+				for _, match := range grokFieldPattern.FindAllStringSubmatch(pattern.Value, -1) {
+					add(processor, pattern, match[1], match[2], grokFieldTypes[match[2]])
+				}
+			}
+		case "dissect":
+			pattern := mappingValue(processor.Body, "pattern")
+			if pattern == nil {
+				continue
+			}
+			for _, match := range dissectFieldPattern.FindAllStringSubmatch(pattern.Value, -1) {
+				if name, ok := dissectKey(match[1]); ok {
+					add(processor, pattern, name, "", nil)
+				}
+			}
+		default:
+			if targetField != nil && targetField.Kind == yaml.ScalarNode && !slices.Contains(objectProcessors, processor.Type.Value) {
+				add(processor, targetField, targetField.Value, "", nil)
+			}
+		}
+	}
+
+	return slices.DeleteFunc(targets, func(target pipelineTarget) bool {
+		return !checkedTargetField(target.Field)
+	})
+}
+
+// dissectKey returns the name of the field of a dissect key, if the key writes to a field.
+// Skipped keys and reference keys are not considered.
+func dissectKey(key string) (string, bool) {
+	key = strings.TrimSuffix(key, "->")
+	if i := strings.IndexByte(key, '/'); i >= 0 {
+		key = key[:i]
+	}
+	key = strings.TrimPrefix(key, "+")
+	if key == "" || strings.ContainsAny(key[:1], "?*&") {
+		return "", false
+	}
+	return key, true
+}
+
+// checkedTargetField returns false for fields that are not expected to be defined, as temporary
+// fields, metadata fields or fields whose name is a template.
+func checkedTargetField(field string) bool {
+	return field != "" &&
+		!strings.HasPrefix(field, "_") &&
+		!strings.HasPrefix(field, "@metadata") &&
+		!strings.Contains(field, "{{")
+}
+
+// removedField is a field removed, or renamed to another field, by a processor.
+type removedField struct {
+	Field string
+
+	// Position is the position of the processor in the list of processors of the pipeline.
+	Position int
+}
+
+// removedFields returns the fields removed, or renamed to other fields, by the processors.
+func removedFields(processors []pipelineProcessor) []removedField {
+	var removed []removedField
+	for i, processor := range processors {
+		switch processor.Type.Value {
+		case "remove", "rename":
+			for _, field := range scalarValues(mappingValue(processor.Body, "field")) {
+				removed = append(removed, removedField{Field: field, Position: i})
+			}
+		}
+	}
+	return removed
+}
+
+// isRemoved checks if the target field, or any of its parents, is removed by a processor that
+// runs after the one writing it.
+func isRemoved(target pipelineTarget, removed []removedField) bool {
+	return slices.ContainsFunc(removed, func(r removedField) bool {
+		return r.Position > target.Position && (target.Field == r.Field || strings.HasPrefix(target.Field, r.Field+"."))
+	})
+}
+
+// scalarValues returns the value of a scalar node, or the values of a sequence of scalars.
+func scalarValues(node *yaml.Node) []string {
+	var values []string
+	for _, item := range scalarNodes(node) {
+		values = append(values, item.Value)
+	}
+	return values
+}
+
+// scalarNodes returns the node if it is a scalar, or the scalars in a sequence node.
+func scalarNodes(node *yaml.Node) []*yaml.Node {
+	if node == nil {
+		return nil
+	}
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return []*yaml.Node{node}
+	case yaml.SequenceNode:
+		var nodes []*yaml.Node
+		for _, item := range node.Content {
+			if item.Kind == yaml.ScalarNode {
+				nodes = append(nodes, item)
+			}
+		}
+		return nodes
+	}
+	return nil
+}
+
+// pipelineFieldsDir returns the fields directory, relative to the package root, with the definitions
+// of the fields of the documents processed by the pipeline.
+func pipelineFieldsDir(pipelinePath string) string {
+	dir := filepath.Dir(filepath.Dir(filepath.Dir(pipelinePath)))
+	return filepath.Join(dir, "fields")
+}
+
+// pipelineFieldCheck checks a target field of a pipeline, with the definition of the field, or nil if it is not defined.
+type pipelineFieldCheck func(pkg *Package, path string, target pipelineTarget, definition *fields.FieldDefinition) []Finding
+
+// checkPipelineFields runs the check for all the fields written by the pipelines of the package, that are
+// not removed by later processors.
+func checkPipelineFields(pkg *Package, check pipelineFieldCheck) ([]Finding, error) {
+	paths, err := pipelineFiles(pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	for _, path := range paths {
+		pipeline, err := readNode(filepath.Join(pkg.Root, path))
+		if err != nil {
+			return nil, err
+		}
+		processors := pipelineProcessors(pipeline)
+		targets := pipelineTargets(processors)
+		if len(targets) == 0 {
+			continue
+		}
+		definitions, err := pkg.knownFields(pipelineFieldsDir(path))
+		if err != nil {
+			return nil, err
+		}
+		removed := removedFields(processors)
+		for _, target := range targets {
+			if isRemoved(target, removed) {
+				continue
+			}
+			findings = append(findings, check(pkg, path, target, fields.FindElementDefinition(target.Field, definitions))...)
+		}
+	}
+	return findings, nil
+}
+
+func checkPipelineFieldsDefined(pkg *Package) ([]Finding, error) {
+	return checkPipelineFields(pkg, func(pkg *Package, path string, target pipelineTarget, definition *fields.FieldDefinition) []Finding {
+		if definition != nil {
+			return nil
+		}
+		definitions, err := pkg.knownFields(pipelineFieldsDir(path))
+		if err == nil && (hasObjectParent(target.Field, definitions) || hasSubfields("", target.Field, definitions)) {
+			return nil
+		}
+		return []Finding{pkg.finding(path, target.Line, "%s processor writes to field %q, that is not defined in %s", target.Processor, target.Field, filepath.ToSlash(pipelineFieldsDir(path)))}
+	})
+}
+
+func checkPipelineFieldTypes(pkg *Package) ([]Finding, error) {
+	return checkPipelineFields(pkg, func(pkg *Package, path string, target pipelineTarget, definition *fields.FieldDefinition) []Finding {
+		if definition == nil || len(target.Types) == 0 {
+			return nil
+		}
+		switch definition.Type {
+		case "", "group", "object", "nested", "flattened", "geo_point":
+			return nil
+		}
+		if slices.Contains(target.Types, definition.Type) {
+			return nil
+		}
+		return []Finding{pkg.finding(path, target.Line, "%s processor writes a %s value to field %q, that is defined as %s", target.Processor, target.ValueType, target.Field, definition.Type)}
+	})
+}
+
+// hasObjectParent checks if any of the parents of the field is defined as an object that can
+// contain undefined subfields.
+func hasObjectParent(field string, definitions []fields.FieldDefinition) bool {
+	for i := strings.LastIndexByte(field, '.'); i > 0; i = strings.LastIndexByte(field[:i], '.') {
+		parent := fields.FindElementDefinition(field[:i], definitions)
+		if parent == nil {
+			continue
+		}
+		switch parent.Type {
+		case "flattened", "object", "nested", "geo_point":
+			if len(parent.Fields) == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasSubfields checks if there are definitions of subfields of the field, as when the field is an
+// object whose subfields are defined with dotted names.
+func hasSubfields(prefix, field string, definitions []fields.FieldDefinition) bool {
+	for _, definition := range definitions {
+		name := definition.Name
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		if strings.HasPrefix(name, field+".") {
+			return true
+		}
+		if (name == field || strings.HasPrefix(field, name+".")) && hasSubfields(name, field, definition.Fields) {
+			return true
+		}
+	}
+	return false
+}
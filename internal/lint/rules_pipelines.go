@@ -0,0 +1,170 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/elastic/elastic-package/internal/elasticsearch/ingest"
+	"github.com/elastic/elastic-package/internal/packages"
+)
+
+const eventOriginalField = "event.original"
+
+func init() {
+	RegisterRule(Rule{
+		ID:          "processor-tag",
+		Description: "Ingest processors have a tag, so they can be identified in errors and in pipeline statistics.",
+		Severity:    SeverityWarning,
+		Check:       checkProcessorTags,
+	})
+	RegisterRule(Rule{
+		ID:          "pipeline-event-original",
+		Description: "Default ingest pipelines of logs data streams set event.original.",
+		Severity:    SeverityWarning,
+		Check:       checkPipelineEventOriginal,
+	})
+}
+
+// pipelineProcessor is a processor in an ingest pipeline.
+type pipelineProcessor = ingest.ProcessorNode
+
+// pipelineFiles returns the paths, relative to the package root, of all the ingest pipelines of the package.
+func pipelineFiles(pkg *Package) ([]string, error) {
+	var result []string
+	for _, pattern := range []string{
+		filepath.Join("elasticsearch", "ingest_pipeline", "*"),
+		filepath.Join("data_stream", "*", "elasticsearch", "ingest_pipeline", "*"),
+	} {
+		matches, err := filepath.Glob(filepath.Join(pkg.Root, pattern))
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			switch filepath.Ext(match) {
+			case ".yml", ".yaml", ".json":
+			default:
+				continue
+			}
+			rel, err := filepath.Rel(pkg.Root, match)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, rel)
+		}
+	}
+	slices.Sort(result)
+	return result, nil
+}
+
+// pipelineProcessors returns all the processors of the pipeline, including the ones in on_failure
+// handlers and in foreach processors.
+func pipelineProcessors(pipeline *yaml.Node) []pipelineProcessor {
+	return ingest.ProcessorNodes(pipeline)
+}
+
+func checkProcessorTags(pkg *Package) ([]Finding, error) {
+	paths, err := pipelineFiles(pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	for _, path := range paths {
+		pipeline, err := readNode(filepath.Join(pkg.Root, path))
+		if err != nil {
+			return nil, err
+		}
+		for _, processor := range pipelineProcessors(pipeline) {
+			if processor.Body.Kind != yaml.MappingNode {
+				continue
+			}
+			tag := mappingValue(processor.Body, "tag")
+			if tag == nil || tag.Value == "" {
+				findings = append(findings, pkg.finding(path, processor.Type.Line, "%s processor has no tag", processor.Type.Value))
+			}
+		}
+	}
+	return findings, nil
+}
+
+func checkPipelineEventOriginal(pkg *Package) ([]Finding, error) {
+	manifests, err := filepath.Glob(filepath.Join(pkg.Root, "data_stream", "*", packages.DataStreamManifestFile))
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	for _, manifestPath := range manifests {
+		manifest, err := packages.ReadDataStreamManifest(manifestPath)
+		if err != nil {
+			return nil, fmt.Errorf("reading data stream manifest failed (path: %s): %w", manifestPath, err)
+		}
+		if manifest.Type != "logs" {
+			continue
+		}
+
+		path, found, err := defaultPipelineFile(pkg, filepath.Dir(manifestPath))
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+		pipeline, err := readNode(filepath.Join(pkg.Root, path))
+		if err != nil {
+			return nil, err
+		}
+		if setsField(pipelineProcessors(pipeline), eventOriginalField) {
+			continue
+		}
+		line := 0
+		if key, _ := mappingEntry(pipeline, "processors"); key != nil {
+			line = key.Line
+		}
+		findings = append(findings, pkg.finding(path, line, "default pipeline of logs data stream doesn't set %s", eventOriginalField))
+	}
+	return findings, nil
+}
+
+// defaultPipelineFile returns the path, relative to the package root, of the default pipeline of the data stream.
+func defaultPipelineFile(pkg *Package, dataStreamPath string) (string, bool, error) {
+	for _, name := range []string{"default.yml", "default.yaml", "default.json"} {
+		path := filepath.Join(dataStreamPath, "elasticsearch", "ingest_pipeline", name)
+		_, err := os.Stat(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", false, err
+		}
+		rel, err := filepath.Rel(pkg.Root, path)
+		if err != nil {
+			return "", false, err
+		}
+		return rel, true, nil
+	}
+	return "", false, nil
+}
+
+// setsField checks if any of the processors writes to the field. Only set and append processors
+// write to their field, other processors read it and write to their target_field, as rename does.
+func setsField(processors []pipelineProcessor, field string) bool {
+	return slices.ContainsFunc(processors, func(processor pipelineProcessor) bool {
+		key := "target_field"
+		switch processor.Type.Value {
+		case "set", "append":
+			key = "field"
+		}
+		value := mappingValue(processor.Body, key)
+		return value != nil && value.Value == field
+	})
+}
@@ -0,0 +1,319 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package lint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-package/internal/packages"
+)
+
+func TestCheckPipelineEventOriginal(t *testing.T) {
+	cases := []struct {
+		title    string
+		pipeline string
+		expected int
+	}{
+		{
+			title:    "renamed from message",
+			pipeline: "processors:\n  - rename:\n      field: message\n      target_field: event.original\n",
+		},
+		{
+			title:    "set in on_failure of a processor",
+			pipeline: "processors:\n  - json:\n      field: message\n      on_failure:\n        - set:\n            field: event.original\n            copy_from: message\n",
+		},
+		{
+			title:    "json pipeline",
+			pipeline: `{"processors": [{"set": {"field": "event.original", "copy_from": "message"}}]}`,
+		},
+		{
+			title:    "only removed",
+			pipeline: "processors:\n  - remove:\n      field: event.original\n",
+			expected: 1,
+		},
+		{
+			title:    "only read by grok",
+			pipeline: "processors:\n  - grok:\n      field: event.original\n      patterns:\n        - '%{GREEDYDATA:message}'\n",
+			expected: 1,
+		},
+		{
+			title:    "not set",
+			pipeline: "processors:\n  - set:\n      field: ecs.version\n      value: 8.11.0\n",
+			expected: 1,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			packageRoot := createTestPackage(t, map[string]string{
+				"data_stream/access/manifest.yml":                              "type: logs\n",
+				"data_stream/access/elasticsearch/ingest_pipeline/default.yml": c.pipeline,
+				"data_stream/status/manifest.yml":                              "type: metrics\n",
+				"data_stream/status/elasticsearch/ingest_pipeline/default.yml": "processors: []\n",
+			})
+			findings, err := checkPipelineEventOriginal(&Package{Root: packageRoot})
+			require.NoError(t, err)
+			assert.Len(t, findings, c.expected)
+		})
+	}
+}
+
+func TestCheckProcessorTagsForeach(t *testing.T) {
+	packageRoot := createTestPackage(t, map[string]string{
+		"elasticsearch/ingest_pipeline/default.json": `{
+  "processors": [
+    {
+      "foreach": {
+        "tag": "foreach_tags",
+        "field": "tags",
+        "processor": {
+          "lowercase": {
+            "field": "_ingest._value"
+          }
+        }
+      }
+    }
+  ]
+}`,
+	})
+	findings, err := checkProcessorTags(&Package{Root: packageRoot})
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	assert.Equal(t, Finding{File: "elasticsearch/ingest_pipeline/default.json", Line: 8, Message: "lowercase processor has no tag"}, findings[0])
+}
+
+func TestHasPackagePrefix(t *testing.T) {
+	assert.True(t, hasPackagePrefix("[Logs Apache] Access and error logs", "Apache"))
+	assert.True(t, hasPackagePrefix("[Cisco] ASA Firewall", "Cisco ASA"))
+	assert.False(t, hasPackagePrefix("Auth0 overview", "Auth0"))
+	assert.False(t, hasPackagePrefix("[Logs Nginx] Overview", "Apache"))
+	assert.False(t, hasPackagePrefix("[Apache overview", "Apache"))
+}
+
+func TestCheckDashboardTitlesWithoutTitle(t *testing.T) {
+	packageRoot := createTestPackage(t, map[string]string{
+		"kibana/dashboard/foo.json": `{"attributes": {}}`,
+	})
+	findings, err := checkDashboardTitles(&Package{Root: packageRoot, Manifest: &packages.PackageManifest{Title: "Foo"}})
+	require.NoError(t, err)
+	assert.Equal(t, []Finding{{File: "kibana/dashboard/foo.json", Message: "dashboard has no title"}}, findings)
+}
+
+func TestDissectKey(t *testing.T) {
+	cases := map[string]string{
+		"source.ip":    "source.ip",
+		"+message/2":   "message",
+		"event.code->": "event.code",
+		"?skipped":     "",
+		"*key":         "",
+		"&key":         "",
+		"":             "",
+	}
+	for key, expected := range cases {
+		name, ok := dissectKey(key)
+		assert.Equal(t, expected != "", ok, key)
+		assert.Equal(t, expected, name, key)
+	}
+}
+
+func TestCheckPipelineFields(t *testing.T) {
+	packageRoot := createTestPackage(t, map[string]string{
+		"data_stream/access/fields/fields.yml": `- name: apache.access
+  type: group
+  fields:
+    - name: bytes
+      type: keyword
+    - name: code
+      type: long
+    - name: labels
+      type: flattened
+- name: '@timestamp'
+  type: date
+- name: event.created
+  type: keyword
+- name: source.geo.location
+  type: geo_point
+- name: source.ip
+  type: ip
+`,
+		"data_stream/access/elasticsearch/ingest_pipeline/default.yml": `---
+processors:
+  - grok:
+      field: message
+      patterns:
+        - '%{IPORHOST:source.address} %{NUMBER:apache.access.bytes:long} %{NUMBER:apache.access.code:int}'
+  - dissect:
+      field: message
+      pattern: '%{apache.access.method} %{?ignored} %{_tmp.path}'
+  - date:
+      field: apache.access.time
+  - date:
+      field: apache.access.time
+      target_field: event.created
+  - set:
+      field: apache.access.labels.env
+      value: production
+  - set:
+      field: apache.access.internal
+      value: true
+  - remove:
+      field: apache.access.internal
+  - convert:
+      field: source.geo.location.lat
+      type: double
+  - rename:
+      field: apache.access.url
+      target_field: url.original
+      on_failure:
+        - set:
+            field: error.message
+            value: '{{{ _ingest.on_failure_message }}}'
+  - remove:
+      field: apache.access.early
+  - set:
+      field: apache.access.early
+      value: late
+  - foreach:
+      field: tags
+      processor:
+        append:
+          field: related.hosts
+          value: '{{{ _ingest._value }}}'
+  - convert:
+      field: source.ip
+      type: string
+`,
+	})
+	pkg := &Package{Root: packageRoot}
+
+	findings, err := checkPipelineFieldsDefined(pkg)
+	require.NoError(t, err)
+	path := "data_stream/access/elasticsearch/ingest_pipeline/default.yml"
+	assert.Equal(t, []Finding{
+		{File: path, Line: 6, Message: `grok processor writes to field "source.address", that is not defined in data_stream/access/fields`},
+		{File: path, Line: 9, Message: `dissect processor writes to field "apache.access.method", that is not defined in data_stream/access/fields`},
+		{File: path, Line: 28, Message: `rename processor writes to field "url.original", that is not defined in data_stream/access/fields`},
+		{File: path, Line: 31, Message: `set processor writes to field "error.message", that is not defined in data_stream/access/fields`},
+		{File: path, Line: 36, Message: `set processor writes to field "apache.access.early", that is not defined in data_stream/access/fields`},
+		{File: path, Line: 42, Message: `append processor writes to field "related.hosts", that is not defined in data_stream/access/fields`},
+	}, findings)
+
+	findings, err = checkPipelineFieldTypes(pkg)
+	require.NoError(t, err)
+	assert.Equal(t, []Finding{
+		{File: path, Line: 6, Message: `grok processor writes a long value to field "apache.access.bytes", that is defined as keyword`},
+		{File: path, Line: 14, Message: `date processor writes a date value to field "event.created", that is defined as keyword`},
+	}, findings)
+}
+
+func TestConditionFields(t *testing.T) {
+	assert.Equal(t, []string{"json.itype", "event.kind"}, conditionFields(`ctx.json?.itype != null && ctx.event?.kind == 'event'`))
+	assert.Equal(t, []string{"message", "apache.access.url"}, conditionFields(`ctx.message.contains("GET") && ctx['apache']['access'].url != null`))
+	assert.Empty(t, conditionFields(`ctx._ingest?.on_failure_message != null || ctx.containsKey('foo')`))
+}
+
+func TestCheckDeadProcessors(t *testing.T) {
+	path := "data_stream/access/elasticsearch/ingest_pipeline/default.yml"
+	handlersPath := "data_stream/access/elasticsearch/ingest_pipeline/handlers.yml"
+	packageRoot := createTestPackage(t, map[string]string{
+		"data_stream/access/fields/fields.yml": `- name: apache.access.status
+  type: keyword
+`,
+		"data_stream/access/elasticsearch/ingest_pipeline/third-party.yml": "processors: []\n",
+		handlersPath: `processors:
+  - date:
+      field: apache.access.time
+      on_failure:
+        - set:
+            field: error.reason
+            value: invalid date
+        - set:
+            field: event.outcome
+            value: failure
+            if: ctx.error?.reason != null
+            on_failure:
+              - remove:
+                  field: error.reason
+  - set:
+      field: event.kind
+      value: pipeline_error
+      if: ctx.error?.reason != null
+  - remove:
+      field: apache.access.status
+`,
+		path: `---
+processors:
+  - rename:
+      field: json
+      target_field: apache.access
+      if: ctx.json?.status != null
+  - set:
+      field: event.kind
+      value: event
+      if: ctx.event?.dataset == 'apache.access'
+  - lowercase:
+      field: apache.access.status
+      tag: lowercase_status
+  - lowercase:
+      field: apache.access.status
+      tag: lowercase_status_again
+  - remove:
+      field:
+        - event.kind
+        - source.tmp
+  - pipeline:
+      name: '{{ IngestPipeline "third-party" }}'
+  - pipeline:
+      name: '{{ IngestPipeline "missing" }}'
+  - pipeline:
+      name: '{{ IngestPipeline "optional" }}'
+      ignore_missing_pipeline: true
+  - convert:
+      field: apache.access.bytes
+      type: long
+      ignore_failure: true
+      on_failure:
+        - set:
+            field: error.message
+            value: '{{{ _ingest.on_failure_message }}}'
+`,
+	})
+	pkg := &Package{Root: packageRoot}
+
+	findings, err := checkPipelineConditionFields(pkg)
+	require.NoError(t, err)
+	assert.Equal(t, []Finding{
+		{File: path, Line: 7, EndLine: 10, Message: `condition of set processor references field "event.dataset", that is never set before it runs`},
+		{File: handlersPath, Line: 15, EndLine: 18, Message: `condition of set processor references field "error.reason", that is never set before it runs`},
+	}, findings)
+
+	findings, err = checkPipelineRemovedFields(pkg)
+	require.NoError(t, err)
+	assert.Equal(t, []Finding{
+		{File: path, Line: 17, EndLine: 20, Message: `remove processor removes field "source.tmp", that is never set before it runs`},
+		{File: handlersPath, Line: 19, EndLine: 20, Message: `remove processor removes field "apache.access.status", that is never set before it runs`},
+	}, findings)
+
+	findings, err = checkPipelineDuplicateProcessors(pkg)
+	require.NoError(t, err)
+	assert.Equal(t, []Finding{
+		{File: path, Line: 14, EndLine: 16, Message: "lowercase processor has the same settings as the previous processor"},
+	}, findings)
+
+	findings, err = checkPipelineReferences(pkg)
+	require.NoError(t, err)
+	assert.Equal(t, []Finding{
+		{File: path, Line: 23, EndLine: 24, Message: `pipeline processor references pipeline "missing", that is not defined in data_stream/access/elasticsearch/ingest_pipeline`},
+	}, findings)
+
+	findings, err = checkPipelineShadowedOnFailure(pkg)
+	require.NoError(t, err)
+	assert.Equal(t, []Finding{
+		{File: path, Line: 28, EndLine: 35, Message: "on_failure handler of convert processor never runs, because the processor ignores failures"},
+	}, findings)
+}
@@ -0,0 +1,50 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package lint
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// readNode parses a YAML or JSON file keeping the position of its values, and returns its
+// root value.
+func readNode(path string) (*yaml.Node, error) {
+	d, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var document yaml.Node
+	err = yaml.Unmarshal(d, &document)
+	if err != nil {
+		return nil, fmt.Errorf("parsing file failed (path: %s): %w", path, err)
+	}
+	if len(document.Content) == 0 {
+		return &yaml.Node{}, nil
+	}
+	return document.Content[0], nil
+}
+
+// mappingEntry returns the key and the value of an entry in a mapping node, or nil if the node
+// is not a mapping or doesn't contain the key.
+func mappingEntry(node *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i], node.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// mappingValue returns the value of an entry in a mapping node, or nil if it is not found.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	_, value := mappingEntry(node, key)
+	return value
+}
@@ -0,0 +1,267 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+// Package agenttemplate renders the handlebars templates used by packages to
+// configure Elastic Agent inputs and streams (agent/input/*.yml.hbs and
+// data_stream/*/agent/stream/*.yml.hbs), mimicking how Fleet compiles them.
+package agenttemplate
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/aymerick/raymond"
+	"gopkg.in/yaml.v3"
+)
+
+// Var is a variable available for a template, with its type as declared in
+// the package manifests.
+type Var struct {
+	Type  string
+	Value any
+}
+
+// Vars is the set of variables available for a template, by name. Names
+// containing dots are exposed to the template as nested objects.
+type Vars map[string]Var
+
+// Compiled is the result of rendering a template.
+type Compiled struct {
+	// Raw is the rendered text, after root-level YAML variables have been
+	// replaced, and before parsing it as YAML.
+	Raw []byte
+
+	// Config is the parsed configuration, with YAML variables replaced.
+	Config map[string]any
+}
+
+// Compile renders the given template with the given variables and parses
+// the result as YAML, following the same steps as Fleet.
+func Compile(template []byte, vars Vars) (*Compiled, error) {
+	context, yamlValues, err := buildTemplateVariables(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := raymond.Parse(string(template))
+	if err != nil {
+		return nil, fmt.Errorf("parsing template failed: %w", err)
+	}
+	tmpl.RegisterHelpers(helpers)
+
+	rendered, err := tmpl.Exec(context)
+	if err != nil {
+		return nil, fmt.Errorf("rendering template failed: %w", err)
+	}
+
+	raw, err := replaceRootLevelYamlVariables(yamlValues, rendered)
+	if err != nil {
+		return nil, err
+	}
+
+	var config map[string]any
+	if err := yaml.Unmarshal([]byte(raw), &config); err != nil {
+		return &Compiled{Raw: []byte(raw)}, fmt.Errorf("rendered template is not valid YAML: %w", err)
+	}
+	if config == nil {
+		config = map[string]any{}
+	}
+	replaceVariablesInYaml(yamlValues, config)
+
+	return &Compiled{
+		Raw:    []byte(raw),
+		Config: config,
+	}, nil
+}
+
+// buildTemplateVariables builds the context for the template. Variables of
+// type yaml are replaced by placeholders that are substituted once the
+// rendered template is parsed, as Fleet does.
+func buildTemplateVariables(vars Vars) (map[string]any, map[string]any, error) {
+	context := make(map[string]any)
+	yamlValues := make(map[string]any)
+
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		v := vars[name]
+		parts := strings.Split(name, ".")
+		last := parts[len(parts)-1]
+
+		current := context
+		for _, part := range parts[:len(parts)-1] {
+			next, ok := current[part].(map[string]any)
+			if !ok {
+				next = make(map[string]any)
+				current[part] = next
+			}
+			current = next
+		}
+
+		if v.Type != "yaml" {
+			current[last] = safeValue(v.Value)
+			continue
+		}
+
+		placeholder := "##" + name + "##"
+		str, _ := v.Value.(string)
+		if str == "" {
+			current[last] = nil
+			yamlValues[placeholder] = nil
+			continue
+		}
+		var value any
+		if err := yaml.Unmarshal([]byte(str), &value); err != nil {
+			return nil, nil, fmt.Errorf("variable %q is not valid YAML: %w", name, err)
+		}
+		current[last] = raymond.SafeString(`"` + placeholder + `"`)
+		yamlValues[placeholder] = value
+	}
+
+	return context, yamlValues, nil
+}
+
+// safeValue marks all strings in the value as safe, so they are not HTML
+// escaped. Fleet compiles templates with escaping disabled.
+func safeValue(value any) any {
+	switch v := value.(type) {
+	case string:
+		return raymond.SafeString(v)
+	case []any:
+		list := make([]any, len(v))
+		for i, e := range v {
+			list[i] = safeValue(e)
+		}
+		return list
+	case []string:
+		list := make([]any, len(v))
+		for i, e := range v {
+			list[i] = raymond.SafeString(e)
+		}
+		return list
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			m[k] = safeValue(e)
+		}
+		return m
+	default:
+		return value
+	}
+}
+
+func replaceRootLevelYamlVariables(yamlValues map[string]any, rendered string) (string, error) {
+	for placeholder, value := range yamlValues {
+		replacement := ""
+		if value != nil {
+			d, err := yaml.Marshal(value)
+			if err != nil {
+				return "", fmt.Errorf("encoding YAML variable failed: %w", err)
+			}
+			replacement = string(d)
+		}
+		re := regexp.MustCompile(`(?m)^"` + regexp.QuoteMeta(placeholder) + `"`)
+		rendered = re.ReplaceAllLiteralString(rendered, replacement)
+	}
+	return rendered, nil
+}
+
+func replaceVariablesInYaml(yamlValues map[string]any, value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for k, e := range v {
+			v[k] = replaceVariablesInYaml(yamlValues, e)
+		}
+	case []any:
+		for i, e := range v {
+			v[i] = replaceVariablesInYaml(yamlValues, e)
+		}
+	case string:
+		if replacement, found := yamlValues[v]; found {
+			return replacement
+		}
+	}
+	return value
+}
+
+// helpers are the handlebars helpers registered by Fleet.
+var helpers = map[string]any{
+	"contains":                containsHelper,
+	"escape_string":           escapeStringHelper,
+	"escape_multiline_string": escapeMultilineStringHelper,
+	"to_json":                 toJSONHelper,
+	"url_encode":              urlEncodeHelper,
+}
+
+func containsHelper(item any, check any, options *raymond.Options) raymond.SafeString {
+	needle := raymond.Str(item)
+	found := false
+	switch c := check.(type) {
+	case []any:
+		found = slices.ContainsFunc(c, func(e any) bool { return raymond.Str(e) == needle })
+	case string, raymond.SafeString:
+		found = strings.Contains(raymond.Str(c), needle)
+	}
+	if found {
+		return raymond.SafeString(options.Fn())
+	}
+	return raymond.SafeString(options.Inverse())
+}
+
+func escapeStringHelper(value any) raymond.SafeString {
+	str := raymond.Str(value)
+	if str == "" {
+		return ""
+	}
+	return raymond.SafeString("'" + strings.ReplaceAll(str, "'", "''") + "'")
+}
+
+func escapeMultilineStringHelper(value any) raymond.SafeString {
+	str := raymond.Str(value)
+	str = strings.ReplaceAll(str, "'", "''")
+	return raymond.SafeString(strings.ReplaceAll(str, "\n", "\n\n"))
+}
+
+func toJSONHelper(value any) raymond.SafeString {
+	d, err := json.Marshal(unsafeValue(value))
+	if err != nil {
+		// Panics with errors are recovered by raymond and returned by Exec.
+		panic(fmt.Errorf("encoding value to JSON failed: %w", err))
+	}
+	return raymond.SafeString(d)
+}
+
+func urlEncodeHelper(value any) raymond.SafeString {
+	return raymond.SafeString(strings.ReplaceAll(url.QueryEscape(raymond.Str(value)), "+", "%20"))
+}
+
+// unsafeValue reverts safeValue, so values can be encoded.
+func unsafeValue(value any) any {
+	switch v := value.(type) {
+	case raymond.SafeString:
+		return string(v)
+	case []any:
+		list := make([]any, len(v))
+		for i, e := range v {
+			list[i] = unsafeValue(e)
+		}
+		return list
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			m[k] = unsafeValue(e)
+		}
+		return m
+	default:
+		return value
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package agenttemplate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompile(t *testing.T) {
+	cases := []struct {
+		title    string
+		template string
+		vars     Vars
+		expected map[string]any
+		fail     bool
+	}{
+		{
+			title:    "simple values",
+			template: "host: {{host}}\nport: {{port}}\n",
+			vars: Vars{
+				"host": {Type: "text", Value: "localhost"},
+				"port": {Type: "integer", Value: 8080},
+			},
+			expected: map[string]any{
+				"host": "localhost",
+				"port": 8080,
+			},
+		},
+		{
+			title:    "values are not html escaped",
+			template: "query: '{{query}}'\n",
+			vars: Vars{
+				"query": {Type: "text", Value: "a > b & c"},
+			},
+			expected: map[string]any{
+				"query": "a > b & c",
+			},
+		},
+		{
+			title:    "conditionals and lists",
+			template: "{{#if paths}}paths:\n{{#each paths}}  - {{this}}\n{{/each}}{{/if}}{{#if enabled}}enabled: true\n{{/if}}",
+			vars: Vars{
+				"paths":   {Type: "text", Value: []any{"/var/log/a.log", "/var/log/b.log"}},
+				"enabled": {Type: "bool", Value: false},
+			},
+			expected: map[string]any{
+				"paths": []any{"/var/log/a.log", "/var/log/b.log"},
+			},
+		},
+		{
+			title:    "dotted names",
+			template: "timeout: {{http.timeout}}\n",
+			vars: Vars{
+				"http.timeout": {Type: "text", Value: "30s"},
+			},
+			expected: map[string]any{
+				"timeout": "30s",
+			},
+		},
+		{
+			title:    "yaml variables",
+			template: "processors: {{processors}}\n{{extra}}\n",
+			vars: Vars{
+				"processors": {Type: "yaml", Value: "- add_fields:\n    fields:\n      foo: bar\n"},
+				"extra":      {Type: "yaml", Value: "key: value\n"},
+			},
+			expected: map[string]any{
+				"processors": []any{
+					map[string]any{"add_fields": map[string]any{"fields": map[string]any{"foo": "bar"}}},
+				},
+				"key": "value",
+			},
+		},
+		{
+			title:    "helpers",
+			template: "{{#contains \"forwarded\" tags}}forwarded: true\n{{/contains}}name: {{escape_string name}}\njson: {{to_json tags}}\nurl: {{url_encode path}}\n",
+			vars: Vars{
+				"tags": {Type: "text", Value: []any{"forwarded", "other"}},
+				"name": {Type: "text", Value: "it's"},
+				"path": {Type: "text", Value: "a b/c"},
+			},
+			expected: map[string]any{
+				"forwarded": true,
+				"name":      "it's",
+				"json":      []any{"forwarded", "other"},
+				"url":       "a%20b%2Fc",
+			},
+		},
+		{
+			title:    "invalid yaml",
+			template: "key: {{value}}\n",
+			vars: Vars{
+				"value": {Type: "text", Value: "[unclosed"},
+			},
+			fail: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			compiled, err := Compile([]byte(c.template), c.vars)
+			if c.fail {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, compiled.Config)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package buildmanifest
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/elastic/go-ucfg/yaml"
+)
+
+// SizeBudget defines the maximum compressed sizes of the built package, as human-readable
+// sizes (e.g. "10MB"). Empty values are not checked.
+type SizeBudget struct {
+	Total      string            `config:"total"`
+	Categories map[string]string `config:"categories"`
+}
+
+// ReadSizeBudget function reads the size budget of the package. It is kept in its own file
+// under "_dev/shared", as the package spec doesn't allow additional settings in the build manifest.
+func ReadSizeBudget(packageRoot string) (*SizeBudget, bool, error) {
+	path := sizeBudgetPath(packageRoot)
+	cfg, err := yaml.NewConfigWithFile(path, ucfg.PathSep("."))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil // ignore not found errors
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("reading file failed (path: %s): %w", path, err)
+	}
+
+	var budget SizeBudget
+	err = cfg.Unpack(&budget)
+	if err != nil {
+		return nil, true, fmt.Errorf("unpacking size budget failed (path: %s): %w", path, err)
+	}
+	return &budget, true, nil
+}
+
+func sizeBudgetPath(packageRoot string) string {
+	return filepath.Join(packageRoot, "_dev", "shared", "size_budget.yml")
+}
@@ -117,25 +117,33 @@ func VarValueYamlString(vv VarValue, field string, numSpaces ...int) string {
 
 // Variable is an instance of configuration variable (named, typed).
 type Variable struct {
-	Name                  string    `config:"name" json:"name" yaml:"name"`
-	Type                  string    `config:"type" json:"type" yaml:"type"`
-	Title                 string    `config:"title" json:"title" yaml:"title"`
-	Description           string    `config:"description" json:"description" yaml:"description"`
-	Multi                 bool      `config:"multi" json:"multi" yaml:"multi"`
-	Required              bool      `config:"required" json:"required" yaml:"required"`
-	Secret                bool      `config:"secret" json:"secret" yaml:"secret"`
-	ShowUser              bool      `config:"show_user" json:"show_user" yaml:"show_user"`
-	HideInDeploymentModes []string  `config:"hide_in_deployment_modes" json:"hide_in_deployment_modes" yaml:"hide_in_deployment_modes"`
-	UrlAllowedSchemes     []string  `config:"url_allowed_schemes" json:"url_allowed_schemes" yaml:"url_allowed_schemes"`
-	MinDuration           string    `config:"min_duration" json:"min_duration" yaml:"min_duration"`
-	MaxDuration           string    `config:"max_duration" json:"max_duration" yaml:"max_duration"`
-	Default               *VarValue `config:"default" json:"default" yaml:"default"`
+	Name                  string      `config:"name" json:"name" yaml:"name"`
+	Type                  string      `config:"type" json:"type" yaml:"type"`
+	Title                 string      `config:"title" json:"title" yaml:"title"`
+	Description           string      `config:"description" json:"description" yaml:"description"`
+	Multi                 bool        `config:"multi" json:"multi" yaml:"multi"`
+	Required              bool        `config:"required" json:"required" yaml:"required"`
+	Secret                bool        `config:"secret" json:"secret" yaml:"secret"`
+	ShowUser              bool        `config:"show_user" json:"show_user" yaml:"show_user"`
+	HideInDeploymentModes []string    `config:"hide_in_deployment_modes" json:"hide_in_deployment_modes" yaml:"hide_in_deployment_modes"`
+	UrlAllowedSchemes     []string    `config:"url_allowed_schemes" json:"url_allowed_schemes" yaml:"url_allowed_schemes"`
+	MinDuration           string      `config:"min_duration" json:"min_duration" yaml:"min_duration"`
+	MaxDuration           string      `config:"max_duration" json:"max_duration" yaml:"max_duration"`
+	Default               *VarValue   `config:"default" json:"default" yaml:"default"`
+	Options               []VarOption `config:"options" json:"options" yaml:"options"`
+}
+
+// VarOption is one of the possible values of a variable of type select.
+type VarOption struct {
+	Value string `config:"value" json:"value" yaml:"value"`
+	Text  string `config:"text" json:"text" yaml:"text"`
 }
 
 // Input is a single input configuration.
 type Input struct {
-	Type string     `config:"type" json:"type" yaml:"type"`
-	Vars []Variable `config:"vars" json:"vars" yaml:"vars"`
+	Type         string     `config:"type" json:"type" yaml:"type"`
+	TemplatePath string     `config:"template_path" json:"template_path" yaml:"template_path"`
+	Vars         []Variable `config:"vars" json:"vars" yaml:"vars"`
 }
 
 // Source contains metadata about the source code of the package.
@@ -182,6 +190,18 @@ type PolicyTemplate struct {
 	Vars         []Variable `config:"vars,omitempty" json:"vars,omitempty" yaml:"vars,omitempty"`
 }
 
+// Requires defines the packages this package depends on.
+type Requires struct {
+	Input   []PackageDependency `config:"input,omitempty" json:"input,omitempty" yaml:"input,omitempty"`
+	Content []PackageDependency `config:"content,omitempty" json:"content,omitempty" yaml:"content,omitempty"`
+}
+
+// PackageDependency is a reference to a required package and its version constraint.
+type PackageDependency struct {
+	Package string `config:"package" json:"package" yaml:"package"`
+	Version string `config:"version" json:"version" yaml:"version"`
+}
+
 // Owner defines package owners, either a single person or a team.
 type Owner struct {
 	Github string `config:"github" json:"github" yaml:"github"`
@@ -212,6 +232,7 @@ type PackageManifest struct {
 	Categories      []string         `config:"categories" json:"categories" yaml:"categories"`
 	Agent           Agent            `config:"agent" json:"agent" yaml:"agent"`
 	Elasticsearch   *Elasticsearch   `config:"elasticsearch" json:"elasticsearch" yaml:"elasticsearch"`
+	Requires        *Requires        `config:"requires,omitempty" json:"requires,omitempty" yaml:"requires,omitempty"`
 }
 
 type PackageDirNameAndManifest struct {
@@ -234,22 +255,31 @@ type ManifestMappings struct {
 }
 
 type Elasticsearch struct {
-	IndexTemplate *ManifestIndexTemplate `config:"index_template" json:"index_template" yaml:"index_template"`
-	SourceMode    string                 `config:"source_mode" json:"source_mode" yaml:"source_mode"`
-	IndexMode     string                 `config:"index_mode" json:"index_mode" yaml:"index_mode"`
+	IndexTemplate    *ManifestIndexTemplate   `config:"index_template" json:"index_template" yaml:"index_template"`
+	SourceMode       string                   `config:"source_mode" json:"source_mode" yaml:"source_mode"`
+	IndexMode        string                   `config:"index_mode" json:"index_mode" yaml:"index_mode"`
+	Privileges       *ElasticsearchPrivileges `config:"privileges" json:"privileges" yaml:"privileges"`
+	DynamicDataset   bool                     `config:"dynamic_dataset" json:"dynamic_dataset" yaml:"dynamic_dataset"`
+	DynamicNamespace bool                     `config:"dynamic_namespace" json:"dynamic_namespace" yaml:"dynamic_namespace"`
+}
+
+// ElasticsearchPrivileges contains the Elasticsearch privileges required by a data stream.
+type ElasticsearchPrivileges struct {
+	Indices []string `config:"indices" json:"indices" yaml:"indices"`
 }
 
 // DataStreamManifest represents the structure of a data stream's manifest
 type DataStreamManifest struct {
-	Name          string         `config:"name" json:"name" yaml:"name"`
-	Title         string         `config:"title" json:"title" yaml:"title"`
-	Type          string         `config:"type" json:"type" yaml:"type"`
-	Dataset       string         `config:"dataset" json:"dataset" yaml:"dataset"`
-	Hidden        bool           `config:"hidden" json:"hidden" yaml:"hidden"`
-	Release       string         `config:"release" json:"release" yaml:"release"`
-	Elasticsearch *Elasticsearch `config:"elasticsearch" json:"elasticsearch" yaml:"elasticsearch"`
-	Streams       []Stream       `config:"streams" json:"streams" yaml:"streams"`
-	Agent         Agent          `config:"agent" json:"agent" yaml:"agent"`
+	Name            string         `config:"name" json:"name" yaml:"name"`
+	Title           string         `config:"title" json:"title" yaml:"title"`
+	Type            string         `config:"type" json:"type" yaml:"type"`
+	Dataset         string         `config:"dataset" json:"dataset" yaml:"dataset"`
+	DatasetIsPrefix bool           `config:"dataset_is_prefix" json:"dataset_is_prefix" yaml:"dataset_is_prefix"`
+	Hidden          bool           `config:"hidden" json:"hidden" yaml:"hidden"`
+	Release         string         `config:"release" json:"release" yaml:"release"`
+	Elasticsearch   *Elasticsearch `config:"elasticsearch" json:"elasticsearch" yaml:"elasticsearch"`
+	Streams         []Stream       `config:"streams" json:"streams" yaml:"streams"`
+	Agent           Agent          `config:"agent" json:"agent" yaml:"agent"`
 }
 
 // Transform contains information about a transform included in a package.
@@ -0,0 +1,254 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package recording
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/dnaeon/go-vcr.v3/cassette"
+	"gopkg.in/dnaeon/go-vcr.v3/recorder"
+
+	"github.com/elastic/elastic-package/internal/elasticsearch"
+	"github.com/elastic/elastic-package/internal/kibana"
+)
+
+const (
+	elasticsearchCassette = "elasticsearch"
+	kibanaCassette        = "kibana"
+
+	// replayElasticsearchAddress and replayKibanaAddress are the addresses used by
+	// clients when replaying, they are never reached as no request goes to the network.
+	replayElasticsearchAddress = "https://127.0.0.1:9200"
+	replayKibanaAddress        = "https://127.0.0.1:5601"
+)
+
+// Mode is the operating mode of a recorder.
+type Mode int
+
+const (
+	// ModeRecord forwards requests to the stack and records the interactions.
+	ModeRecord Mode = iota
+
+	// ModeReplay replies requests with recorded interactions, without reaching the stack.
+	ModeReplay
+)
+
+// numbersRegexp matches sequences of digits, these are used in names of resources
+// created by tests to make them unique, such as pipelines or policies.
+var numbersRegexp = regexp.MustCompile(`[0-9]+`)
+
+// volatileBodyFields are fields of request bodies whose values change on every run.
+var volatileBodyFields = []string{"@timestamp", "event.ingested", "created_at", "updated_at"}
+
+// Recorder records interactions with the Elastic stack in cassettes stored in a
+// directory, or replays them from there.
+type Recorder struct {
+	dir  string
+	mode Mode
+
+	recorders []*recorder.Recorder
+}
+
+// NewRecorder creates a recorder that uses the cassettes in the given directory.
+func NewRecorder(dir string, mode Mode) *Recorder {
+	return &Recorder{
+		dir:  dir,
+		mode: mode,
+	}
+}
+
+// Replaying returns true if the recorder replays interactions, so there is no need
+// for a running stack.
+func (r *Recorder) Replaying() bool {
+	return r.mode == ModeReplay
+}
+
+// ElasticsearchClientOptions returns the options needed to create an Elasticsearch client
+// whose interactions are recorded or replayed.
+func (r *Recorder) ElasticsearchClientOptions() ([]elasticsearch.ClientOption, error) {
+	rec, err := r.newRecorder(elasticsearchCassette)
+	if err != nil {
+		return nil, err
+	}
+
+	options := []elasticsearch.ClientOption{
+		elasticsearch.OptionWithTransportSetup(func(transport http.RoundTripper) http.RoundTripper {
+			rec.SetRealTransport(transport)
+			return rec
+		}),
+	}
+	if r.Replaying() {
+		options = append(options, elasticsearch.OptionWithAddress(replayElasticsearchAddress))
+	}
+	return options, nil
+}
+
+// KibanaClientOptions returns the options needed to create a Kibana client whose
+// interactions are recorded or replayed.
+func (r *Recorder) KibanaClientOptions() ([]kibana.ClientOption, error) {
+	rec, err := r.newRecorder(kibanaCassette)
+	if err != nil {
+		return nil, err
+	}
+
+	options := []kibana.ClientOption{
+		kibana.HTTPClientSetup(func(client *http.Client) *http.Client {
+			rec.SetRealTransport(client.Transport)
+			client.Transport = rec
+			return client
+		}),
+	}
+	if r.Replaying() {
+		options = append(options,
+			kibana.Address(replayKibanaAddress),
+			kibana.RetryMax(0),
+		)
+	}
+	return options, nil
+}
+
+// Stop stops the recorder. When recording, this is when cassettes are stored.
+func (r *Recorder) Stop() error {
+	var errs []error
+	for _, rec := range r.recorders {
+		err := rec.Stop()
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r *Recorder) newRecorder(name string) (*recorder.Recorder, error) {
+	options := recorder.Options{
+		CassetteName:       filepath.Join(r.dir, name),
+		Mode:               recorder.ModeRecordOnly,
+		SkipRequestLatency: true,
+	}
+	if r.Replaying() {
+		options.Mode = recorder.ModeReplayOnly
+	} else {
+		err := os.MkdirAll(r.dir, 0755)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create directory for recordings: %w", err)
+		}
+	}
+
+	rec, err := recorder.NewWithOptions(&options)
+	if errors.Is(err, cassette.ErrCassetteNotFound) {
+		return nil, fmt.Errorf("no recording found for %s in %s", name, r.dir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recorder for %s: %w", name, err)
+	}
+	rec.SetMatcher(requestMatcher)
+	rec.AddHook(redactCredentials, recorder.BeforeSaveHook)
+
+	r.recorders = append(r.recorders, rec)
+	return rec, nil
+}
+
+// requestMatcher matches requests by method, path and body, ignoring the address of the stack,
+// the numbers used to create unique resource names, and volatile fields in the body.
+func requestMatcher(r *http.Request, i cassette.Request) bool {
+	if r.Method != i.Method {
+		return false
+	}
+	recorded, err := url.Parse(i.URL)
+	if err != nil {
+		return false
+	}
+	if normalizeRequestURI(r.URL) != normalizeRequestURI(recorded) {
+		return false
+	}
+	body, err := requestBody(r)
+	if err != nil {
+		return false
+	}
+	return normalizeRequestBody(body) == normalizeRequestBody(i.Body)
+}
+
+func normalizeRequestURI(u *url.URL) string {
+	return numbersRegexp.ReplaceAllString(u.RequestURI(), "0")
+}
+
+// requestBody reads the body of the request, and restores it so it can be read again.
+func requestBody(r *http.Request) (string, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return "", nil
+	}
+	body, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		return "", err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return string(body), nil
+}
+
+// normalizeRequestBody returns the body in a canonical form, so equivalent bodies can be compared.
+// JSON documents, or sequences of them as in bulk requests, are encoded again with sorted keys and
+// without volatile fields. As in paths, numbers in strings are ignored.
+func normalizeRequestBody(body string) string {
+	var documents []string
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+	for {
+		var document any
+		err := decoder.Decode(&document)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			// Not JSON, compare the whole body.
+			return numbersRegexp.ReplaceAllString(body, "0")
+		}
+		d, err := json.Marshal(normalizeJSONValue(document))
+		if err != nil {
+			return numbersRegexp.ReplaceAllString(body, "0")
+		}
+		documents = append(documents, string(d))
+	}
+	return strings.Join(documents, "\n")
+}
+
+func normalizeJSONValue(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for _, field := range volatileBodyFields {
+			delete(value, field)
+		}
+		for k, v := range value {
+			value[k] = normalizeJSONValue(v)
+		}
+		return value
+	case []any:
+		for i, v := range value {
+			value[i] = normalizeJSONValue(v)
+		}
+		return value
+	case string:
+		return numbersRegexp.ReplaceAllString(value, "0")
+	default:
+		return value
+	}
+}
+
+// redactCredentials removes credentials from the recorded requests, they are
+// not needed to replay them.
+func redactCredentials(i *cassette.Interaction) error {
+	i.Request.Headers.Del("Authorization")
+	return nil
+}
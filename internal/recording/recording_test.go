@@ -0,0 +1,172 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package recording
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/dnaeon/go-vcr.v3/cassette"
+
+	"github.com/elastic/elastic-package/internal/elasticsearch"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	dir := t.TempDir()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"cluster_name":"elasticsearch","version":{"number":"8.17.0","build_flavor":"default"}}`))
+	}))
+
+	recorder := NewRecorder(dir, ModeRecord)
+	options, err := recorder.ElasticsearchClientOptions()
+	require.NoError(t, err)
+	options = append(options,
+		elasticsearch.OptionWithAddress(server.URL),
+		elasticsearch.OptionWithUsername("elastic"),
+		elasticsearch.OptionWithPassword("changeme"),
+	)
+	client, err := elasticsearch.NewClient(options...)
+	require.NoError(t, err)
+
+	info, err := client.Info(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "8.17.0", info.Version.Number)
+	require.NoError(t, recorder.Stop())
+	server.Close()
+
+	recorded, err := os.ReadFile(filepath.Join(dir, elasticsearchCassette+".yaml"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(recorded), "Authorization")
+
+	recorder = NewRecorder(dir, ModeReplay)
+	options, err = recorder.ElasticsearchClientOptions()
+	require.NoError(t, err)
+	client, err = elasticsearch.NewClient(options...)
+	require.NoError(t, err)
+
+	info, err = client.Info(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "8.17.0", info.Version.Number)
+
+	// Interactions are replayed only once.
+	_, err = client.Info(context.Background())
+	assert.Error(t, err)
+	require.NoError(t, recorder.Stop())
+}
+
+func TestReplayWithoutRecordings(t *testing.T) {
+	recorder := NewRecorder(t.TempDir(), ModeReplay)
+	_, err := recorder.KibanaClientOptions()
+	assert.Error(t, err)
+}
+
+func TestRequestMatcher(t *testing.T) {
+	cases := []struct {
+		recorded string
+		request  string
+		match    bool
+	}{
+		{
+			recorded: "https://127.0.0.1:9200/_ingest/pipeline/logs-apache.access-1.2.3-1712345678901234567",
+			request:  "https://elasticsearch:9200/_ingest/pipeline/logs-apache.access-1.2.3-1798765432109876543",
+			match:    true,
+		},
+		{
+			recorded: "https://127.0.0.1:5601/api/fleet/agent_policies?kuery=name:test-12345",
+			request:  "https://127.0.0.1:5601/api/fleet/agent_policies?kuery=name:test-67890",
+			match:    true,
+		},
+		{
+			recorded: "https://127.0.0.1:9200/_ingest/pipeline/logs-apache.access-1.2.3-1",
+			request:  "https://127.0.0.1:9200/_ingest/pipeline/logs-apache.error-1.2.3-1",
+			match:    false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.request, func(t *testing.T) {
+			u, err := url.Parse(c.request)
+			require.NoError(t, err)
+			r := &http.Request{Method: http.MethodGet, URL: u}
+			recorded := cassette.Request{Method: http.MethodGet, URL: c.recorded}
+			assert.Equal(t, c.match, requestMatcher(r, recorded))
+
+			recorded = cassette.Request{Method: http.MethodPost, URL: c.recorded}
+			assert.False(t, requestMatcher(r, recorded))
+		})
+	}
+}
+
+func TestRequestMatcherBody(t *testing.T) {
+	cases := []struct {
+		title    string
+		recorded string
+		request  string
+		match    bool
+	}{
+		{
+			title:    "same body",
+			recorded: `{"name":"test-12345","namespace":"ep"}`,
+			request:  `{"namespace": "ep", "name": "test-67890"}`,
+			match:    true,
+		},
+		{
+			title:    "volatile fields",
+			recorded: `{"docs":[{"_source":{"@timestamp":"2024-01-01T00:00:00Z","message":"foo"}}]}`,
+			request:  `{"docs":[{"_source":{"@timestamp":"2025-06-30T12:34:56Z","message":"foo"}}]}`,
+			match:    true,
+		},
+		{
+			title:    "bulk request",
+			recorded: "{\"create\":{}}\n{\"message\":\"foo\",\"@timestamp\":\"2024-01-01T00:00:00Z\"}\n",
+			request:  "{\"create\":{}}\n{\"@timestamp\":\"2025-01-01T00:00:00Z\",\"message\":\"foo\"}\n",
+			match:    true,
+		},
+		{
+			title:    "different body",
+			recorded: `{"name":"test","namespace":"ep"}`,
+			request:  `{"name":"test","namespace":"default"}`,
+			match:    false,
+		},
+		{
+			title:    "not JSON",
+			recorded: "foo=1",
+			request:  "foo=2",
+			match:    true,
+		},
+		{
+			title:    "missing body",
+			recorded: `{"name":"test"}`,
+			request:  "",
+			match:    false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			r, err := http.NewRequest(http.MethodPost, "https://127.0.0.1:5601/api/fleet/agent_policies", strings.NewReader(c.request))
+			require.NoError(t, err)
+			recorded := cassette.Request{Method: http.MethodPost, URL: "https://127.0.0.1:5601/api/fleet/agent_policies", Body: c.recorded}
+			assert.Equal(t, c.match, requestMatcher(r, recorded))
+
+			// The body can still be read after matching.
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			assert.Equal(t, c.request, string(body))
+		})
+	}
+}
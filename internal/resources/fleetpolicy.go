@@ -142,7 +142,7 @@ func (f *FleetAgentPolicy) Create(ctx resource.Context) error {
 	f.ID = policy.ID
 
 	for _, packagePolicy := range f.PackagePolicies {
-		policy, err := createPackagePolicy(*f, packagePolicy)
+		policy, err := BuildPackagePolicy(*f, packagePolicy)
 		if err != nil {
 			return fmt.Errorf("could not prepare package policy: %w", err)
 		}
@@ -155,7 +155,8 @@ func (f *FleetAgentPolicy) Create(ctx resource.Context) error {
 	return nil
 }
 
-func createPackagePolicy(policy FleetAgentPolicy, packagePolicy FleetPackagePolicy) (*kibana.PackageDataStream, error) {
+// BuildPackagePolicy builds the request used to add a package policy to an agent policy in Fleet.
+func BuildPackagePolicy(policy FleetAgentPolicy, packagePolicy FleetPackagePolicy) (*kibana.PackageDataStream, error) {
 	manifest, err := packages.ReadPackageManifestFromPackageRoot(packagePolicy.PackageRoot)
 	if err != nil {
 		return nil, fmt.Errorf("could not read package manifest at %s: %w", packagePolicy.PackageRoot, err)
@@ -236,8 +237,8 @@ func createIntegrationPackagePolicy(policy FleetAgentPolicy, manifest packages.P
 		ds.Inputs[0].Vars = setKibanaVariables(input.Vars, common.MapStr(packagePolicy.Vars))
 	}
 
-	// Add package-level vars
-	ds.Vars = setKibanaVariables(manifest.Vars, common.MapStr(packagePolicy.Vars))
+	// Add package-level vars, including the ones of the policy template, as Fleet does.
+	ds.Vars = setKibanaVariables(slices.Concat(manifest.Vars, policyTemplate.Vars), common.MapStr(packagePolicy.Vars))
 
 	return &ds, nil
 }
@@ -126,6 +126,16 @@ func generateBaseFileCoverageReport(root *os.Root, packageName, path, format str
 }
 
 func generateBaseCoberturaFileCoverageReport(root *os.Root, packageName, path string, covered bool) (*CoberturaCoverage, error) {
+	lines, err := countFileLines(path)
+	if err != nil {
+		return nil, err
+	}
+	return newCoberturaFileCoverageReport(root, packageName, path, lines, func(int) bool { return covered })
+}
+
+// newCoberturaFileCoverageReport generates a coverage report for the given number of lines of a file,
+// isCovered is used to know if each line is covered.
+func newCoberturaFileCoverageReport(root *os.Root, packageName, path string, lines int, isCovered func(line int) bool) (*CoberturaCoverage, error) {
 	coveragePath, err := filepath.Rel(root.Name(), path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to obtain path inside repository for %s", path)
@@ -153,34 +163,34 @@ func generateBaseCoberturaFileCoverageReport(root *os.Root, packageName, path st
 		Timestamp: time.Now().UnixNano(),
 	}
 
-	f, err := os.Open(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %v", err)
-	}
-	defer f.Close()
-
-	hits := int64(0)
-	if covered {
-		hits = 1
-	}
-	lines, err := countReaderLines(f)
-	if err != nil {
-		return nil, fmt.Errorf("failed to count lines in file: %w", err)
-	}
 	for i := range lines {
+		hits := int64(0)
+		if isCovered(i + 1) {
+			hits = 1
+		}
 		line := CoberturaLine{
 			Number: i + 1,
 			Hits:   hits,
 		}
 		class.Lines = append(class.Lines, &line)
+		coverage.LinesCovered += hits
 	}
 	coverage.LinesValid = int64(lines)
-	coverage.LinesCovered = int64(lines) * hits
 
 	return &coverage, nil
 }
 
 func generateBaseGenericFileCoverageReport(root *os.Root, _, path string, covered bool) (*GenericCoverage, error) {
+	lines, err := countFileLines(path)
+	if err != nil {
+		return nil, err
+	}
+	return newGenericFileCoverageReport(root, path, lines, func(int) bool { return covered })
+}
+
+// newGenericFileCoverageReport generates a coverage report for the given number of lines of a file,
+// isCovered is used to know if each line is covered.
+func newGenericFileCoverageReport(root *os.Root, path string, lines int, isCovered func(line int) bool) (*GenericCoverage, error) {
 	coveragePath, err := filepath.Rel(root.Name(), path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to obtain path inside repository for %s", path)
@@ -197,20 +207,10 @@ func generateBaseGenericFileCoverageReport(root *os.Root, _, path string, covere
 		},
 	}
 
-	f, err := os.Open(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %v", err)
-	}
-	defer f.Close()
-
-	lines, err := countReaderLines(f)
-	if err != nil {
-		return nil, fmt.Errorf("failed to count lines in file: %w", err)
-	}
 	for i := range lines {
 		line := GenericLine{
 			LineNumber: int64(i) + 1,
-			Covered:    covered,
+			Covered:    isCovered(i + 1),
 		}
 		file.Lines = append(file.Lines, &line)
 	}
@@ -218,6 +218,20 @@ func generateBaseGenericFileCoverageReport(root *os.Root, _, path string, covere
 	return &coverage, nil
 }
 
+func countFileLines(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open file: %v", err)
+	}
+	defer f.Close()
+
+	lines, err := countReaderLines(f)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count lines in file: %w", err)
+	}
+	return lines, nil
+}
+
 func countReaderLines(r io.Reader) (int, error) {
 	count := 0
 	buffered := bufio.NewReader(r)
@@ -0,0 +1,65 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package testrunner
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/elastic/elastic-package/internal/fields"
+	"github.com/elastic/elastic-package/internal/files"
+)
+
+// GenerateFieldsUsageCoverageReport generates a coverage report for the fields files in the
+// given directory, where the lines defining fields seen in validated documents are covered.
+// Only files in the directory are considered, linked files are ignored.
+func GenerateFieldsUsageCoverageReport(packageName string, usage *fields.FieldsUsage, fieldsDir, format string) (CoverageReport, error) {
+	paths, err := filepath.Glob(filepath.Join(fieldsDir, "*.yml"))
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := files.FindRepositoryRoot()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find repository root directory: %w", err)
+	}
+	defer root.Close()
+
+	var coverage CoverageReport
+	for _, path := range paths {
+		lines, err := countFileLines(path)
+		if err != nil {
+			return nil, err
+		}
+		seenLines, err := usage.SeenLines(fieldsDir, path, lines)
+		if err != nil {
+			return nil, fmt.Errorf("failed to obtain usage of fields file: %w", err)
+		}
+		isCovered := func(line int) bool { return seenLines[line] }
+
+		var fileCoverage CoverageReport
+		switch format {
+		case "cobertura":
+			fileCoverage, err = newCoberturaFileCoverageReport(root, packageName, path, lines, isCovered)
+		case "generic":
+			fileCoverage, err = newGenericFileCoverageReport(root, path, lines, isCovered)
+		default:
+			return nil, fmt.Errorf("unknwon coverage format %s", format)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate coverage for \"%s\": %w", path, err)
+		}
+
+		if coverage == nil {
+			coverage = fileCoverage
+			continue
+		}
+		err = coverage.Merge(fileCoverage)
+		if err != nil {
+			return nil, fmt.Errorf("cannot merge coverages: %w", err)
+		}
+	}
+	return coverage, nil
+}
@@ -0,0 +1,247 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package asset
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/elastic/elastic-package/internal/kibana"
+	"github.com/elastic/elastic-package/internal/packages"
+	"github.com/elastic/elastic-package/internal/testrunner"
+)
+
+// healthCheckedAssetTypes are the types of Kibana assets loaded after installation
+// to check that they can be rendered.
+var healthCheckedAssetTypes = []packages.AssetType{"dashboard", "visualization", "lens", "map"}
+
+// dataViewType is the saved object type of data views.
+const dataViewType = "index-pattern"
+
+type savedObjectKey struct {
+	Type string
+	ID   string
+}
+
+// savedObjectsHealthResults loads the installed dashboards and visualizations
+// through the saved objects API, and checks that they don't have broken
+// references, missing data views or migration issues.
+func (r *tester) savedObjectsHealthResults(ctx context.Context, expectedAssets, installedAssets []packages.Asset) []testrunner.TestResult {
+	var assets []packages.Asset
+	for _, e := range expectedAssets {
+		if slices.Contains(healthCheckedAssetTypes, e.Type) && findActualAsset(installedAssets, e) {
+			assets = append(assets, e)
+		}
+	}
+	if len(assets) == 0 {
+		return nil
+	}
+
+	composers := make([]*testrunner.ResultComposer, len(assets))
+	for i, asset := range assets {
+		composers[i] = testrunner.NewResultComposer(testrunner.TestResult{
+			Name:       fmt.Sprintf("%s %s is healthy", asset.Type, asset.ID),
+			Package:    r.testFolder.Package,
+			DataStream: asset.DataStream,
+			TestType:   TestType,
+		})
+	}
+	withError := func(err error) []testrunner.TestResult {
+		var results []testrunner.TestResult
+		for _, rc := range composers {
+			tr, _ := rc.WithError(err)
+			results = append(results, tr...)
+		}
+		return results
+	}
+
+	objects, err := r.bulkGetSavedObjects(ctx, assets)
+	if err != nil {
+		return withError(fmt.Errorf("could not load saved objects: %w", err))
+	}
+
+	var references []packages.Asset
+	for _, object := range objects {
+		for _, ref := range object.References {
+			if _, found := objects[savedObjectKey{Type: ref.Type, ID: ref.ID}]; found {
+				continue
+			}
+			asset := packages.Asset{Type: packages.AssetType(ref.Type), ID: ref.ID}
+			if !slices.Contains(references, asset) {
+				references = append(references, asset)
+			}
+		}
+	}
+	referenced, err := r.bulkGetSavedObjects(ctx, references)
+	if err != nil {
+		return withError(fmt.Errorf("could not load referenced saved objects: %w", err))
+	}
+	for key, object := range objects {
+		referenced[key] = object
+	}
+
+	kibanaVersion, err := r.kibanaVersion()
+	if err != nil {
+		return withError(err)
+	}
+
+	var results []testrunner.TestResult
+	for i, asset := range assets {
+		var issues []string
+		if asset.SourcePath != "" {
+			source, err := readSavedObject(asset.SourcePath)
+			if err != nil {
+				tr, _ := composers[i].WithError(err)
+				results = append(results, tr...)
+				continue
+			}
+			issues = append(issues, migrationIssues(source, kibanaVersion)...)
+		}
+		object, found := objects[savedObjectKey{Type: string(asset.Type), ID: asset.ID}]
+		if !found {
+			issues = append(issues, "saved object not returned by Kibana")
+		} else {
+			issues = append(issues, savedObjectIssues(object, referenced)...)
+		}
+
+		var tr []testrunner.TestResult
+		if len(issues) > 0 {
+			tr, _ = composers[i].WithError(testrunner.ErrTestCaseFailed{
+				Reason:  fmt.Sprintf("%s %q may fail to render", asset.Type, asset.ID),
+				Details: strings.Join(issues, "\n"),
+			})
+		} else {
+			tr, _ = composers[i].WithSuccess()
+		}
+		results = append(results, tr...)
+	}
+
+	return results
+}
+
+func (r *tester) bulkGetSavedObjects(ctx context.Context, assets []packages.Asset) (map[savedObjectKey]kibana.SavedObject, error) {
+	request := make([]kibana.BulkGetSavedObjectsRequestObject, len(assets))
+	for i, asset := range assets {
+		request[i] = kibana.BulkGetSavedObjectsRequestObject{ID: asset.ID, Type: string(asset.Type)}
+	}
+	objects, err := r.kibanaClient.BulkGetSavedObjects(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[savedObjectKey]kibana.SavedObject, len(objects))
+	for _, object := range objects {
+		result[savedObjectKey{Type: object.Type, ID: object.ID}] = object
+	}
+	return result, nil
+}
+
+func (r *tester) kibanaVersion() (*semver.Version, error) {
+	versionInfo, err := r.kibanaClient.Version()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get Kibana version: %w", err)
+	}
+	if versionInfo.Number == "" {
+		// Managed Kibana instances may not report their version.
+		return nil, nil
+	}
+	version, err := semver.NewVersion(versionInfo.Number)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse Kibana version (%s): %w", versionInfo.Number, err)
+	}
+	return version, nil
+}
+
+func readSavedObject(path string) (kibana.SavedObject, error) {
+	var object kibana.SavedObject
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return object, fmt.Errorf("reading saved object failed: %w", err)
+	}
+	err = json.Unmarshal(content, &object)
+	if err != nil {
+		return object, fmt.Errorf("unmarshalling saved object failed (path: %s): %w", path, err)
+	}
+	return object, nil
+}
+
+// savedObjectIssues returns the issues found in an installed saved object: errors
+// returned by Kibana when loading it, like failed migrations, and references to
+// saved objects that don't exist or cannot be loaded.
+func savedObjectIssues(object kibana.SavedObject, objects map[savedObjectKey]kibana.SavedObject) []string {
+	if object.Error != nil {
+		return []string{fmt.Sprintf("saved object cannot be loaded: %s", object.Error)}
+	}
+
+	var issues []string
+	for _, ref := range object.References {
+		target, found := objects[savedObjectKey{Type: ref.Type, ID: ref.ID}]
+		if found && target.Error == nil {
+			continue
+		}
+
+		reason := "not found"
+		if found {
+			reason = target.Error.String()
+		}
+		if ref.Type == dataViewType {
+			issues = append(issues, fmt.Sprintf("missing data view %q referenced by %q: %s", ref.ID, ref.Name, reason))
+			continue
+		}
+		issues = append(issues, fmt.Sprintf("broken reference %q to %s %q: %s", ref.Name, ref.Type, ref.ID, reason))
+	}
+	return issues
+}
+
+// minModelVersionMajor is the major of the type migration versions of saved object types
+// migrated with model versions. These versions are not related to Kibana versions.
+const minModelVersionMajor = 10
+
+// migrationIssues returns the issues found in the migration versions of a saved
+// object as defined in the package. Objects exported from a newer version of
+// Kibana cannot be migrated by the running one.
+func migrationIssues(object kibana.SavedObject, kibanaVersion *semver.Version) []string {
+	if kibanaVersion == nil {
+		return nil
+	}
+	// Ignore prerelease information, so snapshots can load objects exported from the same version.
+	current, _ := kibanaVersion.SetPrerelease("")
+
+	typeVersions := []string{object.TypeMigrationVersion}
+	for _, version := range object.MigrationVersion {
+		typeVersions = append(typeVersions, version)
+	}
+
+	var issues []string
+	check := func(v string, isTypeVersion bool) {
+		if v == "" {
+			return
+		}
+		version, err := semver.NewVersion(v)
+		if err != nil {
+			issues = append(issues, fmt.Sprintf("invalid migration version %q: %s", v, err))
+			return
+		}
+		if isTypeVersion && version.Major() >= minModelVersionMajor {
+			// Model version of the type, it cannot be compared with the Kibana version.
+			return
+		}
+		if version.GreaterThan(&current) {
+			issues = append(issues, fmt.Sprintf("saved object migration version %s is newer than Kibana version %s", version, kibanaVersion))
+		}
+	}
+	check(object.CoreMigrationVersion, false)
+	for _, v := range typeVersions {
+		check(v, true)
+	}
+	slices.Sort(issues)
+	return slices.Compact(issues)
+}
@@ -0,0 +1,96 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package asset
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/elastic-package/internal/kibana"
+)
+
+func TestSavedObjectIssues(t *testing.T) {
+	objects := map[savedObjectKey]kibana.SavedObject{
+		{Type: "index-pattern", ID: "logs-*"}: {Type: "index-pattern", ID: "logs-*"},
+		{Type: "visualization", ID: "vis-1"}:  {Type: "visualization", ID: "vis-1"},
+		{Type: "visualization", ID: "vis-2"}: {
+			Type:  "visualization",
+			ID:    "vis-2",
+			Error: &kibana.SavedObjectError{StatusCode: 404, Error: "Not Found", Message: "Saved object [visualization/vis-2] not found"},
+		},
+	}
+
+	cases := []struct {
+		title    string
+		object   kibana.SavedObject
+		expected []string
+	}{
+		{
+			title: "healthy",
+			object: kibana.SavedObject{
+				References: []kibana.SavedObjectReference{
+					{Name: "panel_0", Type: "visualization", ID: "vis-1"},
+					{Name: "kibanaSavedObjectMeta.searchSourceJSON.index", Type: "index-pattern", ID: "logs-*"},
+				},
+			},
+		},
+		{
+			title: "object with errors",
+			object: kibana.SavedObject{
+				Error: &kibana.SavedObjectError{StatusCode: 500, Error: "Internal Server Error", Message: "migration failed"},
+			},
+			expected: []string{"saved object cannot be loaded: migration failed (status code: 500)"},
+		},
+		{
+			title: "broken references",
+			object: kibana.SavedObject{
+				References: []kibana.SavedObjectReference{
+					{Name: "panel_0", Type: "visualization", ID: "vis-2"},
+					{Name: "panel_1", Type: "lens", ID: "lens-1"},
+					{Name: "kibanaSavedObjectMeta.searchSourceJSON.index", Type: "index-pattern", ID: "metrics-*"},
+				},
+			},
+			expected: []string{
+				`broken reference "panel_0" to visualization "vis-2": Saved object [visualization/vis-2] not found (status code: 404)`,
+				`broken reference "panel_1" to lens "lens-1": not found`,
+				`missing data view "metrics-*" referenced by "kibanaSavedObjectMeta.searchSourceJSON.index": not found`,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			assert.Equal(t, c.expected, savedObjectIssues(c.object, objects))
+		})
+	}
+}
+
+func TestMigrationIssues(t *testing.T) {
+	object := kibana.SavedObject{
+		TypeMigrationVersion: "8.9.0",
+		CoreMigrationVersion: "8.8.0",
+		MigrationVersion:     map[string]string{"dashboard": "8.9.0"},
+	}
+
+	assert.Empty(t, migrationIssues(object, nil))
+	assert.Empty(t, migrationIssues(object, semver.MustParse("8.9.0-SNAPSHOT")))
+	assert.Empty(t, migrationIssues(object, semver.MustParse("8.15.0")))
+	assert.Equal(t,
+		[]string{"saved object migration version 8.9.0 is newer than Kibana version 8.8.2"},
+		migrationIssues(object, semver.MustParse("8.8.2")),
+	)
+
+	modelVersionObject := kibana.SavedObject{
+		TypeMigrationVersion: "10.1.0",
+		CoreMigrationVersion: "8.8.0",
+	}
+	assert.Empty(t, migrationIssues(modelVersionObject, semver.MustParse("8.15.0")))
+	assert.Equal(t,
+		[]string{"saved object migration version 8.8.0 is newer than Kibana version 8.7.0"},
+		migrationIssues(modelVersionObject, semver.MustParse("8.7.0")),
+	)
+}
@@ -185,6 +185,8 @@ func (r *tester) run(ctx context.Context) ([]testrunner.TestResult, error) {
 		results = append(results, result)
 	}
 
+	results = append(results, r.savedObjectsHealthResults(ctx, expectedAssets, installedAssets)...)
+
 	return results, nil
 }
 
@@ -40,6 +40,8 @@ type runner struct {
 	repositoryRoot *os.Root
 
 	schemaURLs fields.SchemaURLs
+
+	fieldsUsage *fields.FieldsUsage
 }
 
 type PipelineTestRunnerOptions struct {
@@ -55,6 +57,9 @@ type PipelineTestRunnerOptions struct {
 	GlobalTestConfig   testrunner.GlobalRunnerTestConfig
 	RepositoryRoot     *os.Root
 	SchemaURLs         fields.SchemaURLs
+
+	// FieldsUsage, if set, aggregates the fields seen in the validated documents.
+	FieldsUsage *fields.FieldsUsage
 }
 
 func NewPipelineTestRunner(options PipelineTestRunnerOptions) *runner {
@@ -71,6 +76,7 @@ func NewPipelineTestRunner(options PipelineTestRunnerOptions) *runner {
 		globalTestConfig:   options.GlobalTestConfig,
 		repositoryRoot:     options.RepositoryRoot,
 		schemaURLs:         options.SchemaURLs,
+		fieldsUsage:        options.FieldsUsage,
 	}
 	return &runner
 }
@@ -149,6 +155,7 @@ func (r *runner) GetTests(ctx context.Context) ([]testrunner.Tester, error) {
 				GlobalTestConfig:   r.globalTestConfig,
 				RepositoryRoot:     r.repositoryRoot,
 				SchemaURLs:         r.schemaURLs,
+				FieldsUsage:        r.fieldsUsage,
 			})
 			if err != nil {
 				return nil, fmt.Errorf("failed to create pipeline tester: %w", err)
@@ -57,6 +57,8 @@ type tester struct {
 	repositoryRoot *os.Root
 
 	schemaURLs fields.SchemaURLs
+
+	fieldsUsage *fields.FieldsUsage
 }
 
 type PipelineTesterOptions struct {
@@ -72,6 +74,7 @@ type PipelineTesterOptions struct {
 	GlobalTestConfig   testrunner.GlobalRunnerTestConfig
 	RepositoryRoot     *os.Root
 	SchemaURLs         fields.SchemaURLs
+	FieldsUsage        *fields.FieldsUsage
 }
 
 func NewPipelineTester(options PipelineTesterOptions) (*tester, error) {
@@ -92,6 +95,7 @@ func NewPipelineTester(options PipelineTesterOptions) (*tester, error) {
 		globalTestConfig:   options.GlobalTestConfig,
 		repositoryRoot:     options.RepositoryRoot,
 		schemaURLs:         options.SchemaURLs,
+		fieldsUsage:        options.FieldsUsage,
 	}
 
 	stackConfig, err := stack.LoadConfig(r.profile)
@@ -351,6 +355,7 @@ func (r *tester) runTestCase(ctx context.Context, testCaseFile string, dsPath st
 	validatorOptions = append(slices.Clone(validatorOptions),
 		fields.WithNumericKeywordFields(tc.config.NumericKeywordFields),
 		fields.WithStringNumberFields(tc.config.StringNumberFields),
+		fields.WithSeenFieldsTracking(r.fieldsUsage != nil),
 	)
 	fieldsDir := filepath.Join(dsPath, "fields")
 	fieldsValidator, err := fields.CreateValidator(r.repositoryRoot, r.packageRoot, fieldsDir, validatorOptions...)
@@ -359,6 +364,9 @@ func (r *tester) runTestCase(ctx context.Context, testCaseFile string, dsPath st
 	}
 
 	err = r.verifyResults(testCaseFile, tc.config, result, fieldsValidator)
+	if r.fieldsUsage != nil {
+		r.fieldsUsage.Add(fieldsValidator)
+	}
 	if err != nil {
 		results, _ := rc.WithErrorf("verifying test result failed: %w", err)
 		return results, nil
@@ -375,6 +383,23 @@ func (r *tester) runTestCase(ctx context.Context, testCaseFile string, dsPath st
 		if err != nil {
 			return rc.WithErrorf("error calculating pipeline coverage: %w", err)
 		}
+
+		if r.fieldsUsage != nil {
+			fieldsCoverage, err := testrunner.GenerateFieldsUsageCoverageReport(rc.CoveragePackageName(), r.fieldsUsage, fieldsDir, r.coverageType)
+			if err != nil {
+				return rc.WithErrorf("error calculating fields coverage: %w", err)
+			}
+			switch {
+			case fieldsCoverage == nil:
+			case rc.Coverage == nil:
+				rc.Coverage = fieldsCoverage
+			default:
+				err = rc.Coverage.Merge(fieldsCoverage)
+				if err != nil {
+					return rc.WithErrorf("error merging fields coverage: %w", err)
+				}
+			}
+		}
 	}
 
 	return rc.WithSuccess()
@@ -0,0 +1,304 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package policy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/elastic/elastic-package/internal/logger"
+	"github.com/elastic/elastic-package/internal/packages"
+)
+
+const defaultTestCaseName = "default"
+
+// invalidTestNameCharsRegexp matches the characters not allowed in test case file names.
+var invalidTestNameCharsRegexp = regexp.MustCompile(`[^a-z0-9_.-]+`)
+
+// testCase is a policy test case generated from the package manifests.
+type testCase struct {
+	name   string
+	config testConfig
+}
+
+// GenerateTestConfigs writes policy test configuration files for each input of
+// each data stream in integration packages, or for each policy template in input
+// packages. A test case is generated with the default values of the variables,
+// and additional ones for each alternative value of boolean and select variables.
+// Existing files are not overwritten. It returns the paths of the files written.
+func GenerateTestConfigs(packageRoot string, dataStreams []string) ([]string, error) {
+	manifest, err := packages.ReadPackageManifestFromPackageRoot(packageRoot)
+	if err != nil {
+		return nil, fmt.Errorf("reading package manifest failed (path: %s): %w", packageRoot, err)
+	}
+
+	if manifest.Type == "input" {
+		return writeTestConfigs(filepath.Join(packageRoot, "_dev", "test", "policy"), inputPackageTestCases(manifest))
+	}
+
+	if len(dataStreams) == 0 {
+		manifestPaths, err := filepath.Glob(filepath.Join(packageRoot, "data_stream", "*", packages.DataStreamManifestFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to look for data streams: %w", err)
+		}
+		for _, path := range manifestPaths {
+			dataStreams = append(dataStreams, filepath.Base(filepath.Dir(path)))
+		}
+	}
+
+	var written []string
+	for _, dataStream := range dataStreams {
+		dsManifest, err := packages.ReadDataStreamManifestFromPackageRoot(packageRoot, dataStream)
+		if err != nil {
+			return nil, fmt.Errorf("reading data stream manifest failed: %w", err)
+		}
+		testFolder := filepath.Join(packageRoot, "data_stream", dataStream, "_dev", "test", "policy")
+		paths, err := writeTestConfigs(testFolder, dataStreamTestCases(manifest, dsManifest))
+		if err != nil {
+			return nil, err
+		}
+		written = append(written, paths...)
+	}
+	return written, nil
+}
+
+// dataStreamTestCases returns the test cases for each input of a data stream.
+func dataStreamTestCases(manifest *packages.PackageManifest, dsManifest *packages.DataStreamManifest) []testCase {
+	var inputs []string
+	for _, stream := range dsManifest.Streams {
+		if !slices.Contains(inputs, stream.Input) {
+			inputs = append(inputs, stream.Input)
+		}
+	}
+
+	var cases []testCase
+	for _, stream := range dsManifest.Streams {
+		if !slices.Contains(inputs, stream.Input) {
+			// Only the first stream of each input can be selected in tests.
+			continue
+		}
+		inputs = slices.DeleteFunc(inputs, func(input string) bool { return input == stream.Input })
+
+		var policyTemplates []packages.PolicyTemplate
+		for _, policyTemplate := range manifest.PolicyTemplates {
+			if policyTemplate.FindInputByType(stream.Input) == nil {
+				continue
+			}
+			if len(policyTemplate.DataStreams) > 0 && !slices.Contains(policyTemplate.DataStreams, dsManifest.Name) {
+				continue
+			}
+			policyTemplates = append(policyTemplates, policyTemplate)
+		}
+		if len(policyTemplates) != 1 {
+			logger.Warnf("skipping generation of policy tests for data stream %q and input %q: found %d matching policy templates, expected one",
+				dsManifest.Name, stream.Input, len(policyTemplates))
+			continue
+		}
+
+		var prefix, input string
+		if len(dsManifest.Streams) > 1 {
+			prefix = stream.Input
+			input = stream.Input
+		}
+		vars := append(slices.Clone(manifest.Vars), policyTemplates[0].FindInputByType(stream.Input).Vars...)
+		cases = append(cases, variantTestCases(prefix, input, vars, stream.Vars)...)
+	}
+	return cases
+}
+
+// inputPackageTestCases returns the test cases for each policy template of an input package.
+func inputPackageTestCases(manifest *packages.PackageManifest) []testCase {
+	var cases []testCase
+	for _, policyTemplate := range manifest.PolicyTemplates {
+		count := 0
+		for _, pt := range manifest.PolicyTemplates {
+			if pt.Input == policyTemplate.Input {
+				count++
+			}
+		}
+		if count > 1 {
+			logger.Warnf("skipping generation of policy tests for policy template %q: input %q is used by %d policy templates",
+				policyTemplate.Name, policyTemplate.Input, count)
+			continue
+		}
+
+		var prefix, input string
+		if len(manifest.PolicyTemplates) > 1 {
+			prefix = policyTemplate.Name
+			input = policyTemplate.Input
+		}
+		cases = append(cases, variantTestCases(prefix, input, policyTemplate.Vars, nil)...)
+	}
+	return cases
+}
+
+// variantTestCases returns a test case with the default values of the variables,
+// and a test case for each alternative value of boolean and select variables.
+// The names of the variant test cases include the level of the variable, `input`
+// or `ds` for data stream variables.
+func variantTestCases(prefix, input string, vars, dataStreamVars []packages.Variable) []testCase {
+	base := testConfig{Input: input}
+	base.Vars = defaultVarValues(vars)
+	base.DataStream.Vars = defaultVarValues(dataStreamVars)
+
+	cases := []testCase{{name: testCaseName(prefix, defaultTestCaseName), config: base}}
+	for _, v := range vars {
+		for _, value := range varVariants(v) {
+			config := base
+			config.Vars = maps.Clone(base.Vars)
+			if config.Vars == nil {
+				config.Vars = make(map[string]any)
+			}
+			config.Vars[v.Name] = value
+			cases = append(cases, testCase{name: testCaseName(prefix, "input", v.Name, fmt.Sprint(value)), config: config})
+		}
+	}
+	for _, v := range dataStreamVars {
+		for _, value := range varVariants(v) {
+			config := base
+			config.DataStream.Vars = maps.Clone(base.DataStream.Vars)
+			if config.DataStream.Vars == nil {
+				config.DataStream.Vars = make(map[string]any)
+			}
+			config.DataStream.Vars[v.Name] = value
+			cases = append(cases, testCase{name: testCaseName(prefix, "ds", v.Name, fmt.Sprint(value)), config: config})
+		}
+	}
+	return cases
+}
+
+// defaultVarValues returns the default values of the variables. Required variables
+// without default value get a placeholder value.
+func defaultVarValues(vars []packages.Variable) map[string]any {
+	values := make(map[string]any)
+	for _, v := range vars {
+		switch {
+		case v.Default != nil && v.Default.Value() != nil:
+			values[v.Name] = v.Default.Value()
+		case v.Required:
+			values[v.Name] = placeholderVarValue(v)
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
+
+// varVariants returns the values of boolean and select variables that are
+// different to their default values.
+func varVariants(v packages.Variable) []any {
+	var defaultValue any
+	if v.Default != nil {
+		defaultValue = v.Default.Value()
+	}
+
+	var variants []any
+	switch v.Type {
+	case "bool":
+		for _, value := range []bool{true, false} {
+			if defaultValue != value {
+				variants = append(variants, value)
+			}
+		}
+	case "select":
+		for _, option := range v.Options {
+			if defaultValue != option.Value {
+				variants = append(variants, option.Value)
+			}
+		}
+	}
+	return variants
+}
+
+func placeholderVarValue(v packages.Variable) any {
+	var value any
+	switch v.Type {
+	case "bool":
+		value = true
+	case "integer":
+		value = 1
+	case "url":
+		value = "https://localhost"
+	case "select":
+		if len(v.Options) > 0 {
+			value = v.Options[0].Value
+		} else {
+			value = "changeme"
+		}
+	default:
+		value = "changeme"
+	}
+	if v.Multi {
+		return []any{value}
+	}
+	return value
+}
+
+func testCaseName(parts ...string) string {
+	var nonEmpty []string
+	for _, part := range parts {
+		part = invalidTestNameCharsRegexp.ReplaceAllString(strings.ToLower(part), "-")
+		part = strings.Trim(part, "-")
+		if part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	return strings.Join(nonEmpty, "-")
+}
+
+// writeTestConfigs writes the configuration files of the test cases that don't exist yet.
+func writeTestConfigs(testFolder string, cases []testCase) ([]string, error) {
+	if len(cases) == 0 {
+		return nil, nil
+	}
+
+	names := make(map[string]bool)
+	for _, c := range cases {
+		if names[c.name] {
+			return nil, fmt.Errorf("more than one test case generated with name %q in %s", c.name, testFolder)
+		}
+		names[c.name] = true
+	}
+
+	err := os.MkdirAll(testFolder, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create test folder %s: %w", testFolder, err)
+	}
+
+	var written []string
+	for _, c := range cases {
+		path := filepath.Join(testFolder, fmt.Sprintf("test-%s.yml", c.name))
+		if _, err := os.Stat(path); err == nil {
+			logger.Debugf("policy test config %s already exists, skipping", path)
+			continue
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to check if %s exists: %w", path, err)
+		}
+
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		err := enc.Encode(c.config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode test config: %w", err)
+		}
+		err = os.WriteFile(path, buf.Bytes(), 0644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write test config %s: %w", path, err)
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
@@ -0,0 +1,145 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-package/internal/packages"
+)
+
+func varWithDefault(t *testing.T, v packages.Variable, value any) packages.Variable {
+	t.Helper()
+	var vv packages.VarValue
+	require.NoError(t, vv.Unpack(value))
+	v.Default = &vv
+	return v
+}
+
+func TestDataStreamTestCases(t *testing.T) {
+	manifest := packages.PackageManifest{
+		Vars: []packages.Variable{
+			{Name: "api_key", Type: "password", Required: true},
+		},
+		PolicyTemplates: []packages.PolicyTemplate{
+			{
+				Name: "logs",
+				Inputs: []packages.Input{
+					{Type: "logfile", Vars: []packages.Variable{
+						varWithDefault(t, packages.Variable{Name: "preserve_original_event", Type: "bool"}, false),
+					}},
+					{Type: "httpjson"},
+				},
+			},
+		},
+	}
+	dsManifest := packages.DataStreamManifest{
+		Name: "access",
+		Streams: []packages.Stream{
+			{
+				Input: "logfile",
+				Vars: []packages.Variable{
+					varWithDefault(t, packages.Variable{Name: "paths", Type: "text", Multi: true}, []any{"/var/log/access.log"}),
+					varWithDefault(t, packages.Variable{
+						Name:    "mode",
+						Type:    "select",
+						Options: []packages.VarOption{{Value: "tail"}, {Value: "Full Read"}},
+					}, "tail"),
+				},
+			},
+			{Input: "httpjson"},
+			{Input: "logfile"},
+		},
+	}
+
+	cases := dataStreamTestCases(&manifest, &dsManifest)
+
+	var names []string
+	for _, c := range cases {
+		names = append(names, c.name)
+	}
+	assert.Equal(t, []string{
+		"logfile-default",
+		"logfile-input-preserve_original_event-true",
+		"logfile-ds-mode-full-read",
+		"httpjson-default",
+	}, names)
+
+	assert.Equal(t, "logfile", cases[0].config.Input)
+	assert.Equal(t, map[string]any{"api_key": "changeme", "preserve_original_event": false}, cases[0].config.Vars)
+	assert.Equal(t, map[string]any{"paths": []any{"/var/log/access.log"}, "mode": "tail"}, cases[0].config.DataStream.Vars)
+
+	// Variants don't modify the default test case.
+	assert.Equal(t, true, cases[1].config.Vars["preserve_original_event"])
+	assert.Equal(t, "Full Read", cases[2].config.DataStream.Vars["mode"])
+	assert.Equal(t, "tail", cases[0].config.DataStream.Vars["mode"])
+}
+
+func TestInputPackageTestCases(t *testing.T) {
+	manifest := packages.PackageManifest{
+		Type: "input",
+		PolicyTemplates: []packages.PolicyTemplate{
+			{
+				Name:  "sql_query",
+				Input: "sql",
+				Vars: []packages.Variable{
+					{Name: "hosts", Type: "text", Multi: true, Required: true},
+					{Name: "merge_results", Type: "bool"},
+				},
+			},
+		},
+	}
+
+	cases := inputPackageTestCases(&manifest)
+	require.Len(t, cases, 3)
+	assert.Equal(t, "default", cases[0].name)
+	assert.Empty(t, cases[0].config.Input)
+	assert.Equal(t, map[string]any{"hosts": []any{"changeme"}}, cases[0].config.Vars)
+	assert.Equal(t, "input-merge_results-true", cases[1].name)
+	assert.Equal(t, "input-merge_results-false", cases[2].name)
+}
+
+func TestWriteTestConfigs(t *testing.T) {
+	testFolder := filepath.Join(t.TempDir(), "_dev", "test", "policy")
+	cases := []testCase{
+		{name: "default", config: testConfig{Vars: map[string]any{"enabled": true}}},
+		{name: "existing"},
+	}
+
+	require.NoError(t, os.MkdirAll(testFolder, 0755))
+	existingPath := filepath.Join(testFolder, "test-existing.yml")
+	require.NoError(t, os.WriteFile(existingPath, []byte("vars: {}\n"), 0644))
+
+	written, err := writeTestConfigs(testFolder, cases)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(testFolder, "test-default.yml")}, written)
+
+	config, err := readTestConfig(written[0])
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"enabled": true}, config.Vars)
+
+	d, err := os.ReadFile(existingPath)
+	require.NoError(t, err)
+	assert.Equal(t, "vars: {}\n", string(d))
+}
+
+func TestWriteTestConfigsDuplicatedNames(t *testing.T) {
+	testFolder := filepath.Join(t.TempDir(), "_dev", "test", "policy")
+	cases := []testCase{
+		{name: "default"},
+		{name: "input-enabled-true"},
+		{name: "input-enabled-true"},
+	}
+
+	_, err := writeTestConfigs(testFolder, cases)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"input-enabled-true"`)
+	assert.NoDirExists(t, testFolder)
+}
@@ -0,0 +1,490 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package policy
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/elastic/elastic-package/internal/kibana"
+	"github.com/elastic/elastic-package/internal/packages"
+	"github.com/elastic/elastic-package/internal/packages/agenttemplate"
+	"github.com/elastic/elastic-package/internal/resources"
+	"github.com/elastic/elastic-package/internal/testrunner"
+	"github.com/elastic/elastic-package/internal/testrunner/runners/system"
+)
+
+const (
+	// testPolicyNamespace is the namespace used for the agent policies created for tests.
+	testPolicyNamespace = "ep"
+
+	otelCollectorInputName = "otelcol"
+
+	// offlinePackagePolicyID is the ID used for package policies compiled offline.
+	offlinePackagePolicyID = "00000000-0000-0000-0000-000000000000"
+
+	defaultOutputName        = "default"
+	defaultStreamTemplate    = "stream.yml.hbs"
+	offlineElasticsearchHost = "https://elasticsearch:9200"
+)
+
+// defaultIndexPrivileges are the privileges granted by Fleet on the data streams
+// of a package policy, when not overridden in the data stream manifest.
+var defaultIndexPrivileges = []string{"auto_configure", "create_doc"}
+
+// otelSignals contains the settings used to route each type of OpenTelemetry
+// signal to the data stream of the package policy.
+var otelSignals = map[string]struct {
+	statementsKey string
+	context       string
+}{
+	"logs":    {statementsKey: "log_statements", context: "log"},
+	"metrics": {statementsKey: "metric_statements", context: "datapoint"},
+	"traces":  {statementsKey: "trace_statements", context: "span"},
+}
+
+// CompileTestPolicy compiles the agent policy for the policy test defined in testPath
+// without using Fleet. It follows the same composition rules as Fleet, and returns the
+// policy in the same canonical form used in the files with the expected policies.
+func CompileTestPolicy(packageRoot, packageName, dataStream, testPath string) ([]byte, error) {
+	config, err := readTestConfig(testPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read test config from %s: %w", testPath, err)
+	}
+
+	manifest, err := packages.ReadPackageManifestFromPackageRoot(packageRoot)
+	if err != nil {
+		return nil, fmt.Errorf("reading package manifest failed (path: %s): %w", packageRoot, err)
+	}
+
+	var dsManifest *packages.DataStreamManifest
+	if dataStream != "" {
+		dsManifest, err = packages.ReadDataStreamManifestFromPackageRoot(packageRoot, dataStream)
+		if err != nil {
+			return nil, fmt.Errorf("reading data stream manifest failed: %w", err)
+		}
+	}
+
+	testName := testNameFromPath(testPath)
+	agentPolicy := resources.FleetAgentPolicy{
+		ID:        testName,
+		Name:      testName,
+		Namespace: testPolicyNamespace,
+	}
+	packagePolicy := resources.FleetPackagePolicy{
+		Name:           fmt.Sprintf("%s-%s", testName, packageName),
+		PackageRoot:    packageRoot,
+		DataStreamName: dataStream,
+		InputName:      config.Input,
+		Vars:           config.Vars,
+		DataStreamVars: config.DataStream.Vars,
+	}
+	ds, err := resources.BuildPackagePolicy(agentPolicy, packagePolicy)
+	if err != nil {
+		return nil, fmt.Errorf("could not prepare package policy: %w", err)
+	}
+
+	policy, err := compileAgentPolicy(packageRoot, manifest, dsManifest, *ds)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := yaml.Marshal(policy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal policy: %w", err)
+	}
+	return cleanPolicy(d, policyEntryFilters)
+}
+
+// DumpCompiledPolicies compiles offline the policies of the policy tests of the package,
+// and writes them in outputPath. Tests can be filtered by data stream. It returns the
+// number of policies written.
+func DumpCompiledPolicies(packageRoot string, dataStreams []string, outputPath string) (int, error) {
+	manifest, err := packages.ReadPackageManifestFromPackageRoot(packageRoot)
+	if err != nil {
+		return 0, fmt.Errorf("reading package manifest failed (path: %s): %w", packageRoot, err)
+	}
+	if manifest.Type == "input" {
+		dataStreams = nil
+	}
+
+	folders, err := testrunner.FindTestFolders(packageRoot, dataStreams, TestType)
+	if err != nil {
+		return 0, fmt.Errorf("unable to determine test folder paths: %w", err)
+	}
+
+	err = os.MkdirAll(outputPath, 0755)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create output directory %s: %w", outputPath, err)
+	}
+
+	count := 0
+	for _, folder := range folders {
+		tests, err := filepath.Glob(filepath.Join(folder.Path, "test-*.yml"))
+		if err != nil {
+			return 0, fmt.Errorf("failed to look for test files in %s: %w", folder.Path, err)
+		}
+		for _, test := range tests {
+			policy, err := CompileTestPolicy(packageRoot, folder.Package, folder.DataStream, test)
+			if err != nil {
+				return 0, fmt.Errorf("failed to compile policy for %s: %w", test, err)
+			}
+
+			name := testNameFromPath(test)
+			if folder.DataStream != "" {
+				name = folder.DataStream + "-" + name
+			}
+			path := filepath.Join(outputPath, name+".yml")
+			err = os.WriteFile(path, policy, 0644)
+			if err != nil {
+				return 0, fmt.Errorf("failed to write policy %s: %w", path, err)
+			}
+			count++
+		}
+	}
+	return count, nil
+}
+
+// compileAgentPolicy composes an agent policy with a single package policy, as Fleet does.
+// Variables defined at the package, policy template, input and stream levels are merged,
+// with the most specific ones taking precedence, and used to render the handlebars templates.
+// Variables of policy templates of integration packages are package-level variables
+// in the package policy.
+func compileAgentPolicy(packageRoot string, manifest *packages.PackageManifest, dsManifest *packages.DataStreamManifest, ds kibana.PackageDataStream) (map[string]any, error) {
+	if len(ds.Inputs) != 1 || len(ds.Inputs[0].Streams) != 1 {
+		return nil, fmt.Errorf("expected a package policy with a single input and stream")
+	}
+	input := ds.Inputs[0]
+	stream := input.Streams[0]
+
+	idx := slices.IndexFunc(manifest.PolicyTemplates, func(pt packages.PolicyTemplate) bool { return pt.Name == input.PolicyTemplate })
+	if idx < 0 {
+		return nil, fmt.Errorf("policy template %q not found", input.PolicyTemplate)
+	}
+	policyTemplate := manifest.PolicyTemplates[idx]
+
+	var secrets secretReferences
+	var inputDefinition *packages.Input
+	var streamDefinitions []packages.Variable
+	var streamTemplatePath string
+	switch manifest.Type {
+	case "input":
+		streamDefinitions = policyTemplate.Vars
+		streamTemplatePath = filepath.Join(packageRoot, "agent", "input", policyTemplate.TemplatePath)
+	default:
+		if dsManifest == nil {
+			return nil, fmt.Errorf("expected data stream for integration package policy %q", ds.Name)
+		}
+		inputDefinition = policyTemplate.FindInputByType(input.Type)
+		idx := slices.IndexFunc(dsManifest.Streams, func(s packages.Stream) bool { return s.Input == input.Type })
+		if idx < 0 {
+			return nil, fmt.Errorf("stream for input %q not found in data stream %q", input.Type, dsManifest.Name)
+		}
+		streamDefinitions = dsManifest.Streams[idx].Vars
+		templatePath := dsManifest.Streams[idx].TemplatePath
+		if templatePath == "" {
+			templatePath = defaultStreamTemplate
+		}
+		streamTemplatePath = filepath.Join(packageRoot, "data_stream", dsManifest.Name, "agent", "stream", templatePath)
+	}
+
+	packageVarDefinitions := manifest.Vars
+	if manifest.Type != "input" {
+		// Variables of policy templates of input packages are stream variables.
+		packageVarDefinitions = slices.Concat(manifest.Vars, policyTemplate.Vars)
+	}
+	packageVars := secrets.templateVars(packageVarDefinitions, ds.Vars)
+	inputVars := maps.Clone(packageVars)
+	if inputDefinition != nil {
+		maps.Copy(inputVars, secrets.templateVars(inputDefinition.Vars, input.Vars))
+	}
+	streamVars := maps.Clone(inputVars)
+	maps.Copy(streamVars, secrets.templateVars(streamDefinitions, stream.Vars))
+
+	streamConfig, err := compileTemplate(streamTemplatePath, streamVars)
+	if err != nil {
+		return nil, err
+	}
+
+	dataStreamType := stream.DataStream.Type
+	dataset := stream.DataStream.Dataset
+	permissionsName := system.BuildDataStreamName(ds, policyTemplate, manifest.Type, nil)
+	if manifest.Type == "input" {
+		dataStreamType = policyTemplate.Type
+		if v, found := stream.Vars["data_stream.dataset"]; found {
+			if value, ok := v.Value.Value().(string); ok && value != "" {
+				dataset = value
+			}
+		}
+		// Agents can send data to any dataset for input packages.
+		permissionsName = fmt.Sprintf("%s-*-*", dataStreamType)
+	} else {
+		permissionsName = dataStreamPermissionsName(dsManifest, dataStreamType, dataset, ds.Namespace, permissionsName)
+	}
+	privileges := defaultIndexPrivileges
+	if dsManifest != nil && dsManifest.Elasticsearch != nil && dsManifest.Elasticsearch.Privileges != nil && len(dsManifest.Elasticsearch.Privileges.Indices) > 0 {
+		privileges = dsManifest.Elasticsearch.Privileges.Indices
+	}
+
+	policy := map[string]any{
+		"id":       ds.PolicyID,
+		"revision": 1,
+		"output_permissions": map[string]any{
+			defaultOutputName: map[string]any{
+				"_elastic_agent_checks": map[string]any{
+					"cluster": []any{"monitor"},
+				},
+				"_elastic_agent_monitoring": map[string]any{
+					"indices": []any{},
+				},
+				offlinePackagePolicyID: map[string]any{
+					"indices": []any{
+						map[string]any{
+							"names":      []any{permissionsName},
+							"privileges": toAnySlice(privileges),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if input.Type == otelCollectorInputName {
+		policy["inputs"] = []any{}
+		addOTelCollectorConfig(policy, streamConfig, stream.ID, dataStreamType, dataset, ds.Namespace)
+		policy["secret_references"] = secrets.list()
+		return policy, nil
+	}
+
+	agentInput := map[string]any{
+		"id":                fmt.Sprintf("%s-%s", input.Type, ds.Name),
+		"name":              ds.Name,
+		"revision":          1,
+		"type":              input.Type,
+		"data_stream":       map[string]any{"namespace": ds.Namespace},
+		"use_output":        defaultOutputName,
+		"package_policy_id": offlinePackagePolicyID,
+		"meta": map[string]any{
+			"package": map[string]any{
+				"name":    ds.Package.Name,
+				"version": ds.Package.Version,
+			},
+		},
+	}
+	if inputDefinition != nil && inputDefinition.TemplatePath != "" {
+		inputConfig, err := compileTemplate(filepath.Join(packageRoot, "agent", "input", inputDefinition.TemplatePath), inputVars)
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(agentInput, inputConfig)
+	}
+
+	agentStream := map[string]any{
+		"id": stream.ID,
+		"data_stream": map[string]any{
+			"dataset": dataset,
+			"type":    dataStreamType,
+		},
+	}
+	maps.Copy(agentStream, streamConfig)
+	agentInput["streams"] = []any{agentStream}
+
+	policy["inputs"] = []any{agentInput}
+	policy["secret_references"] = secrets.list()
+	return policy, nil
+}
+
+// dataStreamPermissionsName returns the index pattern the agent is granted access
+// to for a data stream, considering dynamic datasets and namespaces, and datasets
+// used as prefixes.
+func dataStreamPermissionsName(dsManifest *packages.DataStreamManifest, dataStreamType, dataset, namespace, defaultName string) string {
+	dynamicDataset := dsManifest.Elasticsearch != nil && dsManifest.Elasticsearch.DynamicDataset
+	dynamicNamespace := dsManifest.Elasticsearch != nil && dsManifest.Elasticsearch.DynamicNamespace
+	if !dynamicDataset && !dynamicNamespace && !dsManifest.DatasetIsPrefix {
+		return defaultName
+	}
+
+	switch {
+	case dynamicDataset:
+		dataset = "*"
+	case dsManifest.DatasetIsPrefix:
+		dataset = dataset + ".*"
+	}
+	if dynamicNamespace {
+		namespace = "*"
+	}
+	return fmt.Sprintf("%s-%s-%s", dataStreamType, dataset, namespace)
+}
+
+func compileTemplate(path string, vars agenttemplate.Vars) (map[string]any, error) {
+	template, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template: %w", err)
+	}
+	compiled, err := agenttemplate.Compile(template, vars)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile template %s: %w", filepath.Base(path), err)
+	}
+	if compiled.Config == nil {
+		return map[string]any{}, nil
+	}
+	return compiled.Config, nil
+}
+
+// secretReferences keeps track of the values of secret variables, that Fleet
+// replaces with references in the agent policy.
+type secretReferences struct {
+	count int
+}
+
+// templateVars converts the values of the variables in a package policy to values
+// usable in templates. Values of secret variables are replaced by references.
+func (s *secretReferences) templateVars(definitions []packages.Variable, values kibana.Vars) agenttemplate.Vars {
+	vars := make(agenttemplate.Vars, len(values))
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		v := values[name]
+		value := v.Value.Value()
+		secret := slices.ContainsFunc(definitions, func(d packages.Variable) bool { return d.Name == name && d.Secret })
+		if secret && value != nil && value != "" {
+			value = fmt.Sprintf("${SECRET_%d}", s.count)
+			s.count++
+		}
+		vars[name] = agenttemplate.Var{Type: v.Type, Value: value}
+	}
+	return vars
+}
+
+func (s *secretReferences) list() []any {
+	references := []any{}
+	for i := range s.count {
+		references = append(references, map[string]any{"id": fmt.Sprintf("secret-%d", i)})
+	}
+	return references
+}
+
+// addOTelCollectorConfig adds the configuration of an OpenTelemetry collector input
+// to the policy. Component IDs are made unique for the stream, and the signals
+// collected are routed to the data stream of the package policy.
+func addOTelCollectorConfig(policy map[string]any, config map[string]any, streamID, dataStreamType, dataset, namespace string) {
+	componentIDs := make(map[string]string)
+	for _, section := range otelVariableKeySections {
+		components, ok := toMap(config[section])
+		if !ok {
+			continue
+		}
+		target := policySection(policy, section)
+		for id, component := range components {
+			newID := otelComponentID(id, streamID)
+			componentIDs[id] = newID
+			target[newID] = component
+		}
+	}
+
+	transformID := otelComponentID("transform", streamID)
+	service := policySection(policy, "service")
+	var pipelines map[string]any
+	if p, ok := toMap(service["pipelines"]); ok {
+		pipelines = p
+	} else {
+		pipelines = make(map[string]any)
+		service["pipelines"] = pipelines
+	}
+
+	var signals []string
+	if serviceConfig, ok := toMap(config["service"]); ok {
+		if extensions, ok := serviceConfig["extensions"].([]any); ok {
+			service["extensions"] = renameComponents(extensions, componentIDs)
+		}
+		configPipelines, _ := toMap(serviceConfig["pipelines"])
+		for name, p := range configPipelines {
+			pipeline, _ := toMap(p)
+			signal, _, _ := strings.Cut(name, "/")
+			if !slices.Contains(signals, signal) {
+				signals = append(signals, signal)
+			}
+
+			receivers, _ := pipeline["receivers"].([]any)
+			processors, _ := pipeline["processors"].([]any)
+			exporters, _ := pipeline["exporters"].([]any)
+			pipelines[otelComponentID(name, streamID)] = map[string]any{
+				"receivers":  renameComponents(receivers, componentIDs),
+				"processors": append(renameComponents(processors, componentIDs), transformID),
+				"exporters":  append(renameComponents(exporters, componentIDs), "forward"),
+			}
+		}
+	}
+	slices.Sort(signals)
+
+	transform := make(map[string]any)
+	for _, signal := range signals {
+		settings, found := otelSignals[signal]
+		if !found {
+			continue
+		}
+		transform[settings.statementsKey] = []any{
+			map[string]any{
+				"context": settings.context,
+				"statements": []any{
+					fmt.Sprintf(`set(attributes["data_stream.type"], %q)`, dataStreamType),
+					fmt.Sprintf(`set(attributes["data_stream.dataset"], %q)`, dataset),
+					fmt.Sprintf(`set(attributes["data_stream.namespace"], %q)`, namespace),
+				},
+			},
+		}
+		pipelines[signal] = map[string]any{
+			"receivers": []any{"forward"},
+			"exporters": []any{"elasticsearch/" + defaultOutputName},
+		}
+	}
+	policySection(policy, "processors")[transformID] = transform
+	policySection(policy, "connectors")["forward"] = map[string]any{}
+	policySection(policy, "exporters")["elasticsearch/"+defaultOutputName] = map[string]any{
+		"endpoints": []any{offlineElasticsearchHost},
+	}
+}
+
+func otelComponentID(id, suffix string) string {
+	if strings.Contains(id, "/") {
+		return id + "-" + suffix
+	}
+	return id + "/" + suffix
+}
+
+func renameComponents(ids []any, componentIDs map[string]string) []any {
+	renamed := make([]any, 0, len(ids))
+	for _, id := range ids {
+		if s, ok := id.(string); ok {
+			if newID, found := componentIDs[s]; found {
+				renamed = append(renamed, newID)
+				continue
+			}
+		}
+		renamed = append(renamed, id)
+	}
+	return renamed
+}
+
+func policySection(policy map[string]any, name string) map[string]any {
+	if section, ok := toMap(policy[name]); ok {
+		return section
+	}
+	section := make(map[string]any)
+	policy[name] = section
+	return section
+}
+
+func toAnySlice(values []string) []any {
+	result := make([]any, len(values))
+	for i, v := range values {
+		result[i] = v
+	}
+	return result
+}
@@ -0,0 +1,117 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCompileTestPolicy checks that the policies compiled offline for the test
+// packages in this repository match the policies generated by Fleet.
+func TestCompileTestPolicy(t *testing.T) {
+	testPaths, err := filepath.Glob("../../../../test/packages/parallel/*/_dev/test/policy/test-*.yml")
+	require.NoError(t, err)
+	dataStreamTestPaths, err := filepath.Glob("../../../../test/packages/parallel/*/data_stream/*/_dev/test/policy/test-*.yml")
+	require.NoError(t, err)
+	testPaths = append(testPaths, dataStreamTestPaths...)
+	require.NotEmpty(t, testPaths)
+
+	for _, testPath := range testPaths {
+		t.Run(testPath, func(t *testing.T) {
+			packageRoot, dataStream := packageRootAndDataStream(testPath)
+
+			compiled, err := CompileTestPolicy(packageRoot, filepath.Base(packageRoot), dataStream, testPath)
+			require.NoError(t, err)
+
+			expected, err := os.ReadFile(expectedPathFor(testPath))
+			require.NoError(t, err)
+
+			diff, err := comparePolicies(expected, compiled)
+			require.NoError(t, err)
+			assert.Empty(t, diff)
+		})
+	}
+}
+
+func packageRootAndDataStream(testPath string) (string, string) {
+	testFolder := filepath.Dir(testPath)
+	root := filepath.Dir(filepath.Dir(filepath.Dir(testFolder)))
+	if filepath.Base(filepath.Dir(root)) == "data_stream" {
+		return filepath.Dir(filepath.Dir(root)), filepath.Base(root)
+	}
+	return root, ""
+}
+
+func TestCompileTestPolicyPolicyTemplateVars(t *testing.T) {
+	packageRoot := t.TempDir()
+	files := map[string]string{
+		"manifest.yml": `format_version: 3.0.0
+name: vars_test
+title: Vars test
+version: 1.0.0
+type: integration
+vars:
+  - name: level
+    type: text
+    default: package
+  - name: package_only
+    type: text
+    default: package
+policy_templates:
+  - name: logs
+    title: Logs
+    vars:
+      - name: level
+        type: text
+        default: policy_template
+      - name: input_level
+        type: text
+        default: policy_template
+      - name: template_only
+        type: text
+    inputs:
+      - type: logfile
+        title: Logs
+        vars:
+          - name: input_level
+            type: text
+            default: input
+`,
+		"data_stream/logs/manifest.yml": `title: Logs
+type: logs
+streams:
+  - input: logfile
+    title: Logs
+    description: Logs
+`,
+		"data_stream/logs/agent/stream/stream.yml.hbs": `level: {{level}}
+input_level: {{input_level}}
+package_only: {{package_only}}
+template_only: {{template_only}}
+`,
+		"data_stream/logs/_dev/test/policy/test-default.yml": `vars:
+  template_only: custom
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(packageRoot, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	testPath := filepath.Join(packageRoot, "data_stream", "logs", "_dev", "test", "policy", "test-default.yml")
+	compiled, err := CompileTestPolicy(packageRoot, "vars_test", "logs", testPath)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(compiled), "level: policy_template\n")
+	assert.Contains(t, string(compiled), "input_level: input\n")
+	assert.Contains(t, string(compiled), "package_only: package\n")
+	assert.Contains(t, string(compiled), "template_only: custom\n")
+}
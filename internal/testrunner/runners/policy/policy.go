@@ -31,6 +31,10 @@ func dumpExpectedAgentPolicy(ctx context.Context, kibanaClient *kibana.Client, t
 		return fmt.Errorf("failed to download policy %q: %w", policyID, err)
 	}
 
+	return writeExpectedAgentPolicy(testPath, policy)
+}
+
+func writeExpectedAgentPolicy(testPath string, policy []byte) error {
 	d, err := cleanPolicy(policy, policyEntryFilters)
 	if err != nil {
 		return fmt.Errorf("failed to prepare policy to store: %w", err)
@@ -49,6 +53,10 @@ func assertExpectedAgentPolicy(ctx context.Context, kibanaClient *kibana.Client,
 	if err != nil {
 		return fmt.Errorf("failed to download policy %q: %w", policyID, err)
 	}
+	return compareExpectedAgentPolicy(testPath, policy)
+}
+
+func compareExpectedAgentPolicy(testPath string, policy []byte) error {
 	expectedPolicy, err := os.ReadFile(expectedPathFor(testPath))
 	if err != nil {
 		return fmt.Errorf("failed to read expected policy: %w", err)
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/elastic/elastic-package/internal/fields"
@@ -30,6 +31,8 @@ type runner struct {
 	dataStreams        []string
 	failOnMissingTests bool
 	generateTestResult bool
+	offline            bool
+	testPaths          []string
 	globalTestConfig   testrunner.GlobalRunnerTestConfig
 	withCoverage       bool
 	coverageType       string
@@ -51,6 +54,8 @@ type PolicyTestRunnerOptions struct {
 	DataStreams        []string
 	FailOnMissingTests bool
 	GenerateTestResult bool
+	Offline            bool
+	TestPaths          []string
 	GlobalTestConfig   testrunner.GlobalRunnerTestConfig
 	WithCoverage       bool
 	CoverageType       string
@@ -65,6 +70,8 @@ func NewPolicyTestRunner(options PolicyTestRunnerOptions) *runner {
 		dataStreams:        options.DataStreams,
 		failOnMissingTests: options.FailOnMissingTests,
 		generateTestResult: options.GenerateTestResult,
+		offline:            options.Offline,
+		testPaths:          options.TestPaths,
 		globalTestConfig:   options.GlobalTestConfig,
 		withCoverage:       options.WithCoverage,
 		coverageType:       options.CoverageType,
@@ -78,6 +85,10 @@ func NewPolicyTestRunner(options PolicyTestRunnerOptions) *runner {
 
 // SetupRunner prepares global resources required by the test runner.
 func (r *runner) SetupRunner(ctx context.Context) error {
+	if r.offline {
+		// Policies are compiled locally, the package doesn't need to be installed.
+		return nil
+	}
 	cleanup, err := r.setupSuite(ctx, r.resourcesManager)
 	if err != nil {
 		return fmt.Errorf("failed to setup test runner: %w", err)
@@ -147,11 +158,15 @@ func (r *runner) GetTests(ctx context.Context) ([]testrunner.Tester, error) {
 			return nil, fmt.Errorf("failed to look for test files in %s: %w", folder.Path, err)
 		}
 		for _, test := range tests {
+			if len(r.testPaths) > 0 && !slices.Contains(r.testPaths, test) {
+				continue
+			}
 			testers = append(testers, NewPolicyTester(PolicyTesterOptions{
 				PackageRoot:        r.packageRoot,
 				TestFolder:         folder,
 				KibanaClient:       r.kibanaClient,
 				GenerateTestResult: r.generateTestResult,
+				Offline:            r.offline,
 				TestPath:           test,
 				GlobalTestConfig:   r.globalTestConfig,
 				WithCoverage:       r.withCoverage,
@@ -15,13 +15,13 @@ import (
 )
 
 type testConfig struct {
-	testrunner.SkippableConfig `config:",inline"`
+	testrunner.SkippableConfig `config:",inline" yaml:"-"`
 
 	Input      string         `config:"input,omitempty" yaml:"input,omitempty"`
 	Vars       map[string]any `config:"vars,omitempty" yaml:"vars,omitempty"`
 	DataStream struct {
 		Vars map[string]any `config:"vars,omitempty" yaml:"vars,omitempty"`
-	} `config:"data_stream" yaml:"data_stream"`
+	} `config:"data_stream" yaml:"data_stream,omitempty"`
 }
 
 func readTestConfig(testPath string) (*testConfig, error) {
@@ -25,6 +25,7 @@ type tester struct {
 	kibanaClient       *kibana.Client
 	testPath           string
 	generateTestResult bool
+	offline            bool
 	globalTestConfig   testrunner.GlobalRunnerTestConfig
 	withCoverage       bool
 	coverageType       string
@@ -41,6 +42,7 @@ type PolicyTesterOptions struct {
 	KibanaClient       *kibana.Client
 	PackageRoot        string
 	GenerateTestResult bool
+	Offline            bool
 	GlobalTestConfig   testrunner.GlobalRunnerTestConfig
 	WithCoverage       bool
 	CoverageType       string
@@ -52,6 +54,7 @@ func NewPolicyTester(options PolicyTesterOptions) *tester {
 		testFolder:         options.TestFolder,
 		packageRoot:        options.PackageRoot,
 		generateTestResult: options.GenerateTestResult,
+		offline:            options.Offline,
 		testPath:           options.TestPath,
 		globalTestConfig:   options.GlobalTestConfig,
 		withCoverage:       options.WithCoverage,
@@ -110,10 +113,15 @@ func (r *tester) runTest(ctx context.Context, manager *resources.Manager, testPa
 		return result.WithSkip(skip)
 	}
 
+	if r.offline {
+		testErr := r.runOfflineTest(testPath)
+		return r.testResult(result, testErr)
+	}
+
 	policyTestSuffix := common.CreateTestRunID()
 	policy := resources.FleetAgentPolicy{
 		Name:      fmt.Sprintf("%s-%s", testName, policyTestSuffix),
-		Namespace: "ep",
+		Namespace: testPolicyNamespace,
 		PackagePolicies: []resources.FleetPackagePolicy{
 			{
 				Name:           fmt.Sprintf("%s-%s-%s", testName, r.testFolder.Package, policyTestSuffix),
@@ -145,6 +153,23 @@ func (r *tester) runTest(ctx context.Context, manager *resources.Manager, testPa
 		return result.WithErrorf("cleanup failed: %w", err)
 	}
 
+	return r.testResult(result, testErr)
+}
+
+// runOfflineTest compiles the policy of the test without Fleet, and compares it with
+// the expected policy, or stores it as the expected policy if results are generated.
+func (r *tester) runOfflineTest(testPath string) error {
+	policy, err := CompileTestPolicy(r.packageRoot, r.testFolder.Package, r.testFolder.DataStream, testPath)
+	if err != nil {
+		return fmt.Errorf("failed to compile policy: %w", err)
+	}
+	if r.generateTestResult {
+		return writeExpectedAgentPolicy(testPath, policy)
+	}
+	return compareExpectedAgentPolicy(testPath, policy)
+}
+
+func (r *tester) testResult(result *testrunner.ResultComposer, testErr error) ([]testrunner.TestResult, error) {
 	if r.withCoverage {
 		coverage, err := generateCoverageReport(result.CoveragePackageName(), r.packageRoot, r.testFolder.DataStream, r.coverageType)
 		if err != nil {
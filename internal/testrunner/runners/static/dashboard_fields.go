@@ -0,0 +1,504 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package static
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/elastic/elastic-package/internal/common"
+	"github.com/elastic/elastic-package/internal/export"
+	"github.com/elastic/elastic-package/internal/fields"
+	"github.com/elastic/elastic-package/internal/files"
+	"github.com/elastic/elastic-package/internal/packages"
+	"github.com/elastic/elastic-package/internal/testrunner"
+)
+
+// savedObjectTypesWithFields are the types of Kibana saved objects whose
+// field references are checked.
+var savedObjectTypesWithFields = []string{"dashboard", "visualization", "lens", "search", "map"}
+
+// fieldReferenceKeys are the keys used in saved objects to reference fields.
+var fieldReferenceKeys = map[string]string{
+	"sourceField": "lens",
+	"field":       "aggregation",
+	"terms_field": "tsvb",
+	"time_field":  "tsvb",
+	"timeField":   "tsvb",
+	"geoField":    "map",
+}
+
+// fieldReference is a reference to a field found in a Kibana saved object.
+type fieldReference struct {
+	Field  string
+	Panel  string
+	Source string
+}
+
+func (r tester) verifyDashboardFields(pkgManifest *packages.PackageManifest) []testrunner.TestResult {
+	resultComposer := testrunner.NewResultComposer(testrunner.TestResult{
+		Name:       "Verify fields referenced in dashboards",
+		TestType:   TestType,
+		Package:    r.testFolder.Package,
+		DataStream: r.testFolder.DataStream,
+	})
+
+	if r.testFolder.DataStream != "" {
+		// Dashboards are defined at the package level.
+		return []testrunner.TestResult{}
+	}
+
+	objectPaths, err := findSavedObjectFiles(r.packageRoot)
+	if err != nil {
+		results, _ := resultComposer.WithError(err)
+		return results
+	}
+	if len(objectPaths) == 0 {
+		// Nothing to do.
+		return []testrunner.TestResult{}
+	}
+
+	schema, err := r.packageFieldsSchema(pkgManifest)
+	if err != nil {
+		results, _ := resultComposer.WithErrorf("loading package fields failed: %w", err)
+		return results
+	}
+
+	var issues []string
+	for _, path := range objectPaths {
+		references, err := savedObjectFieldReferences(path)
+		if err != nil {
+			results, _ := resultComposer.WithError(err)
+			return results
+		}
+
+		relPath, err := filepath.Rel(filepath.Join(r.packageRoot, "kibana"), path)
+		if err != nil {
+			relPath = path
+		}
+		for _, ref := range references {
+			if isFieldDefined(ref.Field, schema) {
+				continue
+			}
+			location := relPath
+			if ref.Panel != "" {
+				location = fmt.Sprintf("%s, panel %q", relPath, ref.Panel)
+			}
+			issues = append(issues, fmt.Sprintf("%s: field %q (%s) is not defined", location, ref.Field, ref.Source))
+		}
+	}
+
+	if len(issues) > 0 {
+		results, _ := resultComposer.WithError(testrunner.ErrTestCaseFailed{
+			Reason:  "one or more fields referenced in dashboards are not defined",
+			Details: strings.Join(issues, "\n"),
+		})
+		return results
+	}
+
+	results, _ := resultComposer.WithSuccess()
+	return results
+}
+
+// packageFieldsSchema loads the field definitions of all the data streams of
+// the package, or of the package itself for input packages, including the
+// fields imported from ECS.
+func (r tester) packageFieldsSchema(pkgManifest *packages.PackageManifest) ([]fields.FieldDefinition, error) {
+	repositoryRoot, err := files.FindRepositoryRootFrom(r.packageRoot)
+	if err != nil {
+		return nil, fmt.Errorf("cannot find repository root from %s: %w", r.packageRoot, err)
+	}
+	defer repositoryRoot.Close()
+
+	fieldsDirs, err := filepath.Glob(filepath.Join(r.packageRoot, "data_stream", "*", "fields"))
+	if err != nil {
+		return nil, err
+	}
+	fieldsDirs = append(fieldsDirs, filepath.Join(r.packageRoot, "fields"))
+
+	var schema []fields.FieldDefinition
+	for _, fieldsDir := range fieldsDirs {
+		validator, err := fields.CreateValidator(repositoryRoot, r.packageRoot, fieldsDir,
+			fields.WithSpecVersion(pkgManifest.SpecVersion),
+			fields.WithEnabledImportAllECSSChema(true),
+			fields.WithSchemaURLs(r.schemaURLs),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("creating fields validator failed (path: %s): %w", fieldsDir, err)
+		}
+		schema = append(schema, validator.Schema...)
+	}
+	return schema, nil
+}
+
+func isFieldDefined(name string, schema []fields.FieldDefinition) bool {
+	if strings.HasPrefix(name, "_") || strings.Contains(name, "*") {
+		// Metadata fields and wildcards.
+		return true
+	}
+	return fields.FindElementDefinition(name, schema) != nil
+}
+
+func findSavedObjectFiles(packageRoot string) ([]string, error) {
+	var paths []string
+	for _, objectType := range savedObjectTypesWithFields {
+		found, err := filepath.Glob(filepath.Join(packageRoot, "kibana", objectType, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, found...)
+	}
+	return paths, nil
+}
+
+// savedObjectFieldReferences returns the unique field references found in the
+// saved object stored in the given file.
+func savedObjectFieldReferences(path string) ([]fieldReference, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading saved object failed: %w", err)
+	}
+
+	var object common.MapStr
+	if err := json.Unmarshal(content, &object); err != nil {
+		return nil, fmt.Errorf("unmarshalling saved object failed (path: %s): %w", path, err)
+	}
+
+	object, err = export.DecodeSavedObject(object)
+	if err != nil {
+		return nil, fmt.Errorf("decoding saved object failed (path: %s): %w", path, err)
+	}
+
+	return extractFieldReferences(object), nil
+}
+
+// extractFieldReferences returns the unique field references in a decoded
+// saved object, from Lens columns, legacy and TSVB visualizations, saved
+// searches, filters and KQL queries.
+func extractFieldReferences(object common.MapStr) []fieldReference {
+	// Some visualizations, like TSVB ones, reference other metrics by their IDs
+	// in the same attributes used to reference fields.
+	ids := make(map[string]bool)
+	collectIDs(object, ids)
+
+	var references []fieldReference
+	add := func(ref fieldReference) {
+		if ref.Field == "" || ids[ref.Field] || slices.Contains(references, ref) {
+			return
+		}
+		references = append(references, ref)
+	}
+
+	attributes, _ := object["attributes"].(map[string]any)
+	if columns, ok := attributes["columns"].([]any); ok {
+		for _, column := range columns {
+			if name, ok := column.(string); ok {
+				add(fieldReference{Field: name, Source: "search"})
+			}
+		}
+	}
+	if sortSpec, ok := attributes["sort"].([]any); ok {
+		for _, s := range sortSpec {
+			if pair, ok := s.([]any); ok && len(pair) > 0 {
+				if name, ok := pair[0].(string); ok {
+					add(fieldReference{Field: name, Source: "search"})
+				}
+			}
+		}
+	}
+
+	panels, _ := attributes["panelsJSON"].([]map[string]any)
+	for _, panel := range panels {
+		name := panelName(panel)
+		walkFieldReferences(panel["embeddableConfig"], func(field, source string) {
+			add(fieldReference{Field: field, Panel: name, Source: source})
+		})
+	}
+	delete(attributes, "panelsJSON")
+	walkFieldReferences(attributes, func(field, source string) {
+		add(fieldReference{Field: field, Source: source})
+	})
+
+	sort.SliceStable(references, func(i, j int) bool {
+		return references[i].Panel < references[j].Panel
+	})
+	return references
+}
+
+func walkFieldReferences(value any, fn func(field, source string)) {
+	switch v := value.(type) {
+	case map[string]any:
+		if language, ok := v["language"].(string); ok {
+			if query, ok := v["query"].(string); ok && (language == "kuery" || language == "lucene") {
+				for _, field := range queryFields(query, language) {
+					fn(field, "query")
+				}
+			}
+		}
+		if meta, ok := v["meta"].(map[string]any); ok {
+			if key, ok := meta["key"].(string); ok {
+				if _, isQuery := meta["type"]; isQuery {
+					fn(key, "filter")
+				}
+			}
+		}
+		for key, e := range v {
+			if source, found := fieldReferenceKeys[key]; found {
+				if field, ok := e.(string); ok {
+					if field != "___records___" {
+						fn(field, source)
+					}
+					continue
+				}
+			}
+			walkFieldReferences(e, fn)
+		}
+	case common.MapStr:
+		walkFieldReferences(map[string]any(v), fn)
+	case []any:
+		for _, e := range v {
+			walkFieldReferences(e, fn)
+		}
+	case []map[string]any:
+		for _, e := range v {
+			walkFieldReferences(e, fn)
+		}
+	}
+}
+
+// collectIDs collects the IDs of the saved object references, of the panels
+// and of the TSVB metrics.
+func collectIDs(object common.MapStr, ids map[string]bool) {
+	addID := func(value any, key string) {
+		if m, ok := value.(map[string]any); ok {
+			if id, ok := m[key].(string); ok && id != "" {
+				ids[id] = true
+			}
+		}
+	}
+
+	references, _ := object["references"].([]any)
+	for _, reference := range references {
+		addID(reference, "id")
+	}
+
+	attributes, _ := object["attributes"].(map[string]any)
+	panels, _ := attributes["panelsJSON"].([]map[string]any)
+	for _, panel := range panels {
+		for _, key := range []string{"panelIndex", "id", "panelRefName"} {
+			addID(panel, key)
+		}
+	}
+
+	collectMetricIDs(attributes, ids)
+}
+
+// collectMetricIDs collects the IDs of the metrics of TSVB series, that can be
+// referenced by other metrics in the same attributes used to reference fields.
+func collectMetricIDs(value any, ids map[string]bool) {
+	switch v := value.(type) {
+	case map[string]any:
+		if series, ok := v["series"].([]any); ok {
+			for _, s := range series {
+				serie, _ := s.(map[string]any)
+				metrics, _ := serie["metrics"].([]any)
+				for _, metric := range metrics {
+					if m, ok := metric.(map[string]any); ok {
+						if id, ok := m["id"].(string); ok && id != "" {
+							ids[id] = true
+						}
+					}
+				}
+			}
+		}
+		for _, e := range v {
+			collectMetricIDs(e, ids)
+		}
+	case common.MapStr:
+		collectMetricIDs(map[string]any(v), ids)
+	case []any:
+		for _, e := range v {
+			collectMetricIDs(e, ids)
+		}
+	case []map[string]any:
+		for _, e := range v {
+			collectMetricIDs(e, ids)
+		}
+	}
+}
+
+// queryFields returns the fields referenced in a KQL or Lucene query. Field
+// names are only taken at the start of clauses, before an operator, so quoted
+// strings and values are never reported as fields. Fields in KQL nested
+// queries, like `user:{ name: x }`, are prefixed with their parent field.
+func queryFields(query, language string) []string {
+	var result []string
+	var nested []string
+	add := func(field string) {
+		if len(nested) > 0 {
+			field = nested[len(nested)-1] + "." + field
+		}
+		if !slices.Contains(result, field) {
+			result = append(result, field)
+		}
+	}
+
+	i := 0
+	for i < len(query) {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '(' || c == ')':
+			i++
+		case c == '}':
+			if len(nested) > 0 {
+				nested = nested[:len(nested)-1]
+			}
+			i++
+		case c == '"':
+			i = skipQuoted(query, i)
+		default:
+			start := i
+			for i < len(query) && isQueryFieldChar(query[i]) {
+				i++
+			}
+			if i == start {
+				// Unexpected character, like a stray operator.
+				i++
+				continue
+			}
+			word := strings.TrimLeft(query[start:i], "-+")
+
+			j := i
+			for j < len(query) && (query[j] == ' ' || query[j] == '\t') {
+				j++
+			}
+			operator := queryOperator(query[j:])
+			if operator == "" || word == "" {
+				// Free text or keywords like `and`, `or` and `not`.
+				continue
+			}
+			i = j + len(operator)
+			for i < len(query) && (query[i] == ' ' || query[i] == '\t') {
+				i++
+			}
+
+			if language == "kuery" && operator == ":" && i < len(query) && query[i] == '{' {
+				if len(nested) > 0 {
+					word = nested[len(nested)-1] + "." + word
+				}
+				nested = append(nested, word)
+				i++
+				continue
+			}
+			add(word)
+			i = skipQueryValue(query, i, language)
+		}
+	}
+	return result
+}
+
+func isQueryFieldChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		strings.IndexByte("_@.-+*", c) >= 0
+}
+
+func queryOperator(s string) string {
+	for _, operator := range []string{"<=", ">=", ":", "<", ">"} {
+		if strings.HasPrefix(s, operator) {
+			return operator
+		}
+	}
+	return ""
+}
+
+// skipQuoted returns the position after the quoted string starting at i.
+func skipQuoted(query string, i int) int {
+	for i++; i < len(query); i++ {
+		switch query[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(query)
+}
+
+// skipQueryValue returns the position after the value starting at i.
+func skipQueryValue(query string, i int, language string) int {
+	if i >= len(query) {
+		return i
+	}
+	switch c := query[i]; {
+	case c == '"':
+		return skipQuoted(query, i)
+	case c == '(':
+		return skipGroup(query, i)
+	case language == "lucene" && (c == '[' || c == '{'):
+		// Ranges, that can be inclusive or exclusive on each side.
+		if end := strings.IndexAny(query[i:], "]}"); end >= 0 {
+			return i + end + 1
+		}
+		return len(query)
+	}
+	for ; i < len(query); i++ {
+		switch query[i] {
+		case '\\':
+			i++
+		case ' ', '\t', '\n', '\r', ')', '}':
+			return i
+		}
+	}
+	return len(query)
+}
+
+// skipGroup returns the position after the parenthesized group starting at i,
+// taking into account nested groups and quoted strings.
+func skipGroup(query string, i int) int {
+	depth := 0
+	for i < len(query) {
+		switch query[i] {
+		case '"':
+			i = skipQuoted(query, i)
+			continue
+		case '\\':
+			i++
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+		i++
+	}
+	return len(query)
+}
+
+func panelName(panel map[string]any) string {
+	if title, ok := panel["title"].(string); ok && title != "" {
+		return title
+	}
+	if config, ok := panel["embeddableConfig"].(map[string]any); ok {
+		if title, ok := config["title"].(string); ok && title != "" {
+			return title
+		}
+		if attributes, ok := config["attributes"].(map[string]any); ok {
+			if title, ok := attributes["title"].(string); ok && title != "" {
+				return title
+			}
+		}
+	}
+	if index, ok := panel["panelIndex"].(string); ok {
+		return index
+	}
+	return ""
+}
@@ -0,0 +1,143 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package static
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-package/internal/common"
+	"github.com/elastic/elastic-package/internal/export"
+	"github.com/elastic/elastic-package/internal/fields"
+)
+
+func TestQueryFields(t *testing.T) {
+	cases := []struct {
+		query    string
+		language string
+		expected []string
+	}{
+		{query: "", expected: nil},
+		{query: `event.action: "denied" and not source.ip: 10.0.0.1`, expected: []string{"event.action", "source.ip"}},
+		{query: `(http.response.status_code >= 400 or url.path: "/a: b")`, expected: []string{"http.response.status_code", "url.path"}},
+		{query: `message: "user: admin"`, expected: []string{"message"}},
+		{query: `url.full: http://x`, expected: []string{"url.full"}},
+		{query: `event.start > 10:30`, expected: []string{"event.start"}},
+		{query: `user:{ name: x }`, expected: []string{"user.name"}},
+		{query: `user:{ name: x and group:{ id: 1 } } and host.name: y`, expected: []string{"user.name", "user.group.id", "host.name"}},
+		{query: `event.code:(4624 or 4625) and free text`, expected: []string{"event.code"}},
+		{query: `event.duration:[10 TO 20} AND -url.path:"/a" AND host.name:x\:y`, language: "lucene", expected: []string{"event.duration", "url.path", "host.name"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.query, func(t *testing.T) {
+			language := c.language
+			if language == "" {
+				language = "kuery"
+			}
+			assert.Equal(t, c.expected, queryFields(c.query, language))
+		})
+	}
+}
+
+func TestExtractFieldReferences(t *testing.T) {
+	searchSource, err := json.Marshal(map[string]any{
+		"query": map[string]any{"language": "kuery", "query": "data_stream.dataset: foo.bar"},
+		"filter": []any{
+			map[string]any{"meta": map[string]any{"key": "host.name", "type": "phrase"}},
+		},
+	})
+	require.NoError(t, err)
+
+	panels, err := json.Marshal([]any{
+		map[string]any{
+			"panelIndex": "1",
+			"title":      "Lens panel",
+			"embeddableConfig": map[string]any{
+				"attributes": map[string]any{
+					"state": map[string]any{
+						"datasourceStates": map[string]any{
+							"formBased": map[string]any{
+								"layers": map[string]any{
+									"layer": map[string]any{
+										"columns": map[string]any{
+											"a": map[string]any{"operationType": "count", "sourceField": "___records___"},
+											"b": map[string]any{"operationType": "terms", "sourceField": "foo.name"},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		map[string]any{
+			"panelIndex": "2",
+			"embeddableConfig": map[string]any{
+				"savedVis": map[string]any{
+					"params": map[string]any{
+						"type":       "timeseries",
+						"time_field": "@timestamp",
+						"series": []any{
+							map[string]any{
+								"terms_field": "foo.id",
+								"metrics": []any{
+									map[string]any{"id": "m1", "type": "max", "field": "foo.bytes"},
+									map[string]any{"id": "m2", "type": "derivative", "field": "m1"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	object := common.MapStr{
+		"id":   "foo.name",
+		"type": "dashboard",
+		"attributes": map[string]any{
+			"kibanaSavedObjectMeta": map[string]any{
+				"searchSourceJSON": string(searchSource),
+			},
+			"panelsJSON": string(panels),
+		},
+	}
+	object, err = export.DecodeSavedObject(object)
+	require.NoError(t, err)
+
+	expected := []fieldReference{
+		{Field: "data_stream.dataset", Source: "query"},
+		{Field: "host.name", Source: "filter"},
+		{Field: "@timestamp", Panel: "2", Source: "tsvb"},
+		{Field: "foo.id", Panel: "2", Source: "tsvb"},
+		{Field: "foo.bytes", Panel: "2", Source: "aggregation"},
+		{Field: "foo.name", Panel: "Lens panel", Source: "lens"},
+	}
+	assert.ElementsMatch(t, expected, extractFieldReferences(object))
+}
+
+func TestIsFieldDefined(t *testing.T) {
+	schema := []fields.FieldDefinition{
+		{
+			Name: "foo",
+			Type: "group",
+			Fields: []fields.FieldDefinition{
+				{Name: "name", Type: "keyword"},
+			},
+		},
+		{Name: "labels.*", Type: "object"},
+	}
+
+	assert.True(t, isFieldDefined("foo.name", schema))
+	assert.True(t, isFieldDefined("_id", schema))
+	assert.True(t, isFieldDefined("labels.env", schema))
+	assert.False(t, isFieldDefined("foo.id", schema))
+}
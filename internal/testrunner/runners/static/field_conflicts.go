@@ -0,0 +1,65 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package static
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/elastic/elastic-package/internal/fields"
+	"github.com/elastic/elastic-package/internal/files"
+	"github.com/elastic/elastic-package/internal/logger"
+	"github.com/elastic/elastic-package/internal/testrunner"
+)
+
+// verifyFieldConflicts checks that fields are defined with the same settings in all the
+// data streams of the package. Conflicts with ECS are only reported as warnings, as
+// packages can intentionally override some ECS settings.
+func (r tester) verifyFieldConflicts() []testrunner.TestResult {
+	resultComposer := testrunner.NewResultComposer(testrunner.TestResult{
+		Name:       "Verify conflicts between field definitions",
+		TestType:   TestType,
+		Package:    r.testFolder.Package,
+		DataStream: r.testFolder.DataStream,
+	})
+
+	if r.testFolder.DataStream != "" {
+		// Conflicts are checked between all the data streams of the package.
+		return []testrunner.TestResult{}
+	}
+
+	repositoryRoot, err := files.FindRepositoryRootFrom(r.packageRoot)
+	if err != nil {
+		results, _ := resultComposer.WithErrorf("cannot find repository root from %s: %w", r.packageRoot, err)
+		return results
+	}
+	defer repositoryRoot.Close()
+
+	conflicts, err := fields.FindFieldConflicts(repositoryRoot, r.packageRoot, r.schemaURLs)
+	if err != nil {
+		results, _ := resultComposer.WithErrorf("looking for conflicts between field definitions failed: %w", err)
+		return results
+	}
+
+	var issues []string
+	for _, conflict := range conflicts {
+		if !conflict.InPackage {
+			logger.Warnf("%s", conflict)
+			continue
+		}
+		issues = append(issues, conflict.String())
+	}
+
+	if len(issues) > 0 {
+		results, _ := resultComposer.WithError(testrunner.ErrTestCaseFailed{
+			Reason:  fmt.Sprintf("%d fields are defined with conflicting settings", len(issues)),
+			Details: strings.Join(issues, "\n"),
+		})
+		return results
+	}
+
+	results, _ := resultComposer.WithSuccess()
+	return results
+}
@@ -90,6 +90,15 @@ func (r *runner) GetTests(ctx context.Context) ([]testrunner.Tester, error) {
 			}
 			return nil, fmt.Errorf("no %s tests found", r.Type())
 		}
+
+		// Package-level resources, like dashboards, are verified when no specific
+		// data stream has been selected.
+		if len(r.dataStreams) == 0 {
+			tests = append(tests, testrunner.TestFolder{
+				Path:    filepath.Join(r.packageRoot, "_dev", "test", string(r.Type())),
+				Package: filepath.Base(r.packageRoot),
+			})
+		}
 	} else {
 		_, pkg := filepath.Split(r.packageRoot)
 		tests = []testrunner.TestFolder{
@@ -0,0 +1,327 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package static
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/elastic/elastic-package/internal/packages"
+	"github.com/elastic/elastic-package/internal/packages/agenttemplate"
+	"github.com/elastic/elastic-package/internal/testrunner"
+)
+
+const (
+	// defaultMaxTemplateCombinations is the default maximum number of variable
+	// combinations rendered for each stream template.
+	defaultMaxTemplateCombinations = 128
+
+	defaultStreamTemplatePath = "stream.yml.hbs"
+
+	// maxReportedTemplateIssues limits the number of issues included in the
+	// failure details, to keep them readable.
+	maxReportedTemplateIssues = 20
+)
+
+// handlebarsExpressionRegexp matches handlebars expressions that may have been
+// left unrendered, like `{{var}}`, `{{#if var}}` or `{{/if}}`.
+var handlebarsExpressionRegexp = regexp.MustCompile(`\{\{\{?[#/^>!&~]?\s*[\w.@-]+[^{}]*\}\}`)
+
+// unsetVar is used in combinations to represent optional variables that are
+// not set.
+type unsetVar struct{}
+
+func (unsetVar) String() string { return "<unset>" }
+
+// templateVarCombination is a set of values for the variables of a template.
+type templateVarCombination []templateVarValue
+
+type templateVarValue struct {
+	variable packages.Variable
+	value    any
+}
+
+func (c templateVarCombination) vars() agenttemplate.Vars {
+	vars := make(agenttemplate.Vars, len(c))
+	for _, v := range c {
+		if _, unset := v.value.(unsetVar); unset {
+			continue
+		}
+		vars[v.variable.Name] = agenttemplate.Var{Type: v.variable.Type, Value: v.value}
+	}
+	return vars
+}
+
+func (c templateVarCombination) String() string {
+	var parts []string
+	for _, v := range c {
+		parts = append(parts, fmt.Sprintf("%s=%v", v.variable.Name, v.value))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (r tester) verifyStreamTemplates(pkgManifest *packages.PackageManifest, maxCombinations int) []testrunner.TestResult {
+	resultComposer := testrunner.NewResultComposer(testrunner.TestResult{
+		Name:       "Verify agent stream templates",
+		TestType:   TestType,
+		Package:    r.testFolder.Package,
+		DataStream: r.testFolder.DataStream,
+	})
+
+	if r.testFolder.DataStream == "" {
+		// Nothing to do, only data streams have stream templates.
+		return []testrunner.TestResult{}
+	}
+
+	dataStreamManifest, err := packages.ReadDataStreamManifestFromPackageRoot(r.packageRoot, r.testFolder.DataStream)
+	if err != nil {
+		results, _ := resultComposer.WithErrorf("failed to read data stream manifest: %w", err)
+		return results
+	}
+	if len(dataStreamManifest.Streams) == 0 {
+		return []testrunner.TestResult{}
+	}
+
+	if maxCombinations <= 0 {
+		maxCombinations = defaultMaxTemplateCombinations
+	}
+
+	var issues []string
+	seen := make(map[string]bool)
+	for _, stream := range dataStreamManifest.Streams {
+		templatePath := stream.TemplatePath
+		if templatePath == "" {
+			templatePath = defaultStreamTemplatePath
+		}
+		templatePath = filepath.Join(r.packageRoot, "data_stream", r.testFolder.DataStream, "agent", "stream", templatePath)
+		template, err := os.ReadFile(templatePath)
+		if err != nil {
+			results, _ := resultComposer.WithErrorf("failed to read stream template: %w", err)
+			return results
+		}
+
+		variables := streamTemplateVariables(pkgManifest, r.testFolder.DataStream, stream)
+		for _, combination := range templateCombinations(variables, maxCombinations) {
+			for _, issue := range checkStreamTemplate(template, combination) {
+				issue = fmt.Sprintf("%s (input: %s): %s", filepath.Base(templatePath), stream.Input, issue)
+				if seen[issue] {
+					continue
+				}
+				seen[issue] = true
+				issues = append(issues, fmt.Sprintf("%s\n    with vars: %s", issue, combination))
+			}
+		}
+	}
+
+	if len(issues) > 0 {
+		details := issues
+		if len(details) > maxReportedTemplateIssues {
+			details = append(details[:maxReportedTemplateIssues:maxReportedTemplateIssues],
+				fmt.Sprintf("... and %d more", len(issues)-maxReportedTemplateIssues))
+		}
+		results, _ := resultComposer.WithError(testrunner.ErrTestCaseFailed{
+			Reason:  "one or more stream templates render invalid configurations",
+			Details: strings.Join(details, "\n"),
+		})
+		return results
+	}
+
+	results, _ := resultComposer.WithSuccess()
+	return results
+}
+
+// streamTemplateVariables returns the variables available for a stream template,
+// from the package, the input in the policy templates and the stream itself.
+// Variables defined in more specific levels override the more generic ones.
+func streamTemplateVariables(pkgManifest *packages.PackageManifest, dataStream string, stream packages.Stream) []packages.Variable {
+	var variables []packages.Variable
+	add := func(vars []packages.Variable) {
+		for _, v := range vars {
+			idx := slices.IndexFunc(variables, func(e packages.Variable) bool { return e.Name == v.Name })
+			if idx >= 0 {
+				variables[idx] = v
+				continue
+			}
+			variables = append(variables, v)
+		}
+	}
+
+	add(pkgManifest.Vars)
+	for _, policyTemplate := range pkgManifest.PolicyTemplates {
+		if len(policyTemplate.DataStreams) > 0 && !slices.Contains(policyTemplate.DataStreams, dataStream) {
+			continue
+		}
+		idx := slices.IndexFunc(policyTemplate.Inputs, func(i packages.Input) bool { return i.Type == stream.Input })
+		if idx >= 0 {
+			add(policyTemplate.Inputs[idx].Vars)
+			break
+		}
+	}
+	add(stream.Vars)
+
+	return variables
+}
+
+// templateVarVariants returns the values to test for a variable: its default,
+// both values for booleans, and unset for optional variables.
+func templateVarVariants(v packages.Variable) []any {
+	var variants []any
+	addVariant := func(value any) {
+		if !slices.ContainsFunc(variants, func(e any) bool { return reflect.DeepEqual(e, value) }) {
+			variants = append(variants, value)
+		}
+	}
+
+	if v.Default != nil && v.Default.Value() != nil {
+		addVariant(v.Default.Value())
+	} else if v.Required {
+		addVariant(exampleVarValue(v))
+	}
+	if v.Type == "bool" {
+		addVariant(true)
+		addVariant(false)
+	}
+	if !v.Required {
+		addVariant(unsetVar{})
+	}
+
+	return variants
+}
+
+// exampleVarValue returns a value for a required variable without default.
+func exampleVarValue(v packages.Variable) any {
+	var value any
+	switch v.Type {
+	case "bool":
+		value = true
+	case "integer":
+		value = 1
+	case "yaml":
+		value = "example: value"
+	case "url":
+		value = "https://example.com"
+	default:
+		value = "example"
+	}
+	if v.Multi && v.Type != "yaml" {
+		return []any{value}
+	}
+	return value
+}
+
+// templateCombinations returns the combinations of variants of the variables.
+// If the number of combinations exceeds the limit, a baseline with the first
+// variant of each variable is used, and then each variant is tried once on
+// top of it, so every variant is rendered at least once when possible.
+func templateCombinations(variables []packages.Variable, limit int) []templateVarCombination {
+	variants := make([][]any, len(variables))
+	total := 1
+	for i, v := range variables {
+		variants[i] = templateVarVariants(v)
+		if total <= limit {
+			total *= len(variants[i])
+		}
+	}
+
+	combination := func(indexes []int) templateVarCombination {
+		c := make(templateVarCombination, len(variables))
+		for i, v := range variables {
+			c[i] = templateVarValue{variable: v, value: variants[i][indexes[i]]}
+		}
+		return c
+	}
+
+	var combinations []templateVarCombination
+	indexes := make([]int, len(variables))
+	if total <= limit {
+		for {
+			combinations = append(combinations, combination(indexes))
+			i := len(indexes) - 1
+			for ; i >= 0; i-- {
+				indexes[i]++
+				if indexes[i] < len(variants[i]) {
+					break
+				}
+				indexes[i] = 0
+			}
+			if i < 0 {
+				return combinations
+			}
+		}
+	}
+
+	combinations = append(combinations, combination(indexes))
+	for i := range variables {
+		for j := 1; j < len(variants[i]); j++ {
+			if len(combinations) >= limit {
+				return combinations
+			}
+			indexes[i] = j
+			combinations = append(combinations, combination(indexes))
+		}
+		indexes[i] = 0
+	}
+	return combinations
+}
+
+// checkStreamTemplate renders the template with the given combination of
+// variables and returns the issues found in the result.
+func checkStreamTemplate(template []byte, combination templateVarCombination) []string {
+	compiled, err := agenttemplate.Compile(template, combination.vars())
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	// Expressions escaped with a backslash are intentionally rendered as is.
+	escaped := make(map[string]bool)
+	for _, match := range handlebarsExpressionRegexp.FindAllIndex(template, -1) {
+		if match[0] > 0 && template[match[0]-1] == '\\' {
+			escaped[string(template[match[0]:match[1]])] = true
+		}
+	}
+
+	var issues []string
+	for i, line := range bytes.Split(compiled.Raw, []byte("\n")) {
+		for _, expression := range handlebarsExpressionRegexp.FindAll(line, -1) {
+			if !escaped[string(expression)] {
+				issues = append(issues, fmt.Sprintf("line %d: unrendered handlebars expression: %s", i+1, bytes.TrimSpace(line)))
+				break
+			}
+		}
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(compiled.Raw, &root); err != nil {
+		return append(issues, fmt.Sprintf("rendered template is not valid YAML: %s", err))
+	}
+	issues = append(issues, findNullKeys(&root)...)
+	return issues
+}
+
+// findNullKeys looks for mapping keys that are null, what usually happens when
+// a key is rendered from an unset variable.
+func findNullKeys(node *yaml.Node) []string {
+	var issues []string
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			if key.Kind == yaml.ScalarNode && key.Tag == "!!null" {
+				issues = append(issues, fmt.Sprintf("line %d: null key found", key.Line))
+			}
+		}
+	}
+	for _, child := range node.Content {
+		issues = append(issues, findNullKeys(child)...)
+	}
+	return issues
+}
@@ -0,0 +1,146 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package static
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/go-ucfg"
+
+	"github.com/elastic/elastic-package/internal/packages"
+)
+
+func varWithDefault(t *testing.T, v packages.Variable, value any) packages.Variable {
+	t.Helper()
+	var vv packages.VarValue
+	require.NoError(t, vv.Unpack(value))
+	v.Default = &vv
+	return v
+}
+
+func TestTemplateCombinations(t *testing.T) {
+	variables := []packages.Variable{
+		varWithDefault(t, packages.Variable{Name: "enabled", Type: "bool", Required: true}, true),
+		varWithDefault(t, packages.Variable{Name: "tags", Type: "text", Multi: true}, []any{"forwarded"}),
+		{Name: "url", Type: "url", Required: true},
+	}
+
+	combinations := templateCombinations(variables, 10)
+	require.Len(t, combinations, 4)
+	assert.Equal(t, "enabled=true, tags=[forwarded], url=https://example.com", combinations[0].String())
+	assert.Equal(t, "enabled=false, tags=<unset>, url=https://example.com", combinations[3].String())
+
+	vars := combinations[1].vars()
+	assert.NotContains(t, vars, "tags")
+	assert.Equal(t, "https://example.com", vars["url"].Value)
+
+	// Limited number of combinations, each variant should be tried at least once.
+	combinations = templateCombinations(variables, 3)
+	require.Len(t, combinations, 3)
+	assert.Equal(t, "enabled=true, tags=[forwarded], url=https://example.com", combinations[0].String())
+	assert.Equal(t, "enabled=false, tags=[forwarded], url=https://example.com", combinations[1].String())
+	assert.Equal(t, "enabled=true, tags=<unset>, url=https://example.com", combinations[2].String())
+}
+
+func TestCheckStreamTemplate(t *testing.T) {
+	variables := []packages.Variable{
+		{Name: "key", Type: "text"},
+		varWithDefault(t, packages.Variable{Name: "enabled", Type: "bool"}, false),
+	}
+
+	cases := []struct {
+		title    string
+		template string
+		invalid  bool
+	}{
+		{
+			title:    "valid template",
+			template: "{{#if key}}{{key}}: value\n{{/if}}{{#if enabled}}enabled: true\n{{/if}}",
+		},
+		{
+			title:    "null key when variable is unset",
+			template: "{{key}}: value\n",
+			invalid:  true,
+		},
+		{
+			title:    "invalid yaml",
+			template: "{{#if enabled}}\nfoo: [\n{{/if}}\nbar: baz\n",
+			invalid:  true,
+		},
+		{
+			title:    "escaped handlebars expression",
+			template: "value: \"\\{{not_rendered}}\"\n",
+		},
+		{
+			title:    "quoted empty key",
+			template: "'': value\n",
+		},
+		{
+			title:    "braces in scripts",
+			template: "program: |\n  {\"events\": {\"json\": e}}\n",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			var issues []string
+			for _, combination := range templateCombinations(variables, defaultMaxTemplateCombinations) {
+				issues = append(issues, checkStreamTemplate([]byte(c.template), combination)...)
+			}
+			if !c.invalid {
+				assert.Empty(t, issues)
+			} else {
+				assert.NotEmpty(t, issues)
+			}
+		})
+	}
+}
+
+func TestStreamTemplateVariables(t *testing.T) {
+	manifest := packages.PackageManifest{
+		Vars: []packages.Variable{{Name: "api_key"}, {Name: "timeout", Type: "text"}},
+		PolicyTemplates: []packages.PolicyTemplate{
+			{
+				DataStreams: []string{"other"},
+				Inputs:      []packages.Input{{Type: "logfile", Vars: []packages.Variable{{Name: "other"}}}},
+			},
+			{
+				Inputs: []packages.Input{{Type: "logfile", Vars: []packages.Variable{{Name: "timeout", Type: "integer"}}}},
+			},
+		},
+	}
+	stream := packages.Stream{
+		Input: "logfile",
+		Vars:  []packages.Variable{{Name: "paths"}},
+	}
+
+	variables := streamTemplateVariables(&manifest, "logs", stream)
+	var names []string
+	for _, v := range variables {
+		names = append(names, v.Name)
+	}
+	assert.Equal(t, []string{"api_key", "timeout", "paths"}, names)
+	assert.Equal(t, "integer", variables[1].Type)
+}
+
+// Ensure that variables read from manifests can be used in templates.
+func TestTemplateVarVariantsFromManifest(t *testing.T) {
+	cfg, err := ucfg.NewFrom(map[string]any{
+		"name":    "hosts",
+		"type":    "text",
+		"multi":   true,
+		"default": []any{"localhost:9200"},
+	})
+	require.NoError(t, err)
+
+	var v packages.Variable
+	require.NoError(t, cfg.Unpack(&v))
+
+	variants := templateVarVariants(v)
+	assert.Equal(t, []any{[]any{"localhost:9200"}, unsetVar{}}, variants)
+}
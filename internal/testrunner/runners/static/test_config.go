@@ -18,6 +18,10 @@ import (
 
 type testConfig struct {
 	testrunner.SkippableConfig `config:",inline"`
+
+	// MaxTemplateCombinations is the maximum number of combinations of variables
+	// used to render each agent stream template.
+	MaxTemplateCombinations int `config:"max_template_combinations"`
 }
 
 func newConfig(staticTestFolderPath string) (*testConfig, error) {
@@ -101,8 +101,27 @@ func (r tester) run(ctx context.Context) ([]testrunner.TestResult, error) {
 		return result.WithError(fmt.Errorf("failed to read manifest: %w", err))
 	}
 
-	// join together results from verifyStreamConfig and verifySampleEvent
-	return append(r.verifyStreamConfig(ctx, r.packageRoot), r.verifySampleEvent(pkgManifest)...), nil
+	maxTemplateCombinations := 0
+	if testConfig != nil {
+		maxTemplateCombinations = testConfig.MaxTemplateCombinations
+	}
+
+	hasDataStreams, err := testrunner.PackageHasDataStreams(pkgManifest)
+	if err != nil {
+		return result.WithError(fmt.Errorf("cannot determine if package has data streams: %w", err))
+	}
+
+	// join together results from verifyStreamConfig, verifySampleEvent, verifyStreamTemplates, verifyDashboardFields
+	// and verifyFieldConflicts
+	var results []testrunner.TestResult
+	if r.testFolder.DataStream != "" || !hasDataStreams {
+		// The package-level folder of packages with data streams is only used
+		// to verify package-level resources.
+		results = append(r.verifyStreamConfig(ctx, r.packageRoot), r.verifySampleEvent(pkgManifest)...)
+		results = append(results, r.verifyStreamTemplates(pkgManifest, maxTemplateCombinations)...)
+	}
+	results = append(results, r.verifyDashboardFields(pkgManifest)...)
+	return append(results, r.verifyFieldConflicts()...), nil
 }
 
 func (r tester) verifyStreamConfig(ctx context.Context, packageRoot string) []testrunner.TestResult {
@@ -0,0 +1,420 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package system
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/elastic/elastic-package/internal/common"
+	"github.com/elastic/elastic-package/internal/logger"
+	"github.com/elastic/elastic-package/internal/multierror"
+	"github.com/elastic/elastic-package/internal/stack"
+	"github.com/elastic/elastic-package/internal/testrunner"
+)
+
+// indexModesMinimumStackVersion is the first version supporting the source mode as index setting.
+var indexModesMinimumStackVersion = semver.MustParse("8.17.0")
+
+// indexModeSettings contains the index settings used to run system tests with each index mode.
+var indexModeSettings = map[string]common.MapStr{
+	"standard": {
+		"index.mode":                "standard",
+		"index.mapping.source.mode": "stored",
+	},
+	"logsdb": {
+		"index.mode": "logsdb",
+	},
+	"synthetic": {
+		"index.mapping.source.mode": "synthetic",
+	},
+}
+
+// indexModeComparisonSkippedFields are the families of fields whose values are expected to change
+// between test runs, as they depend on the agent or service deployed for each run.
+var indexModeComparisonSkippedFields = []string{
+	"agent",
+	"container",
+	"elastic_agent",
+	"event.created",
+	"event.ingested",
+	"host",
+	"log.file.device_id",
+	"log.file.fingerprint",
+	"log.file.inode",
+}
+
+// indexModeDocumentKeys are the fields used to find the same event in the documents retrieved
+// with different index modes, in order of preference.
+var indexModeDocumentKeys = []string{
+	"event.original",
+	"message",
+}
+
+// IndexModesList returns the list of index modes that can be used to run system tests.
+func IndexModesList() []string {
+	modes := make([]string, 0, len(indexModeSettings))
+	for mode := range indexModeSettings {
+		modes = append(modes, mode)
+	}
+	slices.Sort(modes)
+	return modes
+}
+
+// runTestPerIndexMode runs the test once for each index mode, and compares the documents
+// retrieved in each mode with the ones retrieved in the first one.
+func (r *tester) runTestPerIndexMode(ctx context.Context, stackConfig stack.Config, result *testrunner.ResultComposer) ([]testrunner.TestResult, error) {
+	stackVersion, err := semver.NewVersion(r.stackVersion.Number)
+	if err != nil {
+		return result.WithErrorf("failed to parse stack version: %w", err)
+	}
+	if stackVersion.LessThan(indexModesMinimumStackVersion) {
+		return result.WithErrorf("running system tests with different index modes requires stack version %s or later", indexModesMinimumStackVersion)
+	}
+	if r.isTimeSeriesDataStream() {
+		return result.WithErrorf("index mode cannot be overridden in time series data streams")
+	}
+
+	defer func() {
+		r.indexMode = ""
+		r.indexModeDocs = nil
+	}()
+	r.indexModeDocs = make(map[string][]common.MapStr)
+
+	var results []testrunner.TestResult
+	var testName string
+	for _, mode := range r.indexModes {
+		logger.Infof("Running test with index mode %q", mode)
+		r.indexMode = mode
+		partial, err := r.runTestPerVariant(ctx, stackConfig, result, r.configFileName, r.serviceVariant)
+		if len(partial) > 0 {
+			testName = partial[0].Name
+		}
+		for i := range partial {
+			partial[i].Name = fmt.Sprintf("%s (index mode: %s)", partial[i].Name, mode)
+		}
+		results = append(results, partial...)
+		if err != nil {
+			return results, err
+		}
+		if len(partial) > 0 && partial[0].Skipped != nil {
+			return results, nil
+		}
+	}
+
+	var compared []string
+	for _, mode := range r.indexModes {
+		if _, found := r.indexModeDocs[mode]; found {
+			compared = append(compared, mode)
+		}
+	}
+	if len(compared) < 2 {
+		logger.Debugf("Not enough successful runs to compare documents between index modes")
+		return results, nil
+	}
+
+	comparison := r.newResult(fmt.Sprintf("%s (index modes: %s)", testName, strings.Join(compared, ",")))
+	reference := compared[0]
+	var errs multierror.Error
+	for _, mode := range compared[1:] {
+		errs = append(errs, compareIndexModeDocs(reference, r.indexModeDocs[reference], mode, r.indexModeDocs[mode])...)
+	}
+	var comparisonResults []testrunner.TestResult
+	if len(errs) > 0 {
+		comparisonResults, _ = comparison.WithError(testrunner.ErrTestCaseFailed{
+			Reason:  fmt.Sprintf("fields lost or altered in documents retrieved with index modes different to %s", reference),
+			Details: errs.Error(),
+		})
+	} else {
+		comparisonResults, _ = comparison.WithSuccess()
+	}
+	return append(results, comparisonResults...), nil
+}
+
+// installIndexModeTemplate installs the custom component template of the index template used by the
+// test, so new data streams are created with the settings of the index mode. If there is already a
+// custom component template, the settings are merged into it, and it is restored when the returned
+// function is called.
+func (r *tester) installIndexModeTemplate(ctx context.Context, indexTemplateName, mode string) (func(context.Context) error, error) {
+	name := indexTemplateName + "@custom"
+
+	previous, err := r.getComponentTemplate(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	template, err := indexModeComponentTemplate(previous, mode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare component template %s: %w", name, err)
+	}
+	body, err := json.Marshal(template)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode component template %s: %w", name, err)
+	}
+	logger.Debugf("Installing component template %s for index mode %q", name, mode)
+	if err := r.putComponentTemplate(ctx, name, body); err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context) error {
+		if previous != nil {
+			logger.Debugf("Restoring component template %s", name)
+			return r.putComponentTemplate(ctx, name, previous)
+		}
+		logger.Debugf("Deleting component template %s", name)
+		return r.deleteComponentTemplate(ctx, name)
+	}, nil
+}
+
+// indexModeComponentTemplate returns the component template with the settings of the index mode,
+// merged into the previous definition of the component template, if any.
+func indexModeComponentTemplate(previous json.RawMessage, mode string) (common.MapStr, error) {
+	settings, found := indexModeSettings[mode]
+	if !found {
+		return nil, fmt.Errorf("unknown index mode %q", mode)
+	}
+
+	if previous == nil {
+		return common.MapStr{
+			"template": common.MapStr{
+				"settings": settings,
+			},
+			"_meta": common.MapStr{
+				"managed_by":  "elastic-package",
+				"description": fmt.Sprintf("Settings to run system tests with %s index mode", mode),
+			},
+		}, nil
+	}
+
+	var template common.MapStr
+	if err := json.Unmarshal(previous, &template); err != nil {
+		return nil, fmt.Errorf("failed to decode component template: %w", err)
+	}
+	for key, value := range settings {
+		// Settings can be defined with dotted or nested keys, keep only the nested one.
+		if current, err := template.GetValue("template.settings"); err == nil {
+			if currentSettings, err := common.ToMapStr(current); err == nil {
+				delete(currentSettings, key)
+			}
+		}
+		if _, err := template.Put("template.settings."+key, value); err != nil {
+			return nil, fmt.Errorf("failed to set %s setting: %w", key, err)
+		}
+	}
+	return template, nil
+}
+
+// getComponentTemplate returns the definition of the component template, or nil if it doesn't exist.
+func (r *tester) getComponentTemplate(ctx context.Context, name string) (json.RawMessage, error) {
+	resp, err := r.esAPI.Cluster.GetComponentTemplate(
+		r.esAPI.Cluster.GetComponentTemplate.WithContext(ctx),
+		r.esAPI.Cluster.GetComponentTemplate.WithName(name),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("get request failed for component template %s: %w", name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.IsError() {
+		return nil, fmt.Errorf("get request failed for component template %s: %s", name, resp.String())
+	}
+
+	var templates struct {
+		ComponentTemplates []struct {
+			ComponentTemplate json.RawMessage `json:"component_template"`
+		} `json:"component_templates"`
+	}
+	d, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if err := json.Unmarshal(d, &templates); err != nil {
+		return nil, fmt.Errorf("failed to decode component template %s: %w", name, err)
+	}
+	if len(templates.ComponentTemplates) == 0 {
+		return nil, nil
+	}
+	return templates.ComponentTemplates[0].ComponentTemplate, nil
+}
+
+func (r *tester) putComponentTemplate(ctx context.Context, name string, body []byte) error {
+	resp, err := r.esAPI.Cluster.PutComponentTemplate(name, bytes.NewReader(body),
+		r.esAPI.Cluster.PutComponentTemplate.WithContext(ctx),
+	)
+	if err != nil {
+		return fmt.Errorf("put request failed for component template %s: %w", name, err)
+	}
+	defer resp.Body.Close()
+	if resp.IsError() {
+		return fmt.Errorf("put request failed for component template %s: %s", name, resp.String())
+	}
+	return nil
+}
+
+func (r *tester) deleteComponentTemplate(ctx context.Context, name string) error {
+	resp, err := r.esAPI.Cluster.DeleteComponentTemplate(name,
+		r.esAPI.Cluster.DeleteComponentTemplate.WithContext(ctx),
+	)
+	if err != nil {
+		return fmt.Errorf("delete request failed for component template %s: %w", name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if resp.IsError() {
+		return fmt.Errorf("delete request failed for component template %s: %s", name, resp.String())
+	}
+	return nil
+}
+
+// compareIndexModeDocs reports the fields found in the reference documents that are not found in
+// any of the other documents, and the fields whose values are different in the documents of the
+// same event. Events are identified by the fields in indexModeDocumentKeys, documents without
+// them are only used to look for lost fields.
+func compareIndexModeDocs(referenceMode string, referenceDocs []common.MapStr, mode string, docs []common.MapStr) multierror.Error {
+	var errs multierror.Error
+
+	reference := flattenIndexModeDocs(referenceDocs)
+	compared := flattenIndexModeDocs(docs)
+
+	seen := make(map[string]struct{})
+	for _, doc := range compared {
+		for key := range doc {
+			seen[key] = struct{}{}
+		}
+	}
+	var lost []string
+	for _, doc := range reference {
+		for key := range doc {
+			if _, found := seen[key]; found || slices.Contains(lost, key) {
+				continue
+			}
+			lost = append(lost, key)
+		}
+	}
+	slices.Sort(lost)
+	for _, key := range lost {
+		errs = append(errs, fmt.Errorf("field %q found with index mode %s is lost with index mode %s", key, referenceMode, mode))
+	}
+
+	byEvent := make(map[string]map[string]any)
+	for _, doc := range compared {
+		if key := indexModeDocumentKey(doc); key != "" {
+			byEvent[key] = doc
+		}
+	}
+	altered := make(map[string]struct{})
+	for _, doc := range reference {
+		other, found := byEvent[indexModeDocumentKey(doc)]
+		if !found {
+			continue
+		}
+		for _, key := range sortedMapKeys(doc) {
+			if _, found := altered[key]; found || skipIndexModeComparison(key) {
+				continue
+			}
+			value, found := other[key]
+			if !found || equalIndexModeValues(doc[key], value) {
+				continue
+			}
+			altered[key] = struct{}{}
+			errs = append(errs, fmt.Errorf("field %q is altered with index mode %s: %v (%s) != %v (%s)", key, mode, doc[key], referenceMode, value, mode))
+		}
+	}
+
+	return errs
+}
+
+// flattenIndexModeDocs returns the documents with their fields as dotted keys, so documents with
+// objects and with dotted keys can be compared.
+func flattenIndexModeDocs(docs []common.MapStr) []map[string]any {
+	flattened := make([]map[string]any, len(docs))
+	for i, doc := range docs {
+		flattened[i] = make(map[string]any)
+		flattenIndexModeDoc(flattened[i], "", doc)
+	}
+	return flattened
+}
+
+func flattenIndexModeDoc(result map[string]any, prefix string, elem map[string]any) {
+	for name, value := range elem {
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+		switch value := value.(type) {
+		case map[string]any:
+			flattenIndexModeDoc(result, key, value)
+		case common.MapStr:
+			flattenIndexModeDoc(result, key, value)
+		default:
+			result[key] = value
+		}
+	}
+}
+
+func indexModeDocumentKey(doc map[string]any) string {
+	for _, key := range indexModeDocumentKeys {
+		if value, found := doc[key]; found {
+			return fmt.Sprintf("%s=%v", key, normalizeIndexModeValue(value))
+		}
+	}
+	return ""
+}
+
+func skipIndexModeComparison(key string) bool {
+	for _, skipped := range indexModeComparisonSkippedFields {
+		if key == skipped || strings.HasPrefix(key, skipped+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// equalIndexModeValues compares two values of a field. Arrays with a single value are equal to
+// the value, and the order of the values in arrays is not considered, as synthetic source can
+// return them sorted.
+func equalIndexModeValues(a, b any) bool {
+	return reflect.DeepEqual(normalizeIndexModeValue(a), normalizeIndexModeValue(b))
+}
+
+func normalizeIndexModeValue(value any) any {
+	values, ok := value.([]any)
+	if !ok {
+		return value
+	}
+	if len(values) == 1 {
+		return normalizeIndexModeValue(values[0])
+	}
+	normalized := make([]any, len(values))
+	for i, v := range values {
+		normalized[i] = normalizeIndexModeValue(v)
+	}
+	slices.SortFunc(normalized, func(a, b any) int {
+		return strings.Compare(fmt.Sprintf("%v", a), fmt.Sprintf("%v", b))
+	})
+	return normalized
+}
+
+func sortedMapKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,129 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package system
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-package/internal/common"
+)
+
+func TestCompareIndexModeDocs(t *testing.T) {
+	cases := []struct {
+		title     string
+		reference []common.MapStr
+		docs      []common.MapStr
+		expected  []string
+	}{
+		{
+			title: "same documents",
+			reference: []common.MapStr{
+				{"message": "foo", "source": common.MapStr{"ip": "10.0.0.1", "port": float64(80)}},
+			},
+			docs: []common.MapStr{
+				{"message": "foo", "source.ip": "10.0.0.1", "source.port": []any{float64(80)}},
+			},
+		},
+		{
+			title: "arrays in different order",
+			reference: []common.MapStr{
+				{"message": "foo", "tags": []any{"b", "a"}},
+			},
+			docs: []common.MapStr{
+				{"message": "foo", "tags": []any{"a", "b"}},
+			},
+		},
+		{
+			title: "fields expected to change between runs",
+			reference: []common.MapStr{
+				{"message": "foo", "agent": common.MapStr{"id": "1"}, "event": common.MapStr{"ingested": "2024-01-01T00:00:00Z"}},
+			},
+			docs: []common.MapStr{
+				{"message": "foo", "agent": common.MapStr{"id": "2"}, "event": common.MapStr{"ingested": "2024-01-01T00:00:10Z"}},
+			},
+		},
+		{
+			title: "lost fields",
+			reference: []common.MapStr{
+				{"message": "foo", "foo": common.MapStr{"bar": "a", "baz": "b"}},
+			},
+			docs: []common.MapStr{
+				{"message": "foo", "foo": common.MapStr{"bar": "a"}},
+			},
+			expected: []string{
+				`field "foo.baz" found with index mode standard is lost with index mode synthetic`,
+			},
+		},
+		{
+			title: "altered fields",
+			reference: []common.MapStr{
+				{"event": common.MapStr{"original": "foo"}, "message": "foo", "values": []any{"a", "a"}},
+				{"event": common.MapStr{"original": "bar"}, "message": "foo", "values": []any{"b", "b"}},
+			},
+			docs: []common.MapStr{
+				{"event": common.MapStr{"original": "bar"}, "message": "foo", "values": []any{"b", "b"}},
+				{"event": common.MapStr{"original": "foo"}, "message": "foo", "values": "a"},
+			},
+			expected: []string{
+				`field "values" is altered with index mode synthetic: [a a] (standard) != a (synthetic)`,
+			},
+		},
+		{
+			title: "documents without keys",
+			reference: []common.MapStr{
+				{"foo": "a"},
+			},
+			docs: []common.MapStr{
+				{"foo": "b"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			errs := compareIndexModeDocs("standard", c.reference, "synthetic", c.docs)
+
+			var messages []string
+			for _, err := range errs {
+				messages = append(messages, err.Error())
+			}
+			assert.Equal(t, c.expected, messages)
+		})
+	}
+}
+
+func TestIndexModeComponentTemplate(t *testing.T) {
+	template, err := indexModeComponentTemplate(nil, "logsdb")
+	require.NoError(t, err)
+	assert.Equal(t, common.MapStr{"index.mode": "logsdb"}, template["template"].(common.MapStr)["settings"])
+	assert.Contains(t, template, "_meta")
+
+	previous := json.RawMessage(`{
+		"template": {
+			"settings": {"index.mode": "time_series", "index": {"number_of_replicas": "0"}},
+			"mappings": {"properties": {"foo": {"type": "keyword"}}}
+		},
+		"_meta": {"managed_by": "someone"}
+	}`)
+	template, err = indexModeComponentTemplate(previous, "standard")
+	require.NoError(t, err)
+
+	d, err := json.Marshal(template)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"template": {
+			"settings": {"index": {"number_of_replicas": "0", "mode": "standard", "mapping": {"source": {"mode": "stored"}}}},
+			"mappings": {"properties": {"foo": {"type": "keyword"}}}
+		},
+		"_meta": {"managed_by": "someone"}
+	}`, string(d))
+
+	_, err = indexModeComponentTemplate(nil, "unknown")
+	assert.Error(t, err)
+}
@@ -32,9 +32,11 @@ type runner struct {
 	esAPI          *elasticsearch.API
 	esClient       *elasticsearch.Client
 	schemaURLs     fields.SchemaURLs
+	fieldsUsage    *fields.FieldsUsage
 
 	dataStreams          []string
 	serviceVariant       string
+	indexModes           []string
 	overrideAgentVersion string
 
 	globalTestConfig   testrunner.GlobalRunnerTestConfig
@@ -65,12 +67,18 @@ type SystemTestRunnerOptions struct {
 	OverrideAgentVersion string
 	SchemaURLs           fields.SchemaURLs
 
+	// FieldsUsage, if set, aggregates the fields seen in the validated documents.
+	FieldsUsage *fields.FieldsUsage
+
 	// FIXME: Keeping Elasticsearch client to be able to do low-level requests for parameters not supported yet by the API.
 	ESClient *elasticsearch.Client
 
 	DataStreams    []string
 	ServiceVariant string
 
+	// IndexModes, if set, are the index modes to run each test with.
+	IndexModes []string
+
 	RunSetup       bool
 	RunTearDown    bool
 	RunTestsOnly   bool
@@ -93,8 +101,10 @@ func NewSystemTestRunner(options SystemTestRunnerOptions) *runner {
 		esClient:             options.ESClient,
 		profile:              options.Profile,
 		schemaURLs:           options.SchemaURLs,
+		fieldsUsage:          options.FieldsUsage,
 		dataStreams:          options.DataStreams,
 		serviceVariant:       options.ServiceVariant,
+		indexModes:           options.IndexModes,
 		configFilePath:       options.ConfigFilePath,
 		runSetup:             options.RunSetup,
 		runTestsOnly:         options.RunTestsOnly,
@@ -262,6 +272,7 @@ func (r *runner) GetTests(ctx context.Context) ([]testrunner.Tester, error) {
 					API:                  r.esAPI,
 					ESClient:             r.esClient,
 					SchemaURLs:           r.schemaURLs,
+					FieldsUsage:          r.fieldsUsage,
 					TestFolder:           t,
 					ServiceVariant:       variant,
 					GenerateTestResult:   r.generateTestResult,
@@ -270,6 +281,7 @@ func (r *runner) GetTests(ctx context.Context) ([]testrunner.Tester, error) {
 					RunTestsOnly:         r.runTestsOnly,
 					RunTearDown:          r.runTearDown,
 					ConfigFileName:       config,
+					IndexModes:           r.indexModes,
 					GlobalTestConfig:     r.globalTestConfig,
 					WithCoverage:         r.withCoverage,
 					CoverageType:         r.coverageType,
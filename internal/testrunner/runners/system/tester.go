@@ -204,6 +204,7 @@ type tester struct {
 	esClient           *elasticsearch.Client
 	kibanaClient       *kibana.Client
 	schemaURLs         fields.SchemaURLs
+	fieldsUsage        *fields.FieldsUsage
 
 	runIndependentElasticAgent bool
 
@@ -213,6 +214,13 @@ type tester struct {
 	serviceVariant string
 	configFileName string
 
+	// indexModes are the index modes to run the test with, the test runs once with each of them.
+	indexModes []string
+	// indexMode is the index mode of the current run, if any.
+	indexMode string
+	// indexModeDocs contains the documents retrieved with each index mode.
+	indexModeDocs map[string][]common.MapStr
+
 	runSetup     bool
 	runTearDown  bool
 	runTestsOnly bool
@@ -243,6 +251,7 @@ type tester struct {
 	resetAgentLogLevelHandler func(context.Context) error
 	shutdownServiceHandler    func(context.Context) error
 	shutdownAgentHandler      func(context.Context) error
+	restoreIndexModeHandler   func(context.Context) error
 }
 
 type SystemTesterOptions struct {
@@ -253,6 +262,7 @@ type SystemTesterOptions struct {
 	API                *elasticsearch.API
 	KibanaClient       *kibana.Client
 	SchemaURLs         fields.SchemaURLs
+	FieldsUsage        *fields.FieldsUsage
 
 	OverrideAgentVersion string
 
@@ -262,6 +272,7 @@ type SystemTesterOptions struct {
 	DeferCleanup     time.Duration
 	ServiceVariant   string
 	ConfigFileName   string
+	IndexModes       []string
 	GlobalTestConfig testrunner.GlobalRunnerTestConfig
 	WithCoverage     bool
 	CoverageType     string
@@ -281,9 +292,11 @@ func NewSystemTester(options SystemTesterOptions) (*tester, error) {
 		esClient:                   options.ESClient,
 		kibanaClient:               options.KibanaClient,
 		schemaURLs:                 options.SchemaURLs,
+		fieldsUsage:                options.FieldsUsage,
 		deferCleanup:               options.DeferCleanup,
 		serviceVariant:             options.ServiceVariant,
 		configFileName:             options.ConfigFileName,
+		indexModes:                 options.IndexModes,
 		runSetup:                   options.RunSetup,
 		runTestsOnly:               options.RunTestsOnly,
 		runTearDown:                options.RunTearDown,
@@ -634,6 +647,14 @@ func (r *tester) tearDownTest(ctx context.Context) error {
 		r.cleanTestScenarioHandler = nil
 	}
 
+	// Component templates for index modes are restored once the data stream has been deleted.
+	if r.restoreIndexModeHandler != nil {
+		if err := r.restoreIndexModeHandler(cleanupCtx); err != nil {
+			return err
+		}
+		r.restoreIndexModeHandler = nil
+	}
+
 	if r.resetAgentLogLevelHandler != nil {
 		if err := r.resetAgentLogLevelHandler(cleanupCtx); err != nil {
 			return err
@@ -679,7 +700,11 @@ func (r *tester) run(ctx context.Context, stackConfig stack.Config) (results []t
 
 	startTesting := time.Now()
 
-	results, err = r.runTestPerVariant(ctx, stackConfig, result, r.configFileName, r.serviceVariant)
+	if len(r.indexModes) > 0 {
+		results, err = r.runTestPerIndexMode(ctx, stackConfig, result)
+	} else {
+		results, err = r.runTestPerVariant(ctx, stackConfig, result, r.configFileName, r.serviceVariant)
+	}
 	if err != nil {
 		return results, err
 	}
@@ -1121,6 +1146,17 @@ func (r *tester) prepareScenario(ctx context.Context, config *testConfig, stackC
 	scenario.indexTemplateName = BuildIndexTemplateName(ds, policyTemplate, r.pkgManifest.Type, config.Vars)
 	scenario.dataStream = BuildDataStreamName(ds, policyTemplate, r.pkgManifest.Type, config.Vars)
 
+	if r.indexMode != "" {
+		r.restoreIndexModeHandler, err = r.installIndexModeTemplate(ctx, scenario.indexTemplateName, r.indexMode)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set index mode %q: %w", r.indexMode, err)
+		}
+		// Ensure that the data stream is created with the settings of the index mode.
+		if err := r.deleteDataStream(ctx, scenario.dataStream); err != nil {
+			return nil, fmt.Errorf("failed to delete data stream %s: %w", scenario.dataStream, err)
+		}
+	}
+
 	r.cleanTestScenarioHandler = func(ctx context.Context) error {
 		logger.Debugf("Deleting data stream for testing %s", scenario.dataStream)
 		err := r.deleteDataStream(ctx, scenario.dataStream)
@@ -1683,18 +1719,33 @@ func (r *tester) validateTestScenario(ctx context.Context, result *testrunner.Re
 		fields.WithDisableNormalization(scenario.syntheticEnabled),
 		// When using the OTel collector input, just a subset of validations are performed (e.g. check expected datasets)
 		fields.WithOTelValidation(r.isTestUsingOTelCollectorInput(scenario.policyTemplate.Input)),
+		fields.WithSeenFieldsTracking(r.fieldsUsage != nil),
 	)
 	if err != nil {
 		return result.WithErrorf("creating fields validator for data stream failed (path: %s): %w", fieldsDir, err)
 	}
 
-	if errs := validateFields(scenario.docs, fieldsValidator); len(errs) > 0 {
+	errs := validateFields(scenario.docs, fieldsValidator)
+	if r.fieldsUsage != nil {
+		r.fieldsUsage.Add(fieldsValidator)
+	}
+	if len(errs) > 0 {
 		return result.WithError(testrunner.ErrTestCaseFailed{
 			Reason:  fmt.Sprintf("one or more errors found in documents stored in %s data stream", scenario.dataStream),
 			Details: errs.Error(),
 		})
 	}
 
+	if r.isTimeSeriesDataStream() {
+		logger.Debug("Performing validation of time series documents")
+		if errs := fieldsValidator.ValidateTimeSeriesDocuments(scenario.docs); len(errs) > 0 {
+			return result.WithError(testrunner.ErrTestCaseFailed{
+				Reason:  fmt.Sprintf("one or more time series errors found in documents stored in %s data stream", scenario.dataStream),
+				Details: errs.Error(),
+			})
+		}
+	}
+
 	if !r.isTestUsingOTelCollectorInput(scenario.policyTemplate.Input) && r.fieldValidationMethod == mappingsMethod {
 		logger.Debug("Performing validation based on mappings")
 		exceptionFields := listExceptionFields(scenario.docs, fieldsValidator)
@@ -1736,6 +1787,10 @@ func (r *tester) validateTestScenario(ctx context.Context, result *testrunner.Re
 		}
 	}
 
+	if r.indexMode != "" {
+		r.indexModeDocs[r.indexMode] = docs
+	}
+
 	specVersion, err := semver.NewVersion(r.pkgManifest.SpecVersion)
 	if err != nil {
 		return result.WithErrorf("failed to parse format version %q: %w", r.pkgManifest.SpecVersion, err)
@@ -1772,7 +1827,7 @@ func (r *tester) validateTestScenario(ctx context.Context, result *testrunner.Re
 	}
 
 	if r.withCoverage {
-		coverage, err := r.generateCoverageReport(result.CoveragePackageName())
+		coverage, err := r.generateCoverageReport(result.CoveragePackageName(), fieldsDir)
 		if err != nil {
 			return result.WithErrorf("coverage report generation failed: %w", err)
 		}
@@ -1862,6 +1917,14 @@ func (r *tester) runTest(ctx context.Context, config *testConfig, stackConfig st
 	return r.validateTestScenario(ctx, result, scenario, config)
 }
 
+// isTimeSeriesDataStream returns true if the data stream under test uses the time series index mode.
+func (r *tester) isTimeSeriesDataStream() bool {
+	if r.dataStreamManifest == nil || r.dataStreamManifest.Elasticsearch == nil {
+		return false
+	}
+	return r.dataStreamManifest.Elasticsearch.IndexMode == "time_series"
+}
+
 func (r *tester) isTestUsingOTelCollectorInput(policyTemplateInput string) bool {
 	// Just supported for input packages currently
 	if r.pkgManifest.Type != "input" {
@@ -2716,7 +2779,7 @@ func (r *tester) anyErrorMessages(logsFilePath string, startTime time.Time, erro
 	return nil
 }
 
-func (r *tester) generateCoverageReport(pkgName string) (testrunner.CoverageReport, error) {
+func (r *tester) generateCoverageReport(pkgName string, fieldsDir string) (testrunner.CoverageReport, error) {
 	dsPattern := "*"
 	if r.dataStreamManifest != nil && r.dataStreamManifest.Name != "" {
 		dsPattern = r.dataStreamManifest.Name
@@ -2725,10 +2788,34 @@ func (r *tester) generateCoverageReport(pkgName string) (testrunner.CoverageRepo
 	// This list of patterns includes patterns for all types of packages. It should not be a problem if some path doesn't exist.
 	patterns := []string{
 		filepath.Join(r.packageRoot, "manifest.yml"),
-		filepath.Join(r.packageRoot, "fields", "*.yml"),
 		filepath.Join(r.packageRoot, "data_stream", dsPattern, "manifest.yml"),
-		filepath.Join(r.packageRoot, "data_stream", dsPattern, "fields", "*.yml"),
+	}
+	if r.fieldsUsage == nil {
+		patterns = append(patterns,
+			filepath.Join(r.packageRoot, "fields", "*.yml"),
+			filepath.Join(r.packageRoot, "data_stream", dsPattern, "fields", "*.yml"),
+		)
 	}
 
-	return testrunner.GenerateBaseFileCoverageReportGlob(pkgName, patterns, r.coverageType, true)
+	coverage, err := testrunner.GenerateBaseFileCoverageReportGlob(pkgName, patterns, r.coverageType, true)
+	if err != nil || r.fieldsUsage == nil {
+		return coverage, err
+	}
+
+	// Only the lines of the fields seen in documents are covered.
+	fieldsCoverage, err := testrunner.GenerateFieldsUsageCoverageReport(pkgName, r.fieldsUsage, fieldsDir, r.coverageType)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case fieldsCoverage == nil:
+		return coverage, nil
+	case coverage == nil:
+		return fieldsCoverage, nil
+	}
+	err = coverage.Merge(fieldsCoverage)
+	if err != nil {
+		return nil, fmt.Errorf("cannot merge fields coverage: %w", err)
+	}
+	return coverage, nil
 }
@@ -62,7 +62,7 @@ func installAgent(ts *testscript.TestScript, neg bool, args []string) {
 		ts.Fatalf("no active client for %s", *profName)
 	}
 
-	ctx := context.Background()
+	ctx := scriptContext(ts)
 	if *timeout > 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, *timeout)
@@ -197,7 +197,7 @@ func uninstallAgent(ts *testscript.TestScript, neg bool, args []string) {
 		ts.Fatalf("agent policy in %s is not installed", *profName)
 	}
 
-	ctx := context.Background()
+	ctx := scriptContext(ts)
 	if *timeout > 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, *timeout)
@@ -213,6 +213,74 @@ func uninstallAgent(ts *testscript.TestScript, neg bool, args []string) {
 	fmt.Fprintf(ts.Stdout(), "deleted agent policies for %s/%s (testing:%s enrolled:%s)\n", pkg, ds, installed.testingPolicy.ID, installed.enrolledPolicy.ID)
 }
 
+// agentStatus prints the status of the installed agent, optionally waiting for it
+// to reach a given status.
+func agentStatus(ts *testscript.TestScript, neg bool, args []string) {
+	clearStdStreams(ts)
+
+	if neg {
+		ts.Fatalf("unsupported: ! agent_status")
+	}
+
+	stacks, ok := ts.Value(runningStackTag{}).(map[string]*runningStack)
+	if !ok {
+		ts.Fatalf("no active stacks registry")
+	}
+	agents, ok := ts.Value(installedAgentsTag{}).(map[string]*installedAgent)
+	if !ok {
+		ts.Fatalf("no installed installed agent registry")
+	}
+
+	flg := flag.NewFlagSet("agent_status", flag.ContinueOnError)
+	profName := flg.String("profile", "default", "profile name")
+	want := flg.String("want", "", "status to wait for (empty indicates no wait)")
+	timeout := flg.Duration("timeout", time.Minute, "timeout (zero or lower indicates no timeout)")
+	ts.Check(flg.Parse(args))
+	if flg.NArg() != 0 {
+		ts.Fatalf("usage: agent_status [-profile <profile>] [-timeout <duration>] [-want <status>]")
+	}
+
+	stk, ok := stacks[*profName]
+	if !ok {
+		ts.Fatalf("no active client for %s", *profName)
+	}
+	installed, ok := agents[*profName]
+	if !ok {
+		ts.Fatalf("agent policy in %s is not installed", *profName)
+	}
+
+	ctx := scriptContext(ts)
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	status := "unknown"
+	for {
+		if ctx.Err() != nil {
+			ts.Fatalf("timed out waiting for agent status %s, last status was %s", *want, status)
+		}
+		enrolled, err := stk.kibana.ListAgents(ctx)
+		if errors.Is(err, context.DeadlineExceeded) {
+			continue
+		}
+		ts.Check(decoratedWith("getting enrolled agents", err))
+		status = "not_enrolled"
+		for _, a := range enrolled {
+			if a.ID == installed.enrolled.ID {
+				status = a.Status
+				break
+			}
+		}
+		if *want == "" || status == *want {
+			break
+		}
+		time.Sleep(time.Second)
+	}
+	fmt.Fprintln(ts.Stdout(), status)
+}
+
 type installedAgentsTag struct{}
 
 type installedAgent struct {
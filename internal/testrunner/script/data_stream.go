@@ -86,7 +86,7 @@ func addPackagePolicy(ts *testscript.TestScript, neg bool, args []string) {
 		ts.Fatalf("agent policy in %s is not installed", *profName)
 	}
 
-	ctx := context.Background()
+	ctx := scriptContext(ts)
 	if *timeout > 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, *timeout)
@@ -160,7 +160,7 @@ func removePackagePolicy(ts *testscript.TestScript, neg bool, args []string) {
 		ts.Fatalf("no data stream for %s", dsName)
 	}
 
-	ctx := context.Background()
+	ctx := scriptContext(ts)
 	if *timeout > 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, *timeout)
@@ -54,7 +54,7 @@ func getPolicyCommand(ts *testscript.TestScript, neg bool, args []string) {
 		ts.Fatalf("no active client for %s", *profName)
 	}
 
-	ctx := context.Background()
+	ctx := scriptContext(ts)
 	if *timeout > 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, *timeout)
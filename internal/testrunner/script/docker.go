@@ -52,7 +52,7 @@ func dockerUp(ts *testscript.TestScript, neg bool, args []string) {
 	_, err := os.Stat(compose)
 	ts.Check(err)
 
-	ctx := context.Background()
+	ctx := scriptContext(ts)
 	if *timeout > 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, *timeout)
@@ -115,7 +115,7 @@ func dockerSignal(ts *testscript.TestScript, neg bool, args []string) {
 		ts.Fatalf("service %s is not deployed", name)
 	}
 
-	ctx := context.Background()
+	ctx := scriptContext(ts)
 	if *timeout > 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, *timeout)
@@ -150,7 +150,7 @@ func dockerWaitExit(ts *testscript.TestScript, neg bool, args []string) {
 		ts.Fatalf("service %s is not deployed", name)
 	}
 
-	ctx := context.Background()
+	ctx := scriptContext(ts)
 	if *timeout > 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, *timeout)
@@ -192,7 +192,7 @@ func dockerDown(ts *testscript.TestScript, neg bool, args []string) {
 		ts.Fatalf("service %s is not deployed", name)
 	}
 
-	ctx := context.Background()
+	ctx := scriptContext(ts)
 	if *timeout > 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, *timeout)
@@ -0,0 +1,301 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package script
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/PaesslerAG/jsonpath"
+	"github.com/rogpeppe/go-internal/testscript"
+
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// waitForDocs waits until the current data stream or a named data stream contains
+// at least a minimum number of documents, optionally matching a query.
+func waitForDocs(ts *testscript.TestScript, neg bool, args []string) {
+	clearStdStreams(ts)
+
+	if neg {
+		ts.Fatalf("unsupported: ! wait_for_docs")
+	}
+
+	stacks, ok := ts.Value(runningStackTag{}).(map[string]*runningStack)
+	if !ok {
+		ts.Fatalf("no active stacks registry")
+	}
+
+	flg := flag.NewFlagSet("wait_for_docs", flag.ContinueOnError)
+	profName := flg.String("profile", "default", "profile name")
+	minCount := flg.Int("min", 1, "minimum number of documents expected")
+	queryPath := flg.String("query", "", "path to a file containing the query to filter documents")
+	timeout := flg.Duration("timeout", time.Minute, "timeout (zero or lower indicates no timeout)")
+	ts.Check(flg.Parse(args))
+	if flg.NArg() != 0 && flg.NArg() != 1 {
+		ts.Fatalf("usage: wait_for_docs [-profile <profile>] [-timeout <duration>] [-min <count>] [-query <query_path>] [<data_stream>]")
+	}
+
+	ds := ts.Getenv("DATA_STREAM")
+	if flg.NArg() == 1 {
+		ds = flg.Arg(0)
+	}
+	if ds == "" {
+		ts.Fatalf("no data stream specified")
+	}
+
+	stk, ok := stacks[*profName]
+	if !ok {
+		ts.Fatalf("no active client for %s", *profName)
+	}
+
+	var query []byte
+	if *queryPath != "" {
+		q, err := os.ReadFile(ts.MkAbs(*queryPath))
+		ts.Check(decoratedWith("reading query", err))
+		query, err = json.Marshal(map[string]json.RawMessage{"query": q})
+		ts.Check(decoratedWith("preparing query", err))
+	}
+
+	ctx := scriptContext(ts)
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	count := 0
+	for {
+		if ctx.Err() != nil {
+			ts.Fatalf("timed out waiting for %d documents in data stream %s, found %d", *minCount, ds, count)
+		}
+
+		opts := []func(*esapi.CountRequest){
+			stk.es.Count.WithContext(ctx),
+			stk.es.Count.WithIndex(ds),
+			stk.es.Count.WithIgnoreUnavailable(true),
+		}
+		if query != nil {
+			opts = append(opts, stk.es.Count.WithBody(bytes.NewReader(query)))
+		}
+		resp, err := stk.es.Count(opts...)
+		if errors.Is(err, context.DeadlineExceeded) {
+			continue
+		}
+		ts.Check(decoratedWith("counting documents", err))
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		ts.Check(decoratedWith("reading count result", err))
+
+		switch {
+		case resp.StatusCode == http.StatusServiceUnavailable && bytes.Contains(body, []byte("no_shard_available_action_exception")):
+			// Index is being created, but no shards are available yet.
+		case resp.StatusCode >= 300:
+			ts.Fatalf("failed to count docs in data stream %s: %s", ds, body)
+		default:
+			var res struct {
+				Count int `json:"count"`
+			}
+			ts.Check(decoratedWith("unmarshaling result", json.Unmarshal(body, &res)))
+			count = res.Count
+			if count >= *minCount {
+				fmt.Fprintf(ts.Stdout(), "found %d documents in %s\n", count, ds)
+				return
+			}
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+// assertDocs checks an assertion on the documents stored in a file, as printed by get_docs.
+// Paths starting with "$" are evaluated as JSONPath expressions against the whole file,
+// other paths are field names that are checked in the source of each document.
+func assertDocs(ts *testscript.TestScript, neg bool, args []string) {
+	if len(args) != 2 && len(args) != 4 {
+		ts.Fatalf("usage: assert_docs <docs_path> <path> [(==|!=|=~) <value>]")
+	}
+
+	data, err := os.ReadFile(ts.MkAbs(args[0]))
+	ts.Check(decoratedWith("reading documents", err))
+	var docs any
+	ts.Check(decoratedWith("unmarshaling documents", json.Unmarshal(data, &docs)))
+
+	var a docsAssertion
+	if len(args) == 4 {
+		a, err = newDocsAssertion(args[1], args[2], args[3])
+	} else {
+		a, err = newDocsAssertion(args[1], "", "")
+	}
+	ts.Check(err)
+
+	err = a.check(docs)
+	switch {
+	case neg && err == nil:
+		ts.Fatalf("unexpected success of assertion %s", a)
+	case !neg && err != nil:
+		ts.Fatalf("assertion %s failed: %v", a, err)
+	}
+}
+
+// pluralJSONPathRegexp matches JSONPath expressions that can select multiple values,
+// such as wildcards, recursive descent, filters, unions and slices.
+var pluralJSONPathRegexp = regexp.MustCompile(`\*|\.\.|\[\?|\[[^\]]*[:,][^\]]*\]`)
+
+type docsAssertion struct {
+	path     string
+	operator string
+	value    any
+	pattern  *regexp.Regexp
+}
+
+func newDocsAssertion(path, operator, value string) (docsAssertion, error) {
+	a := docsAssertion{path: path, operator: operator}
+	switch operator {
+	case "":
+	case "==", "!=":
+		// Values that are not valid JSON are compared as strings.
+		if err := json.Unmarshal([]byte(value), &a.value); err != nil {
+			a.value = value
+		}
+	case "=~":
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return a, fmt.Errorf("invalid regular expression %q: %w", value, err)
+		}
+		a.pattern = re
+		a.value = value
+	default:
+		return a, fmt.Errorf("unknown operator %q, expected one of ==, != or =~", operator)
+	}
+	return a, nil
+}
+
+func (a docsAssertion) String() string {
+	if a.operator == "" {
+		return a.path
+	}
+	return fmt.Sprintf("%s %s %v", a.path, a.operator, a.value)
+}
+
+// check evaluates the assertion against the documents. JSONPath expressions
+// must find at least one value, and all the values found must satisfy the
+// assertion. Fields must be present and satisfy the assertion in all documents.
+func (a docsAssertion) check(docs any) error {
+	if strings.HasPrefix(a.path, "$") {
+		found, err := jsonpath.Get(a.path, docs)
+		if err != nil {
+			return fmt.Errorf("evaluating %s: %w", a.path, err)
+		}
+		values := []any{found}
+		if list, ok := found.([]any); ok && pluralJSONPathRegexp.MatchString(a.path) {
+			values = list
+		}
+		if len(values) == 0 {
+			return fmt.Errorf("no values found for %s", a.path)
+		}
+		for _, value := range values {
+			if err := a.checkValue(value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	sources := documentSources(docs)
+	if len(sources) == 0 {
+		return errors.New("no documents found")
+	}
+	for i, source := range sources {
+		value, found := fieldValue(source, a.path)
+		if !found {
+			return fmt.Errorf("field %s not found in document %d", a.path, i)
+		}
+		if err := a.checkValue(value); err != nil {
+			return fmt.Errorf("document %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (a docsAssertion) checkValue(value any) error {
+	switch a.operator {
+	case "==":
+		if !reflect.DeepEqual(value, a.value) {
+			return fmt.Errorf("found %v, expected %v", value, a.value)
+		}
+	case "!=":
+		if reflect.DeepEqual(value, a.value) {
+			return fmt.Errorf("found unexpected %v", value)
+		}
+	case "=~":
+		s, ok := value.(string)
+		if !ok {
+			s = fmt.Sprint(value)
+		}
+		if !a.pattern.MatchString(s) {
+			return fmt.Errorf("%q doesn't match %s", s, a.pattern)
+		}
+	}
+	return nil
+}
+
+// documentSources returns the sources of the documents in a search response,
+// or in a list of documents.
+func documentSources(docs any) []map[string]any {
+	var hits []any
+	switch docs := docs.(type) {
+	case []any:
+		hits = docs
+	case map[string]any:
+		if h, ok := docs["hits"].(map[string]any); ok {
+			hits, _ = h["hits"].([]any)
+		}
+	}
+
+	var sources []map[string]any
+	for _, hit := range hits {
+		doc, ok := hit.(map[string]any)
+		if !ok {
+			continue
+		}
+		if source, ok := doc["_source"].(map[string]any); ok {
+			doc = source
+		}
+		sources = append(sources, doc)
+	}
+	return sources
+}
+
+// fieldValue returns the value of a field in a document, considering that the
+// field name can be expressed with nested objects, dotted keys, or a mix of both.
+func fieldValue(doc map[string]any, field string) (any, bool) {
+	if value, found := doc[field]; found {
+		return value, true
+	}
+	for i := range len(field) {
+		if field[i] != '.' {
+			continue
+		}
+		object, ok := doc[field[:i]].(map[string]any)
+		if !ok {
+			continue
+		}
+		if value, found := fieldValue(object, field[i+1:]); found {
+			return value, true
+		}
+	}
+	return nil, false
+}
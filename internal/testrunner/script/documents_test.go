@@ -0,0 +1,63 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License;
+// you may not use this file except in compliance with the Elastic License.
+
+package script
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const searchResponse = `{
+	"hits": {
+		"total": {"value": 2, "relation": "eq"},
+		"hits": [
+			{"_source": {"event": {"dataset": "apache.access"}, "http.response.status_code": 200, "tags": ["a", "b"]}},
+			{"_source": {"event.dataset": "apache.access", "http": {"response": {"status_code": 404}}, "tags": ["a"]}}
+		]
+	}
+}`
+
+func TestDocsAssertion(t *testing.T) {
+	var docs any
+	require.NoError(t, json.Unmarshal([]byte(searchResponse), &docs))
+
+	cases := []struct {
+		path, operator, value string
+		fail                  bool
+	}{
+		{path: "$.hits.total.value", operator: "==", value: "2"},
+		{path: "$.hits.total.value", operator: "==", value: "3", fail: true},
+		{path: "$.hits.hits[*]._source.tags", operator: "!=", value: "[]"},
+		{path: "$.hits.hits[0]._source.tags", operator: "==", value: `["a","b"]`},
+		{path: "$.hits.hits[?(@._source.missing)]", fail: true},
+		{path: "event.dataset", operator: "==", value: "apache.access"},
+		{path: "event.dataset", operator: "=~", value: `^apache\.`},
+		{path: "http.response.status_code", operator: "=~", value: `^[24]0[04]$`},
+		{path: "http.response.status_code", operator: "==", value: "200", fail: true},
+		{path: "tags"},
+		{path: "error.message", fail: true},
+	}
+
+	for _, c := range cases {
+		a, err := newDocsAssertion(c.path, c.operator, c.value)
+		require.NoError(t, err)
+		t.Run(a.String(), func(t *testing.T) {
+			err := a.check(docs)
+			if c.fail {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNewDocsAssertionInvalidOperator(t *testing.T) {
+	_, err := newDocsAssertion("event.dataset", ">", "1")
+	assert.Error(t, err)
+}
@@ -54,7 +54,7 @@ func addPackage(ts *testscript.TestScript, neg bool, args []string) {
 		ts.Fatalf("no active client for %s", *profName)
 	}
 
-	ctx := context.Background()
+	ctx := scriptContext(ts)
 	if *timeout > 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, *timeout)
@@ -107,7 +107,7 @@ func removePackage(ts *testscript.TestScript, neg bool, args []string) {
 		ts.Fatalf("no active client for %s", *profName)
 	}
 
-	ctx := context.Background()
+	ctx := scriptContext(ts)
 	if *timeout > 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, *timeout)
@@ -157,7 +157,7 @@ func upgradePackageLatest(ts *testscript.TestScript, neg bool, args []string) {
 		ts.Fatalf("no active client for %s", *profName)
 	}
 
-	ctx := context.Background()
+	ctx := scriptContext(ts)
 	if *timeout > 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, *timeout)
@@ -215,7 +215,7 @@ func addPackageZip(ts *testscript.TestScript, neg bool, args []string) {
 		ts.Fatalf("no active client for %s", *profName)
 	}
 
-	ctx := context.Background()
+	ctx := scriptContext(ts)
 	if *timeout > 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, *timeout)
@@ -259,7 +259,7 @@ func removePackageZip(ts *testscript.TestScript, neg bool, args []string) {
 		ts.Fatalf("no active client for %s", *profName)
 	}
 
-	ctx := context.Background()
+	ctx := scriptContext(ts)
 	if *timeout > 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, *timeout)
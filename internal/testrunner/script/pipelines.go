@@ -53,7 +53,7 @@ func installPipelines(ts *testscript.TestScript, neg bool, args []string) {
 		ts.Fatalf("no active client for %s", *profName)
 	}
 
-	ctx := context.Background()
+	ctx := scriptContext(ts)
 	if *timeout > 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, *timeout)
@@ -134,7 +134,7 @@ func simulate(ts *testscript.TestScript, neg bool, args []string) {
 		ts.Fatalf("no active client for %s", *profName)
 	}
 
-	ctx := context.Background()
+	ctx := scriptContext(ts)
 	if *timeout > 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, *timeout)
@@ -188,7 +188,7 @@ func uninstallPipelines(ts *testscript.TestScript, neg bool, args []string) {
 		ts.Fatalf("no active client for %s", *profName)
 	}
 
-	ctx := context.Background()
+	ctx := scriptContext(ts)
 	if *timeout > 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, *timeout)
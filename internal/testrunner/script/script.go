@@ -22,6 +22,7 @@ import (
 	"regexp"
 	"slices"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -47,12 +48,17 @@ type Options struct {
 	Dir     string   // Path to directory containing script tests.
 	Streams []string // Data streams to test.
 
-	ExternalStack   bool   // Stack is provided externally to the scripts.
-	RunPattern      string // Regular expression to select tests to run.
-	Verbose         bool   // Verbose script logging.
-	UpdateScripts   bool   // testscript.Params.UpdateScripts
-	ContinueOnError bool   // testscript.Params.ContinueOnError
-	TestWork        bool   // testscript.Params.TestWork
+	ExternalStack   bool          // Stack is provided externally to the scripts.
+	RunPattern      string        // Regular expression to select tests to run.
+	Verbose         bool          // Verbose script logging.
+	UpdateScripts   bool          // testscript.Params.UpdateScripts
+	ContinueOnError bool          // testscript.Params.ContinueOnError
+	TestWork        bool          // testscript.Params.TestWork
+	Parallel        int           // Maximum number of scripts run in parallel (one or lower runs them sequentially).
+	Timeout         time.Duration // Timeout for each script (zero or lower indicates no timeout).
+
+	WithCoverage bool   // Generate coverage reports.
+	CoverageType string // Format of the coverage reports.
 }
 
 func Run(dst *[]testrunner.TestResult, w io.Writer, opt Options) error {
@@ -169,14 +175,6 @@ func Run(dst *[]testrunner.TestResult, w io.Writer, opt Options) error {
 		stdinTempFile: stdinTempFile,
 
 		passthrough: w, out: w,
-
-		results: dst,
-
-		deployedService:      make(map[string]servicedeployer.DeployedService),
-		runningStack:         make(map[string]*runningStack),
-		installedAgents:      make(map[string]*installedAgent),
-		installedDataStreams: make(map[string]struct{}),
-		installedPipelines:   make(map[string]installedPipelines),
 	}
 	if opt.RunPattern != "" {
 		t.run, err = regexp.Compile(opt.RunPattern)
@@ -212,26 +210,41 @@ func Run(dst *[]testrunner.TestResult, w io.Writer, opt Options) error {
 			}
 		}
 	}
-	var n int
+	var tests []scriptTest
 	for _, d := range dirs {
-		t.dataStream = d
 		scripts := d
 		var dsRoot string
 		if pkgRoot != "" {
 			dsRoot = filepath.Join(pkgRoot, "data_stream", d)
 			scripts = filepath.Join(dsRoot, filepath.FromSlash("_dev/test/scripts"))
 		}
-		_, err := os.Stat(scripts)
-		if errors.Is(err, fs.ErrNotExist) {
-			continue
+		files, err := scriptFiles(scripts)
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			tests = append(tests, scriptTest{dataStream: d, dsRoot: dsRoot, path: file})
 		}
-		n++
-		p := testscript.Params{
-			Dir:             scripts,
+	}
+	if len(tests) == 0 {
+		t.Log("[no test files]")
+		return nil
+	}
+
+	params := func(test scriptTest, st *T) testscript.Params {
+		// Each script is run with its own parameters, so the deadline
+		// applies to each script independently.
+		var deadline time.Time
+		if opt.Timeout > 0 {
+			deadline = time.Now().Add(opt.Timeout)
+		}
+		return testscript.Params{
+			Files:           []string{test.path},
 			WorkdirRoot:     workdirRoot,
 			UpdateScripts:   opt.UpdateScripts,
 			ContinueOnError: opt.ContinueOnError,
 			TestWork:        opt.TestWork,
+			Deadline:        deadline,
 			Cmds: map[string]func(ts *testscript.TestScript, neg bool, args []string){
 				"sleep":                  sleep,
 				"date":                   date,
@@ -257,6 +270,11 @@ func Run(dst *[]testrunner.TestResult, w io.Writer, opt Options) error {
 				"remove_package_policy":  removePackagePolicy,
 				"uninstall_agent":        uninstallAgent,
 				"get_docs":               getDocs,
+				"wait_for_docs":          waitForDocs,
+				"assert_docs":            assertDocs,
+				"es_request":             esRequest,
+				"kibana_request":         kibanaRequest,
+				"agent_status":           agentStatus,
 				"dump_logs":              dumpLogs,
 				"match_file":             match,
 				"get_policy":             getPolicyCommand,
@@ -281,16 +299,24 @@ func Run(dst *[]testrunner.TestResult, w io.Writer, opt Options) error {
 				if prevVersion != "" {
 					e.Setenv("PREVIOUS_VERSION", prevVersion)
 				}
-				if dsRoot != "" {
-					e.Setenv("DATA_STREAM", d)
-					e.Setenv("DATA_STREAM_ROOT", dsRoot)
+				if test.dsRoot != "" {
+					e.Setenv("DATA_STREAM", test.dataStream)
+					e.Setenv("DATA_STREAM_ROOT", test.dsRoot)
 				}
 				e.Setenv("ECS_BASE_SCHEMA_URL", appConfig.SchemaURLs().ECSBase())
-				e.Values[deployedServiceTag{}] = t.deployedService
-				e.Values[runningStackTag{}] = t.runningStack
-				e.Values[installedAgentsTag{}] = t.installedAgents
-				e.Values[installedDataStreamsTag{}] = t.installedDataStreams
-				e.Values[installedPipelinesTag{}] = t.installedPipelines
+				e.Values[deployedServiceTag{}] = st.deployedService
+				e.Values[runningStackTag{}] = st.runningStack
+				e.Values[installedAgentsTag{}] = st.installedAgents
+				e.Values[installedDataStreamsTag{}] = st.installedDataStreams
+				e.Values[installedPipelinesTag{}] = st.installedPipelines
+
+				ctx := context.Background()
+				if !deadline.IsZero() {
+					var cancel context.CancelFunc
+					ctx, cancel = context.WithDeadline(ctx, deadline)
+					e.Defer(cancel)
+				}
+				e.Values[scriptContextTag{}] = ctx
 				return nil
 			},
 			Condition: func(cond string) (bool, error) {
@@ -308,34 +334,87 @@ func Run(dst *[]testrunner.TestResult, w io.Writer, opt Options) error {
 				}
 			},
 		}
-		// This is not the ideal approach. What I would like would
-		// be to pass this into the testscript, but that is a bunch
-		// of wiring and likely should either be added later when
-		// needed, or have the option of passing it in to the library
-		// upstream.
-		if ctx.Err() != nil {
-			t.Fatal("interrupted")
+	}
+
+	runScript := func(test scriptTest, st *T) {
+		runTests(st, params(test, st)) //nolint:errcheck // elastic-package detects errors by the results slice.
+		if opt.WithCoverage && pkgRoot != "" {
+			for i, result := range *st.results {
+				coverage, err := generateCoverageReport(pkgRoot, result.Package, result.DataStream, opt.CoverageType)
+				if err != nil {
+					st.Log("coverage: ", err)
+					continue
+				}
+				(*st.results)[i].Coverage = coverage
+			}
 		}
-		t.Log("DATA_STREAM ", d)
-		runTests(t, p) //nolint:errcheck // elastic-package detects errors by the results slice.
 		if opt.TestWork {
-			continue
+			return
 		}
-		err = cleanUp(
+		err := cleanUp(
 			context.Background(), // Not the interrupt context.
 			pkgRoot,
-			t.deployedService,
-			t.installedDataStreams,
-			t.installedAgents,
-			t.installedPipelines,
-			t.runningStack,
+			st.deployedService,
+			st.installedDataStreams,
+			st.installedAgents,
+			st.installedPipelines,
+			st.runningStack,
 		)
 		if err != nil {
-			t.Log("cleanup: ", err)
+			st.Log("cleanup: ", err)
 		}
 	}
-	if n == 0 {
-		t.Log("[no test files]")
+
+	if opt.Parallel <= 1 {
+		var dataStream string
+		for _, test := range tests {
+			if ctx.Err() != nil {
+				return errors.New("interrupted")
+			}
+			if test.dataStream != dataStream {
+				dataStream = test.dataStream
+				t.Log("DATA_STREAM ", dataStream)
+			}
+			st := t.scriptT(test.dataStream, w)
+			runScript(test, st)
+			*dst = append(*dst, *st.results...)
+		}
+		return nil
+	}
+
+	// Output of each script is written when it finishes, so logs of
+	// scripts running in parallel are not mixed.
+	var (
+		wg  sync.WaitGroup
+		mu  sync.Mutex
+		sem = make(chan struct{}, opt.Parallel)
+		sts = make([]*T, len(tests))
+	)
+	for i, test := range tests {
+		if ctx.Err() != nil {
+			break
+		}
+		var out bytes.Buffer
+		sts[i] = t.scriptT(test.dataStream, &out)
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			runScript(test, sts[i])
+			mu.Lock()
+			defer mu.Unlock()
+			w.Write(out.Bytes())
+		}()
+	}
+	wg.Wait()
+	for _, st := range sts {
+		if st != nil {
+			*dst = append(*dst, *st.results...)
+		}
+	}
+	if ctx.Err() != nil {
+		return errors.New("interrupted")
 	}
 	return nil
 }
@@ -386,6 +465,64 @@ func cleanUp(ctx context.Context, pkgRoot string, srvs map[string]servicedeploye
 	return errors.Join(errs...)
 }
 
+// scriptTest is a script file to be run as a test.
+type scriptTest struct {
+	dataStream string
+	dsRoot     string
+	path       string
+}
+
+// scriptFiles returns the paths of the script files in a directory,
+// sorted by file name.
+func scriptFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read scripts directory (path: %s): %w", dir, err)
+	}
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasSuffix(name, ".txtar") || strings.HasSuffix(name, ".txt") {
+			files = append(files, filepath.Join(dir, name))
+		}
+	}
+	return files, nil
+}
+
+type scriptContextTag struct{}
+
+// scriptContext returns the context of the running script, that is cancelled
+// when the script times out.
+func scriptContext(ts *testscript.TestScript) context.Context {
+	ctx, ok := ts.Value(scriptContextTag{}).(context.Context)
+	if !ok {
+		return context.Background()
+	}
+	return ctx
+}
+
+// generateCoverageReport generates a coverage report that includes the manifests and fields files
+// of the package or data stream, as done for system tests.
+func generateCoverageReport(pkgRoot, pkgName, dataStream, coverageType string) (testrunner.CoverageReport, error) {
+	dsPattern := "*"
+	if dataStream != "" {
+		dsPattern = dataStream
+		pkgName = pkgName + "." + dataStream
+	}
+
+	patterns := []string{
+		filepath.Join(pkgRoot, "manifest.yml"),
+		filepath.Join(pkgRoot, "fields", "*.yml"),
+		filepath.Join(pkgRoot, "data_stream", dsPattern, "manifest.yml"),
+		filepath.Join(pkgRoot, "data_stream", dsPattern, "fields", "*.yml"),
+	}
+
+	return testrunner.GenerateBaseFileCoverageReportGlob(pkgName, patterns, coverageType, true)
+}
+
 func scripts(dir string) ([]string, error) {
 	if dir == "" {
 		return nil, nil
@@ -511,6 +648,29 @@ type T struct {
 	installedPipelines   map[string]installedPipelines
 }
 
+// scriptT returns a T to run a single script, with its own registries and results,
+// so scripts can be run in parallel and cleaned up independently.
+func (t *T) scriptT(dataStream string, out io.Writer) *T {
+	return &T{
+		pkg:        t.pkg,
+		dataStream: dataStream,
+
+		run:           t.run,
+		verbose:       t.verbose,
+		stdinTempFile: t.stdinTempFile,
+
+		passthrough: out, out: out,
+
+		results: new([]testrunner.TestResult),
+
+		deployedService:      make(map[string]servicedeployer.DeployedService),
+		runningStack:         make(map[string]*runningStack),
+		installedAgents:      make(map[string]*installedAgent),
+		installedDataStreams: make(map[string]struct{}),
+		installedPipelines:   make(map[string]installedPipelines),
+	}
+}
+
 // clearRegistries prevents tests within a directory from communicating
 // with each other. This is required because we need a way to share the
 // registries with the environment in order to do the clean-up.
@@ -56,7 +56,7 @@ func stackUp(ts *testscript.TestScript, neg bool, args []string) {
 		ts.Fatalf("can't load configuration: %v", err)
 	}
 
-	ctx := context.Background()
+	ctx := scriptContext(ts)
 	if *timeout > 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, *timeout)
@@ -132,7 +132,7 @@ func stackDown(ts *testscript.TestScript, neg bool, args []string) {
 		ts.Fatalf("cannot take down externally run stack %s", *profName)
 	}
 
-	ctx := context.Background()
+	ctx := scriptContext(ts)
 	if *timeout > 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, *timeout)
@@ -175,7 +175,7 @@ func useStack(ts *testscript.TestScript, neg bool, args []string) {
 	path, err := expandTilde(*profPath)
 	ts.Check(decoratedWith("getting home directory", err))
 
-	ctx := context.Background()
+	ctx := scriptContext(ts)
 	if *timeout > 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, *timeout)
@@ -256,7 +256,7 @@ func getDocs(ts *testscript.TestScript, neg bool, args []string) {
 		ts.Fatalf("no active client for %s", *profName)
 	}
 
-	ctx := context.Background()
+	ctx := scriptContext(ts)
 	if *timeout > 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, *timeout)
@@ -320,6 +320,94 @@ func getDocs(ts *testscript.TestScript, neg bool, args []string) {
 	fmt.Fprintf(ts.Stdout(), "%s\n", body.Bytes())
 }
 
+// esRequest performs a request to the Elasticsearch API of the stack, authenticated
+// with the credentials of the profile, and prints the response body.
+func esRequest(ts *testscript.TestScript, neg bool, args []string) {
+	stackRequest(ts, neg, "es_request", args, func(ctx context.Context, stk *runningStack, method, path string, body []byte) (int, []byte, error) {
+		req, err := http.NewRequestWithContext(ctx, method, path, bytes.NewReader(body))
+		if err != nil {
+			return 0, nil, err
+		}
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		resp, err := stk.es.Transport.Perform(req)
+		if err != nil {
+			return 0, nil, err
+		}
+		defer resp.Body.Close()
+		respBody, err := io.ReadAll(resp.Body)
+		return resp.StatusCode, respBody, err
+	})
+}
+
+// kibanaRequest performs a request to the Kibana API of the stack, authenticated
+// with the credentials of the profile, and prints the response body.
+func kibanaRequest(ts *testscript.TestScript, neg bool, args []string) {
+	stackRequest(ts, neg, "kibana_request", args, func(ctx context.Context, stk *runningStack, method, path string, body []byte) (int, []byte, error) {
+		return stk.kibana.SendRequest(ctx, method, path, body)
+	})
+}
+
+func stackRequest(ts *testscript.TestScript, neg bool, name string, args []string, do func(ctx context.Context, stk *runningStack, method, path string, body []byte) (int, []byte, error)) {
+	clearStdStreams(ts)
+
+	stacks, ok := ts.Value(runningStackTag{}).(map[string]*runningStack)
+	if !ok {
+		ts.Fatalf("no active stacks registry")
+	}
+
+	flg := flag.NewFlagSet(name, flag.ContinueOnError)
+	profName := flg.String("profile", "default", "profile name")
+	jsonData := flg.Bool("json", false, "format response as indented JSON")
+	timeout := flg.Duration("timeout", 0, "timeout (zero or lower indicates no timeout)")
+	ts.Check(flg.Parse(args))
+	if flg.NArg() != 2 && flg.NArg() != 3 {
+		ts.Fatalf("usage: %s [-profile <profile>] [-timeout <duration>] [-json] <method> <path> [<body_path>]", name)
+	}
+	method := strings.ToUpper(flg.Arg(0))
+	path := flg.Arg(1)
+
+	stk, ok := stacks[*profName]
+	if !ok {
+		ts.Fatalf("no active client for %s", *profName)
+	}
+
+	var body []byte
+	if flg.NArg() == 3 {
+		var err error
+		body, err = os.ReadFile(ts.MkAbs(flg.Arg(2)))
+		ts.Check(decoratedWith("reading request body", err))
+	}
+
+	ctx := scriptContext(ts)
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	status, resp, err := do(ctx, stk, method, path, body)
+	ts.Check(decoratedWith(fmt.Sprintf("performing %s request to %s", method, path), err))
+	if *jsonData {
+		var dst bytes.Buffer
+		ts.Check(decoratedWith("formatting response", json.Indent(&dst, resp, "", "\t")))
+		resp = dst.Bytes()
+	}
+	ts.Stdout().Write(resp)
+	if !bytes.HasSuffix(resp, []byte{'\n'}) {
+		fmt.Fprintln(ts.Stdout())
+	}
+
+	failed := status >= 300
+	switch {
+	case neg && !failed:
+		ts.Fatalf("%s: unexpected success with status code %d", name, status)
+	case !neg && failed:
+		ts.Fatalf("%s: unexpected status code %d", name, status)
+	}
+}
+
 // dumpLogs copies logs to a directory within the work directory.
 func dumpLogs(ts *testscript.TestScript, neg bool, args []string) {
 	clearStdStreams(ts)
@@ -382,7 +470,7 @@ func dumpLogs(ts *testscript.TestScript, neg bool, args []string) {
 		ts.Check(decoratedWith("parsing since flag", err))
 	}
 
-	ctx := context.Background()
+	ctx := scriptContext(ts)
 	if *timeout > 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, *timeout)